@@ -0,0 +1,87 @@
+package stateless
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Branch is one destination of PermitIfExhaustive, taken when its guard is met.
+// A nil Guard is always met. An optional Description labels the guard in graphs and errors.
+type Branch[TState comparable] struct {
+	Guard       GuardFunc
+	Dest        TState
+	Description string
+}
+
+// PermitIfExhaustive configures the trigger to transition to the destination of whichever branch's
+// guard is met, declaring that exactly one always is. If none is met, Fire returns a
+// NoBranchMatchedError listing each branch and why its guard rejected, instead of treating the
+// trigger as unhandled; if several are met, Fire returns the usual error for ambiguous transitions.
+// Guards reject with Reject as with PermitIf; any other error is returned by Fire as is.
+// Identity destinations are handled as in Permit.
+func (sn *StateNode[TState, TTrigger]) PermitIfExhaustive(
+	tr TTrigger,
+	branches ...Branch[TState],
+) *StateNode[TState, TTrigger] {
+	if len(branches) == 0 {
+		panic(fmt.Sprintf("PermitIfExhaustive requires at least one branch: state '%v', trigger '%v'",
+			sn.representation.UnderlyingState(), tr))
+	}
+	for _, branch := range branches {
+		guard := EmptyTransitionGuard
+		if branch.Guard != nil {
+			guard = TransitionGuard{Conditions: []GuardCondition{
+				NewGuardCondition(branchGuard(branch), CreateInvocationInfo(branch.Guard, branch.Description)),
+			}}
+		}
+		sn.permit(tr, branch.Dest, guard)
+	}
+	return sn
+}
+
+// branchGuard wraps the guard of a branch so that its rejections identify the branch.
+func branchGuard[TState comparable](branch Branch[TState]) GuardFunc {
+	description := CreateInvocationInfo(branch.Guard, branch.Description).Description()
+	return func(ctx context.Context, args any) error {
+		if err := branch.Guard(ctx, args); err != nil {
+			return &branchGuardError{BranchRejection: BranchRejection{
+				Destination: branch.Dest,
+				Description: description,
+				Reason:      err,
+			}}
+		}
+		return nil
+	}
+}
+
+// branchGuardError is returned by the guard of a branch that is not met. It reads and unwraps
+// as the guard's own error.
+type branchGuardError struct {
+	BranchRejection
+}
+
+func (e *branchGuardError) Error() string {
+	return e.Reason.Error()
+}
+
+// Unwrap returns the error returned by the guard.
+func (e *branchGuardError) Unwrap() error {
+	return e.Reason
+}
+
+// noBranchMatched returns a NoBranchMatchedError if the unmet guards include PermitIfExhaustive
+// branches, or nil.
+func noBranchMatched(state any, trigger any, unmetGuards []error) error {
+	var branches []BranchRejection
+	for _, unmet := range unmetGuards {
+		var branch *branchGuardError
+		if errors.As(unmet, &branch) {
+			branches = append(branches, branch.BranchRejection)
+		}
+	}
+	if len(branches) == 0 {
+		return nil
+	}
+	return &NoBranchMatchedError{Trigger: trigger, State: state, Branches: branches}
+}
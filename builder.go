@@ -0,0 +1,100 @@
+package stateless
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Builder separates the configuration of a state machine from its use. States are configured
+// through Builder.Configure, and Build validates the configuration once and returns a machine
+// whose configuration can no longer be changed: configuring a state on it, through Configure or
+// otherwise, panics, as do the setters that change how triggers are handled, such as
+// AliasTriggers, SetTriggerParameters and SetMaxImmediateDepth. Callbacks, logging and other
+// runtime features can still be set up. Since the configuration is final, built machines resolve
+// unguarded triggers through a precompiled dispatch table (see StateMachine.SetPrecompiledDispatch).
+//
+//	b := stateless.NewBuilder[State, Trigger](StateA)
+//	b.Configure(StateA).Permit(TriggerX, StateB)
+//	sm, err := b.Build()
+//
+// StateNode values obtained from the builder must not be used after Build.
+type Builder[TState, TTrigger comparable] struct {
+	sm    *StateMachine[TState, TTrigger]
+	built bool
+}
+
+// NewBuilder creates a builder for a state machine with the specified initial state.
+func NewBuilder[TState, TTrigger comparable](initialState TState) *Builder[TState, TTrigger] {
+	return &Builder[TState, TTrigger]{sm: NewStateMachine[TState, TTrigger](initialState)}
+}
+
+// Configure begins configuration of a state. It panics if Build has already succeeded.
+func (b *Builder[TState, TTrigger]) Configure(state TState) *StateNode[TState, TTrigger] {
+	if b.built {
+		panic("stateless: Configure called on a Builder after Build")
+	}
+	return b.sm.Configure(state)
+}
+
+// Build validates the configuration and returns the state machine. Validation checks that
+// the superstate hierarchy has no cycles, that no state has several unguarded behaviours for
+// the same trigger (see StateMachine.Validate), and that every initial transition targets a
+// substate of the state declaring it. All problems found are returned together; the builder
+// can then be corrected and built again. Build can only succeed once.
+func (b *Builder[TState, TTrigger]) Build() (*StateMachine[TState, TTrigger], error) {
+	if b.built {
+		return nil, &InvalidOperationError{Message: "builder has already been built"}
+	}
+
+	var errs []error
+	representations := b.sm.representations()
+	for _, state := range b.sm.States() {
+		rep := representations[state]
+
+		visited := map[TState]bool{state: true}
+		for super := rep.Superstate(); super != nil; super = super.Superstate() {
+			if visited[super.UnderlyingState()] {
+				errs = append(errs, &InvalidOperationError{
+					Message: fmt.Sprintf("state '%v' is part of a superstate cycle", state),
+				})
+				break
+			}
+			visited[super.UnderlyingState()] = true
+		}
+
+		var targets []TState
+		if rep.HasInitialTransition() {
+			targets = append(targets, rep.InitialTransitionTarget())
+		}
+		for _, initial := range rep.GuardedInitialTransitions() {
+			targets = append(targets, initial.Target)
+		}
+		for _, target := range targets {
+			targetRep, ok := representations[target]
+			if !ok || !targetRep.IsSubstateOf(state) {
+				errs = append(errs, &InvalidOperationError{
+					Message: fmt.Sprintf("initial transition target '%v' is not a substate of '%v'", target, state),
+				})
+			}
+		}
+	}
+	if err := b.sm.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	b.built = true
+	b.sm.SetPrecompiledDispatch(true)
+	b.sm.sealed = true
+	return b.sm, nil
+}
+
+// panicIfSealed panics if the machine was created by Builder.Build, whose configuration is final.
+// method names the configuration method called.
+func (sm *StateMachine[TState, TTrigger]) panicIfSealed(method string) {
+	if sm.sealed {
+		panic("stateless: " + method + " called on a state machine created by Builder.Build")
+	}
+}
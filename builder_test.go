@@ -0,0 +1,116 @@
+package stateless_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/atlekbai/stateless"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	b := stateless.NewBuilder[State, Trigger](StateA)
+	b.Configure(StateA).Permit(TriggerX, StateB)
+	b.Configure(StateB).
+		InitialTransition(StateC).
+		Permit(TriggerY, StateA)
+	b.Configure(StateC).SubstateOf(StateB)
+
+	sm, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateC {
+		t.Errorf("expected StateC, got %v", sm.State())
+	}
+
+	if _, err := b.Build(); err == nil {
+		t.Error("expected error building twice")
+	}
+
+	assertPanics(t, "builder Configure after Build", func() { b.Configure(StateD) })
+	assertPanics(t, "machine Configure after Build", func() { sm.Configure(StateD) })
+}
+
+func TestBuilder_BuiltMachineIsImmutable(t *testing.T) {
+	b := stateless.NewBuilder[State, Trigger](StateA)
+	node := b.Configure(StateA).Permit(TriggerX, StateB)
+	b.Configure(StateB)
+
+	sm, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	representation, _ := sm.Representation(StateA)
+
+	action := func(context.Context, stateless.Transition[State, Trigger]) error { return nil }
+	assertPanics(t, "StateNode obtained before Build", func() { node.Permit(TriggerY, StateC) })
+	assertPanics(t, "StateNode entry action", func() { node.OnEntry(action) })
+	assertPanics(t, "StateRepresentation", func() {
+		info := stateless.NewInvocationInfo("action", "")
+		representation.AddEntryAction(stateless.NewEntryActionBehaviour[State, Trigger](action, info))
+	})
+	assertPanics(t, "AliasTriggers", func() { sm.AliasTriggers(TriggerX, TriggerZ) })
+	assertPanics(t, "SetTriggerParameters", func() { sm.SetTriggerParameters(TriggerX, reflect.TypeFor[int]()) })
+	assertPanics(t, "SetArgDecoder", func() { sm.SetArgDecoder(TriggerX, func(a any) (any, error) { return a, nil }) })
+	assertPanics(t, "SetMaxImmediateDepth", func() { sm.SetMaxImmediateDepth(1) })
+	assertPanics(t, "SetReverseExitOrder", func() { sm.SetReverseExitOrder(true) })
+	assertPanics(t, "SetPermitIdentityAsReentry", func() { sm.SetPermitIdentityAsReentry(true) })
+	assertPanics(t, "SetEmitCompletedForNonTransitions", func() { sm.SetEmitCompletedForNonTransitions(true) })
+	assertPanics(t, "SetEmitInitialTransitionEvents", func() { sm.SetEmitInitialTransitionEvents(true) })
+	assertPanics(t, "SetUnhandledReturnsError", func() { sm.SetUnhandledReturnsError(true) })
+	assertPanics(t, "SetGuardReasonFormatter", func() {
+		sm.SetGuardReasonFormatter(func(_ Trigger, description string) string { return description })
+	})
+	assertPanics(t, "SetPrecompiledDispatch", func() { sm.SetPrecompiledDispatch(false) })
+
+	// The machine still works, and its configuration was left unchanged
+	if err := sm.Fire(TriggerX, nil); err != nil || sm.State() != StateB {
+		t.Errorf("expected the built machine to fire, got %v in %v", err, sm.State())
+	}
+	if err := sm.Fire(TriggerZ, nil); err == nil {
+		t.Error("expected TriggerZ not to have become an alias")
+	}
+}
+
+func TestBuilder_BuildReportsAllProblems(t *testing.T) {
+	b := stateless.NewBuilder[State, Trigger](StateA)
+	b.Configure(StateA).
+		Permit(TriggerX, StateB).
+		Ignore(TriggerX).
+		InitialTransition(StateC)
+	b.Configure(StateC)
+
+	sm, err := b.Build()
+	if sm != nil {
+		t.Error("expected no machine on validation failure")
+	}
+	var opErr *stateless.InvalidOperationError
+	if !errors.As(err, &opErr) {
+		t.Fatalf("expected InvalidOperationError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "initial transition target") || !strings.Contains(err.Error(), "unguarded") {
+		t.Errorf("expected both problems to be reported, got %q", err.Error())
+	}
+
+	// The builder can be corrected and built again
+	b.Configure(StateC).SubstateOf(StateA)
+	if _, err := b.Build(); err == nil || strings.Contains(err.Error(), "initial transition target") {
+		t.Errorf("expected only the overlapping behaviours to remain, got %v", err)
+	}
+}
+
+func assertPanics(t *testing.T, name string, fn func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("%s: expected panic", name)
+		}
+	}()
+	fn()
+}
@@ -0,0 +1,39 @@
+package stateless
+
+import "time"
+
+// Clock is the source of time used by a state machine, for TimeInState, MinDwell and PermitTick.
+// Replace it with SetClock to control time in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// AfterFunc calls f in its own goroutine once d has elapsed, unless the returned timer is stopped first.
+	AfterFunc(d time.Duration, f func()) ClockTimer
+}
+
+// ClockTimer is a pending call scheduled with Clock.AfterFunc.
+type ClockTimer interface {
+	// Stop prevents the call from happening. It returns false if the call already happened or was stopped.
+	Stop() bool
+}
+
+// systemClock is the default Clock, backed by the time package.
+type systemClock struct{}
+
+// Now returns time.Now().
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// AfterFunc calls time.AfterFunc.
+func (systemClock) AfterFunc(d time.Duration, f func()) ClockTimer {
+	return time.AfterFunc(d, f)
+}
+
+// SetClock replaces the clock used by the state machine. It should be called before the machine is used.
+// TimeInState is measured from the call until the state next changes.
+func (sm *StateMachine[TState, TTrigger]) SetClock(clock Clock) {
+	sm.clock = clock
+	sm.clockEpoch = clock.Now()
+	sm.enteredAt.Store(0)
+}
@@ -0,0 +1,58 @@
+package stateless
+
+import (
+	"context"
+	"maps"
+	"reflect"
+)
+
+// WithValue attaches a value to the state machine under key, such as the correlation id of the
+// workflow instance it runs, replacing any previous value. During each fire, the context passed to
+// guards, actions, selectors and handlers such as OnTransitioning and OnTransitionedWith carries the
+// attached values on top of the context the trigger was fired with, so they do not need to be
+// threaded through trigger args.
+//
+// The context a trigger is fired with takes precedence: if its Value returns a non-nil value for a
+// key, that value is seen instead of the one attached to the machine, which lets a single fire
+// override it. As with context.WithValue, the key must be comparable and should be of an unexported
+// type. Values are not copied by Clone.
+func (sm *StateMachine[TState, TTrigger]) WithValue(key, val any) {
+	if key == nil || !reflect.TypeOf(key).Comparable() {
+		panic("stateless: WithValue key must be a non-nil comparable value")
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	// The map is replaced rather than modified, since contexts of fires in progress hold the previous one
+	var values map[any]any
+	if previous := sm.values.Load(); previous != nil {
+		values = maps.Clone(*previous)
+	} else {
+		values = make(map[any]any)
+	}
+	values[key] = val
+	sm.values.Store(&values)
+}
+
+// machineValuesContext is the context of a fire carrying the values attached with WithValue.
+type machineValuesContext struct {
+	context.Context
+	values map[any]any
+}
+
+// Value returns the value of the fire's context for key, or else the value attached to the machine.
+func (c *machineValuesContext) Value(key any) any {
+	if val := c.Context.Value(key); val != nil {
+		return val
+	}
+	return c.values[key]
+}
+
+// withMachineValues returns ctx carrying the values attached with WithValue, if any.
+func (sm *StateMachine[TState, TTrigger]) withMachineValues(ctx context.Context) context.Context {
+	values := sm.values.Load()
+	if values == nil {
+		return ctx
+	}
+	return &machineValuesContext{Context: ctx, values: *values}
+}
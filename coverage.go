@@ -0,0 +1,100 @@
+package stateless
+
+import (
+	"slices"
+	"sync"
+)
+
+// TransitionEdge identifies a configured transition: a trigger leading from a state to a destination.
+// Source is the state that configures the transition, which may be a superstate of the state the
+// trigger was fired in.
+type TransitionEdge[TState, TTrigger comparable] struct {
+	Source      TState
+	Trigger     TTrigger
+	Destination TState
+}
+
+// coverageRecorder collects the transition edges taken while coverage is enabled.
+type coverageRecorder[TState, TTrigger comparable] struct {
+	mutex sync.Mutex
+	taken map[TransitionEdge[TState, TTrigger]]struct{}
+}
+
+// record marks an edge as taken.
+func (c *coverageRecorder[TState, TTrigger]) record(edge TransitionEdge[TState, TTrigger]) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.taken[edge] = struct{}{}
+}
+
+// isTaken reports whether an edge was taken.
+func (c *coverageRecorder[TState, TTrigger]) isTaken(edge TransitionEdge[TState, TTrigger]) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	_, ok := c.taken[edge]
+	return ok
+}
+
+// EnableCoverage starts recording which configured transitions are taken, so that tests can check
+// with Coverage that every transition was exercised. Recording costs a map insertion per transition.
+// Calling EnableCoverage again keeps the transitions recorded so far.
+func (sm *StateMachine[TState, TTrigger]) EnableCoverage() {
+	sm.coverage.CompareAndSwap(nil, &coverageRecorder[TState, TTrigger]{
+		taken: make(map[TransitionEdge[TState, TTrigger]]struct{}),
+	})
+}
+
+// Coverage returns the configured transitions that were taken since EnableCoverage was called,
+// and all configured transitions. Both are in a deterministic order, and taken is a subset of total,
+// so full coverage means both have the same length.
+//
+// Transitions are those configured with Permit, PermitIf, PermitReentry and PermitReentryIf. Dynamic
+// and default transitions have no fixed destination and are not tracked; neither are internal
+// transitions and ignored triggers. Without EnableCoverage, taken is empty.
+func (sm *StateMachine[TState, TTrigger]) Coverage() (taken, total []TransitionEdge[TState, TTrigger]) {
+	coverage := sm.coverage.Load()
+	for state, rep := range sm.representations() {
+		for trigger, behaviours := range rep.TriggerBehaviours() {
+			for _, behaviour := range behaviours {
+				var edge TransitionEdge[TState, TTrigger]
+				switch b := behaviour.(type) {
+				case *TransitioningTriggerBehaviour[TState, TTrigger]:
+					edge = TransitionEdge[TState, TTrigger]{Source: state, Trigger: trigger, Destination: b.Destination}
+				case *ReentryTriggerBehaviour[TState, TTrigger]:
+					edge = TransitionEdge[TState, TTrigger]{Source: state, Trigger: trigger, Destination: b.Destination}
+				default:
+					continue
+				}
+				if !slices.Contains(total, edge) {
+					total = append(total, edge)
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(total, compareEdges[TState, TTrigger])
+	for _, edge := range total {
+		if coverage != nil && coverage.isTaken(edge) {
+			taken = append(taken, edge)
+		}
+	}
+	return taken, total
+}
+
+// recordCoverage records a taken transition if coverage is enabled.
+func (sm *StateMachine[TState, TTrigger]) recordCoverage(source TState, trigger TTrigger, destination TState) {
+	if coverage := sm.coverage.Load(); coverage != nil {
+		coverage.record(TransitionEdge[TState, TTrigger]{Source: source, Trigger: trigger, Destination: destination})
+	}
+}
+
+// compareEdges orders edges by source, trigger and destination.
+func compareEdges[TState, TTrigger comparable](a, b TransitionEdge[TState, TTrigger]) int {
+	if c := compareValues(a.Source, b.Source); c != 0 {
+		return c
+	}
+	if c := compareValues(a.Trigger, b.Trigger); c != 0 {
+		return c
+	}
+	return compareValues(a.Destination, b.Destination)
+}
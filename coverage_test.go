@@ -0,0 +1,55 @@
+package stateless_test
+
+import (
+	"testing"
+
+	"github.com/atlekbai/stateless"
+)
+
+func TestCoverage(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateB)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateC)
+	sm.Configure(StateB).
+		SubstateOf(StateA).
+		PermitReentry(TriggerY).
+		Permit(TriggerZ, StateD)
+	sm.Configure(StateC).
+		Permit(TriggerX, StateB)
+
+	if taken, total := sm.Coverage(); len(taken) != 0 || len(total) != 4 {
+		t.Fatalf("expected 0 of 4 edges before enabling coverage, got %v of %v", taken, total)
+	}
+
+	sm.EnableCoverage()
+	for _, tr := range []Trigger{TriggerY, TriggerX, TriggerX} {
+		if err := sm.Fire(tr, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	taken, total := sm.Coverage()
+	expected := []stateless.TransitionEdge[State, Trigger]{
+		{Source: StateA, Trigger: TriggerX, Destination: StateC},
+		{Source: StateB, Trigger: TriggerY, Destination: StateB},
+		{Source: StateC, Trigger: TriggerX, Destination: StateB},
+	}
+	if len(taken) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, taken)
+	}
+	for i := range expected {
+		if taken[i] != expected[i] {
+			t.Errorf("expected %v at index %d, got %v", expected[i], i, taken[i])
+		}
+	}
+	if len(total) != 4 {
+		t.Errorf("expected 4 configured edges, got %v", total)
+	}
+
+	if err := sm.Fire(TriggerZ, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if taken, total := sm.Coverage(); len(taken) != len(total) {
+		t.Errorf("expected full coverage, got %v of %v", taken, total)
+	}
+}
@@ -0,0 +1,127 @@
+package stateless
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Describe returns a canonical, line-oriented description of the state machine configuration,
+// meant to be diffed when the configuration changes. States are listed in order, each with its
+// superstate, whether it is final, its tags, its entry, activate, deactivate and exit action
+// descriptions in execution order, and its transitions, dynamic transitions and ignored triggers
+// ordered by trigger and then by their text, so the output does not depend on the order the machine
+// was configured in:
+//
+//	initial StateA
+//	state StateA
+//	  entry StartTimer
+//	  transition TriggerX -> StateB [guard: isReady]
+//	  internal TriggerY [action: refresh]
+//	  dynamic TriggerZ -> selectTarget (StateB, StateC)
+//	  ignore TriggerW
+//
+// Guards and actions are identified by their descriptions, which default to function names.
+func (sm *StateMachine[TState, TTrigger]) Describe() string {
+	info := sm.GetInfo()
+
+	var b strings.Builder
+	if info.InitialState != nil {
+		fmt.Fprintf(&b, "initial %s\n", formatValue(info.InitialState.UnderlyingState))
+	}
+	for _, state := range info.States {
+		fmt.Fprintf(&b, "state %s\n", formatValue(state.UnderlyingState))
+		if state.Superstate != nil {
+			fmt.Fprintf(&b, "  superstate %s\n", formatValue(state.Superstate.UnderlyingState))
+		}
+		if state.IsFinal {
+			b.WriteString("  final\n")
+		}
+		for _, key := range slices.Sorted(maps.Keys(state.Tags)) {
+			fmt.Fprintf(&b, "  tag %s=%s\n", key, state.Tags[key])
+		}
+		for _, act := range state.EntryActions {
+			fmt.Fprintf(&b, "  entry %s\n", act.Description())
+		}
+		for _, act := range state.ActivateActions {
+			fmt.Fprintf(&b, "  activate %s\n", act.Description())
+		}
+		for _, act := range state.DeactivateActions {
+			fmt.Fprintf(&b, "  deactivate %s\n", act.Description())
+		}
+		for _, act := range state.ExitActions {
+			fmt.Fprintf(&b, "  exit %s\n", act.Description())
+		}
+		writeSortedLines(&b, state.FixedTransitions, describeFixedTransition)
+		writeSortedLines(&b, state.DynamicTransitions, describeDynamicTransition)
+		writeSortedLines(&b, state.IgnoredTriggers, func(tr IgnoredTransitionInfo) string {
+			return "ignore " + formatValue(tr.Trigger.UnderlyingTrigger) + describeGuards(tr.GuardConditions)
+		})
+	}
+	return b.String()
+}
+
+// writeSortedLines writes one line per transition, ordered by trigger and then by line.
+func writeSortedLines[T any, PT interface {
+	*T
+	GetTrigger() TriggerInfo
+}](b *strings.Builder, transitions []T, describe func(T) string) {
+	type line struct {
+		trigger any
+		text    string
+	}
+	lines := make([]line, len(transitions))
+	for i, tr := range transitions {
+		lines[i] = line{trigger: PT(&tr).GetTrigger().UnderlyingTrigger, text: describe(tr)}
+	}
+	slices.SortStableFunc(lines, func(a, b line) int {
+		if c := compareValues(a.trigger, b.trigger); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.text, b.text)
+	})
+	for _, l := range lines {
+		fmt.Fprintf(b, "  %s\n", l.text)
+	}
+}
+
+// describeFixedTransition describes a fixed or internal transition.
+func describeFixedTransition(tr FixedTransitionInfo) string {
+	trigger := formatValue(tr.Trigger.UnderlyingTrigger)
+	if tr.IsInternalTransition {
+		return fmt.Sprintf("internal %s [action: %s]%s", trigger, tr.InternalAction.Description(),
+			describeGuards(tr.GuardConditions))
+	}
+	destination := NullString
+	if tr.DestinationState != nil {
+		destination = formatValue(tr.DestinationState.UnderlyingState)
+	}
+	return fmt.Sprintf("transition %s -> %s%s", trigger, destination, describeGuards(tr.GuardConditions))
+}
+
+// describeDynamicTransition describes a dynamic transition with its possible destinations.
+func describeDynamicTransition(tr DynamicTransitionInfo) string {
+	destinations := make([]string, len(tr.PossibleDestinationStates))
+	for i, dst := range tr.PossibleDestinationStates {
+		destinations[i] = dst.DestinationState
+		if dst.Criterion != "" {
+			destinations[i] += " when " + dst.Criterion
+		}
+	}
+	line := fmt.Sprintf("dynamic %s -> %s", formatValue(tr.Trigger.UnderlyingTrigger),
+		tr.DestinationStateSelectorDescription.Description())
+	if len(destinations) > 0 {
+		line += " (" + strings.Join(destinations, ", ") + ")"
+	}
+	return line + describeGuards(tr.GuardConditions)
+}
+
+// describeGuards describes the guard conditions of a transition, or returns an empty string.
+func describeGuards(guards []InvocationInfo) string {
+	if len(guards) == 0 {
+		return ""
+	}
+	return " [guard: " + strings.Join(guardDescriptions(guards), ", ") + "]"
+}
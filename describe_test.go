@@ -0,0 +1,65 @@
+package stateless_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/atlekbai/stateless"
+)
+
+func TestDescribe(t *testing.T) {
+	isReady := func(ctx context.Context, args any) error { return nil }
+	noop := func(ctx context.Context, tr stateless.Transition[State, Trigger]) error { return nil }
+
+	build := func(reversed bool) *stateless.StateMachine[State, Trigger] {
+		sm := stateless.NewStateMachine[State, Trigger](StateA)
+		configureA := func() {
+			a := sm.Configure(StateA).OnEntry(noop, "StartTimer")
+			if reversed {
+				a.PermitIf(TriggerX, StateC, isReady, "isLate").PermitIf(TriggerX, StateB, isReady, "isReady")
+			} else {
+				a.PermitIf(TriggerX, StateB, isReady, "isReady").PermitIf(TriggerX, StateC, isReady, "isLate")
+			}
+			a.Ignore(TriggerZ).
+				InternalTransition(TriggerY, noop)
+		}
+		configureB := func() {
+			sm.Configure(StateB).SubstateOf(StateC).OnExit(noop, "StopTimer").
+				PermitDynamic(TriggerY, func(ctx context.Context, args any) (State, error) { return StateA, nil },
+					stateless.DynamicStateInfo{DestinationState: "StateA", Criterion: "always"})
+			sm.Configure(StateC).MarkFinal()
+		}
+		if reversed {
+			configureB()
+			configureA()
+		} else {
+			configureA()
+			configureB()
+		}
+		return sm
+	}
+
+	description := build(false).Describe()
+	if reordered := build(true).Describe(); reordered != description {
+		t.Errorf("expected the same description regardless of configuration order, got:\n%s\nand:\n%s",
+			description, reordered)
+	}
+
+	expected := `initial StateA
+state StateA
+  entry StartTimer
+  transition TriggerX -> StateB [guard: isReady]
+  transition TriggerX -> StateC [guard: isLate]
+  internal TriggerY [action: Function]
+  ignore TriggerZ
+state StateB
+  superstate StateC
+  exit StopTimer
+  dynamic TriggerY -> Function (StateA when always)
+state StateC
+  final
+`
+	if description != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, description)
+	}
+}
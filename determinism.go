@@ -0,0 +1,88 @@
+package stateless
+
+import (
+	"context"
+	"strings"
+)
+
+// DeterminismIssue reports that more than one behaviour configured for a trigger in a state would
+// handle the trigger when fired with the same args, which makes Fire fail at runtime.
+type DeterminismIssue[TState, TTrigger comparable] struct {
+	// State is the state configuring the behaviours.
+	State TState
+
+	// Trigger is the trigger the behaviours handle.
+	Trigger TTrigger
+
+	// Args are the sample args for which the guards of several behaviours are met.
+	Args any
+
+	// Guards describes, for each behaviour whose guards are met, its guard conditions separated by
+	// commas, or "unguarded" for a behaviour without guards.
+	Guards []string
+}
+
+// CheckDeterminism evaluates, for each state and trigger configuring several behaviours, the guards
+// of those behaviours with each of sampleArgs, and reports every state, trigger and sample for which
+// more than one behaviour's guards are met. With no samples, guards are evaluated once with nil args.
+// Issues are ordered by state, trigger and sample. Guards returning errors other than rejections
+// count as not met; no selectors or actions run.
+//
+// The result is only as good as the samples: an empty result means that none of them is ambiguous,
+// not that the guards are mutually exclusive for all args.
+func (sm *StateMachine[TState, TTrigger]) CheckDeterminism(
+	ctx context.Context,
+	sampleArgs []any,
+) []DeterminismIssue[TState, TTrigger] {
+	if len(sampleArgs) == 0 {
+		sampleArgs = []any{nil}
+	}
+
+	representations := sm.representations()
+	states := make([]TState, 0, len(representations))
+	for state := range representations {
+		states = append(states, state)
+	}
+	sortValues(states)
+
+	var issues []DeterminismIssue[TState, TTrigger]
+	for _, state := range states {
+		behavioursByTrigger := representations[state].TriggerBehaviours()
+		triggers := make([]TTrigger, 0, len(behavioursByTrigger))
+		for trigger, behaviours := range behavioursByTrigger {
+			if len(behaviours) > 1 {
+				triggers = append(triggers, trigger)
+			}
+		}
+		sortValues(triggers)
+
+		for _, trigger := range triggers {
+			for _, args := range sampleArgs {
+				var met []string
+				for _, behaviour := range behavioursByTrigger[trigger] {
+					if behaviour.GuardConditionsMet(ctx, args) == nil {
+						met = append(met, describeBehaviourGuards[TState](behaviour))
+					}
+				}
+				if len(met) > 1 {
+					issues = append(issues, DeterminismIssue[TState, TTrigger]{
+						State:   state,
+						Trigger: trigger,
+						Args:    args,
+						Guards:  met,
+					})
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// describeBehaviourGuards describes the guard conditions of a behaviour for a DeterminismIssue.
+func describeBehaviourGuards[TState, TTrigger comparable](behaviour TriggerBehaviour[TState, TTrigger]) string {
+	conditions := behaviour.GetGuard().Conditions
+	if len(conditions) == 0 {
+		return "unguarded"
+	}
+	return strings.Join(guardDescriptions(convertGuardConditions(conditions)), ", ")
+}
@@ -0,0 +1,52 @@
+package stateless
+
+import "slices"
+
+// DisableTrigger makes the state machine treat trigger as unhandled in every state, whatever its
+// configuration, until EnableTrigger is called: firing it goes through OnUnhandledTriggerHandler or
+// OnUnhandledTrigger, or returns an InvalidTransitionError, and queries such as CanFire and
+// GetPermittedTriggers no longer report it. It is safe to call while the machine is firing; a
+// trigger already being processed is not affected.
+func (sm *StateMachine[TState, TTrigger]) DisableTrigger(trigger TTrigger) {
+	sm.disabledMutex.Lock()
+	defer sm.disabledMutex.Unlock()
+	if sm.disabledTriggers == nil {
+		sm.disabledTriggers = make(map[TTrigger]struct{})
+	}
+	sm.disabledTriggers[trigger] = struct{}{}
+	sm.anyDisabled.Store(true)
+}
+
+// EnableTrigger re-enables a trigger disabled with DisableTrigger.
+func (sm *StateMachine[TState, TTrigger]) EnableTrigger(trigger TTrigger) {
+	sm.disabledMutex.Lock()
+	defer sm.disabledMutex.Unlock()
+	delete(sm.disabledTriggers, trigger)
+	sm.anyDisabled.Store(len(sm.disabledTriggers) > 0)
+}
+
+// IsTriggerDisabled returns true if the trigger was disabled with DisableTrigger.
+func (sm *StateMachine[TState, TTrigger]) IsTriggerDisabled(trigger TTrigger) bool {
+	if !sm.anyDisabled.Load() {
+		return false
+	}
+	sm.disabledMutex.RLock()
+	defer sm.disabledMutex.RUnlock()
+	_, disabled := sm.disabledTriggers[trigger]
+	return disabled
+}
+
+// hasDisabledTriggers returns true if any trigger is disabled.
+func (sm *StateMachine[TState, TTrigger]) hasDisabledTriggers() bool {
+	sm.disabledMutex.RLock()
+	defer sm.disabledMutex.RUnlock()
+	return len(sm.disabledTriggers) > 0
+}
+
+// withoutDisabledTriggers removes the disabled triggers from a list of triggers.
+func (sm *StateMachine[TState, TTrigger]) withoutDisabledTriggers(triggers []TTrigger) []TTrigger {
+	if !sm.hasDisabledTriggers() {
+		return triggers
+	}
+	return slices.DeleteFunc(triggers, sm.IsTriggerDisabled)
+}
@@ -0,0 +1,91 @@
+package stateless
+
+import "context"
+
+// dispatchTable caches, per state and trigger, the handler of triggers whose resolution does not
+// depend on any guard, for the configuration version it was built from.
+type dispatchTable[TState, TTrigger comparable] struct {
+	version  uint64
+	handlers map[TState]map[TTrigger]*TriggerBehaviourResult[TState, TTrigger]
+}
+
+// SetPrecompiledDispatch enables a dispatch table that resolves unguarded triggers without walking
+// the behaviours of the state and its superstates on every fire. The table is built on the first
+// fire after the configuration changed and caches, for each state and trigger, the handler when the
+// first state in the hierarchy that configures the trigger has a single behaviour for it and that
+// behaviour has no guard. Guarded triggers, default transitions and ambiguous configurations are
+// still resolved live, so behaviour is unchanged. Machines created by Builder.Build use it by default.
+// Disabled by default.
+func (sm *StateMachine[TState, TTrigger]) SetPrecompiledDispatch(enable bool) {
+	sm.panicIfSealed("SetPrecompiledDispatch")
+	sm.dispatchMutex.Lock()
+	defer sm.dispatchMutex.Unlock()
+	sm.precompiledDispatch.Store(enable)
+	sm.dispatch.Store(nil)
+}
+
+// findHandler resolves the handler for a trigger fired in the state of representation, using the
+// dispatch table when it is enabled and covers the trigger.
+func (sm *StateMachine[TState, TTrigger]) findHandler(
+	ctx context.Context,
+	representation *StateRepresentation[TState, TTrigger],
+	tr TTrigger,
+	args any,
+) *TriggerBehaviourResult[TState, TTrigger] {
+	if table := sm.dispatchTable(); table != nil {
+		if result, ok := table.handlers[representation.UnderlyingState()][tr]; ok {
+			return result
+		}
+	}
+	return representation.TryFindHandler(ctx, tr, args)
+}
+
+// dispatchTable returns the dispatch table for the current configuration, building it if needed,
+// or nil if precompiled dispatch is disabled.
+func (sm *StateMachine[TState, TTrigger]) dispatchTable() *dispatchTable[TState, TTrigger] {
+	if !sm.precompiledDispatch.Load() {
+		return nil
+	}
+	version := sm.configVersion.Load()
+	if table := sm.dispatch.Load(); table != nil && table.version == version {
+		return table
+	}
+
+	table := sm.buildDispatchTable(version)
+	sm.dispatchMutex.Lock()
+	defer sm.dispatchMutex.Unlock()
+	if sm.precompiledDispatch.Load() {
+		sm.dispatch.Store(table)
+	}
+	return table
+}
+
+// buildDispatchTable resolves every unguarded trigger of every configured state.
+func (sm *StateMachine[TState, TTrigger]) buildDispatchTable(version uint64) *dispatchTable[TState, TTrigger] {
+	table := &dispatchTable[TState, TTrigger]{
+		version:  version,
+		handlers: make(map[TState]map[TTrigger]*TriggerBehaviourResult[TState, TTrigger]),
+	}
+	for state, representation := range sm.representations() {
+		handlers := make(map[TTrigger]*TriggerBehaviourResult[TState, TTrigger])
+		for rep := representation; rep != nil; rep = rep.Superstate() {
+			for trigger, behaviours := range rep.TriggerBehaviours() {
+				if _, resolved := handlers[trigger]; resolved {
+					continue
+				}
+				// The innermost state configuring the trigger decides; nil marks it as resolved live
+				handlers[trigger] = nil
+				if len(behaviours) == 1 && behaviours[0].GetGuard().IsEmpty() {
+					handlers[trigger] = &TriggerBehaviourResult[TState, TTrigger]{Handler: behaviours[0], Owner: rep}
+				}
+			}
+		}
+		for trigger, result := range handlers {
+			if result == nil {
+				delete(handlers, trigger)
+			}
+		}
+		table.handlers[state] = handlers
+	}
+	return table
+}
@@ -0,0 +1,71 @@
+package stateless_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/atlekbai/stateless"
+)
+
+// newLargeMachine builds a mostly unguarded machine of 200 states in nested groups of four
+// levels, where each leaf inherits most of its triggers from its superstates.
+func newLargeMachine(precompiled bool) *stateless.StateMachine[int, int] {
+	const (
+		states   = 200
+		triggers = 20
+	)
+	sm := stateless.NewStateMachine[int, int](states - 1)
+	sm.SetPrecompiledDispatch(precompiled)
+	for state := range states {
+		node := sm.Configure(state)
+		if state%4 != 0 {
+			node.SubstateOf(state - 1)
+		}
+		for trigger := state % 4 * 5; trigger < state%4*5+5 && trigger < triggers; trigger++ {
+			node.Permit(trigger, (state+trigger+1)%states)
+		}
+		node.PermitIf(triggers, (state+1)%states, func(ctx context.Context, args any) error { return nil })
+	}
+	return sm
+}
+
+func TestSetPrecompiledDispatch(t *testing.T) {
+	live, precompiled := newLargeMachine(false), newLargeMachine(true)
+	for i := range 1000 {
+		trigger := i * 7 % 21
+		liveErr := live.Fire(trigger, nil)
+		precompiledErr := precompiled.Fire(trigger, nil)
+		if (liveErr == nil) != (precompiledErr == nil) || live.State() != precompiled.State() {
+			t.Fatalf("fire %d of trigger %d: expected %v (%v), got %v (%v)",
+				i, trigger, live.State(), liveErr, precompiled.State(), precompiledErr)
+		}
+	}
+
+	// The table follows configuration changes
+	precompiled.Configure(precompiled.State()).Permit(21, 0)
+	if err := precompiled.Fire(21, nil); err != nil || precompiled.State() != 0 {
+		t.Errorf("expected the new transition to be taken, got %v (%v)", precompiled.State(), err)
+	}
+}
+
+// benchmarkFire fires only handled triggers, many of them inherited from superstates, so that every
+// fire resolves a handler and takes a transition.
+func benchmarkFire(b *testing.B, precompiled bool) {
+	sm := newLargeMachine(precompiled)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		// A state at level l of its group handles triggers 0 to 5l+4, directly or through its superstates
+		if err := sm.Fire(i%(sm.State()%4*5+5), nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFire_Live(b *testing.B) {
+	benchmarkFire(b, false)
+}
+
+func BenchmarkFire_PrecompiledDispatch(b *testing.B) {
+	benchmarkFire(b, true)
+}
@@ -0,0 +1,37 @@
+package stateless
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// setState stores a new current state and records when it was entered.
+func (sm *StateMachine[TState, TTrigger]) setState(state TState) {
+	sm.stateMutator(state)
+	sm.enteredAt.Store(int64(sm.clock.Now().Sub(sm.clockEpoch)))
+	sm.updateTickers()
+}
+
+// TimeInState returns how long the machine has been in its current state: the time since the last
+// transition, reentry or initial transition, or since the machine was created if none happened yet.
+// State changes made directly through external storage are not seen.
+func (sm *StateMachine[TState, TTrigger]) TimeInState() time.Duration {
+	return sm.clock.Now().Sub(sm.clockEpoch) - time.Duration(sm.enteredAt.Load())
+}
+
+// MinDwell returns a guard that rejects until the machine has been in its current state for at
+// least d, as measured by TimeInState. Use it to debounce or rate-limit transitions:
+//
+//	sm.Configure(StateA).PermitIf(TriggerX, StateB, sm.MinDwell(5*time.Second))
+//
+// When the guard is configured on a superstate, the time is measured from the last change of the
+// current substate.
+func (sm *StateMachine[TState, TTrigger]) MinDwell(d time.Duration) GuardFunc {
+	return func(_ context.Context, _ any) error {
+		if elapsed := sm.TimeInState(); elapsed < d {
+			return Reject(fmt.Sprintf("state must be held for %v, only %v elapsed", d, elapsed))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,41 @@
+package stateless_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/atlekbai/stateless"
+)
+
+func TestMinDwell(t *testing.T) {
+	const dwell = 30 * time.Millisecond
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		PermitIf(TriggerX, StateC, sm.MinDwell(dwell))
+
+	time.Sleep(dwell)
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := sm.TimeInState(); elapsed >= dwell {
+		t.Errorf("expected time in state to restart on transition, got %v", elapsed)
+	}
+
+	if err := sm.Fire(TriggerX, nil); err == nil {
+		t.Fatal("expected guard to reject before the dwell time elapsed")
+	}
+	if sm.State() != StateB {
+		t.Fatalf("expected StateB, got %v", sm.State())
+	}
+
+	time.Sleep(dwell)
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateC {
+		t.Errorf("expected StateC, got %v", sm.State())
+	}
+}
@@ -0,0 +1,52 @@
+package stateless
+
+import (
+	"context"
+)
+
+// enteringFailureStateKey marks the context while a failure state configured with OnEntryFailure
+// is entered, so that a failing entry of the failure state does not start another compensation.
+type enteringFailureStateKey struct{}
+
+// enteringFailureState reports whether ctx belongs to the entry of a failure state.
+func enteringFailureState(ctx context.Context) bool {
+	entering, _ := ctx.Value(enteringFailureStateKey{}).(bool)
+	return entering
+}
+
+// enterState runs the entry actions for transition on rep. If they fail, the error is passed to
+// the error handler and, when the entered state has a failure state configured, the machine moves
+// there; the returned error is then an EntryFailedError.
+func (sm *StateMachine[TState, TTrigger]) enterState(
+	ctx context.Context,
+	rep *StateRepresentation[TState, TTrigger],
+	transition Transition[TState, TTrigger],
+) error {
+	err := rep.enter(ctx, transition, sm.anyEntryHook())
+	if err == nil {
+		return nil
+	}
+	err = sm.handleActionError(ctx, transition, PhaseEntry, err)
+
+	failureState, ok := rep.EntryFailureState()
+	if !ok || IsGuardRejection(err) || enteringFailureState(ctx) {
+		return err
+	}
+
+	entryErr := &EntryFailedError{State: transition.Destination, FailureState: failureState, Err: err}
+	ctx = context.WithValue(ctx, enteringFailureStateKey{}, true)
+	compensation := NewTransition(transition.Destination, failureState, transition.Trigger, transition.Args)
+
+	sm.setState(failureState)
+	sm.onTransitionedEvent.InvokeCtx(ctx, compensation)
+	if err := sm.getRepresentation(failureState).enter(ctx, compensation, sm.anyEntryHook()); err != nil {
+		entryErr.FailureErr = sm.handleActionError(ctx, compensation, PhaseEntry, err)
+		return entryErr
+	}
+	if sm.State() == failureState {
+		if _, err := sm.handleInitialTransitions(ctx, failureState, transition.Trigger, transition.Args); err != nil {
+			entryErr.FailureErr = err
+		}
+	}
+	return entryErr
+}
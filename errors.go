@@ -3,6 +3,7 @@ package stateless
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 )
 
@@ -28,6 +29,13 @@ func (e *ArgumentError) Error() string {
 	return e.Message
 }
 
+// ErrTriggerUnhandled is returned, wrapped with the trigger and state, by a fire whose trigger was
+// passed to OnUnhandledTrigger when SetUnhandledReturnsError is enabled.
+var ErrTriggerUnhandled = errors.New("trigger was not handled")
+
+// ErrShuttingDown is returned by fires refused, and by queue processing stopped, after Shutdown.
+var ErrShuttingDown = errors.New("state machine is shutting down")
+
 // InvalidTransitionError is thrown when a trigger is fired from a state that
 // does not have a valid transition for that trigger.
 type InvalidTransitionError struct {
@@ -38,25 +46,31 @@ type InvalidTransitionError struct {
 }
 
 func (e *InvalidTransitionError) Error() string {
+	var permitted string
+	if len(e.PermittedTriggers) > 0 {
+		triggers := make([]string, len(e.PermittedTriggers))
+		for i, t := range e.PermittedTriggers {
+			triggers[i] = fmt.Sprintf("%v", t)
+		}
+		permitted = fmt.Sprintf(" Permitted triggers: %s.", strings.Join(triggers, ", "))
+	}
+
 	if len(e.UnmetGuards) > 0 {
 		guardMessages := make([]string, len(e.UnmetGuards))
 		for i, err := range e.UnmetGuards {
 			guardMessages[i] = err.Error()
 		}
-		return fmt.Sprintf(
+		message := fmt.Sprintf(
 			"trigger '%v' is valid for transition from state '%v' "+
 				"but guard conditions are not met. Guard conditions: %s",
 			e.Trigger, e.State, strings.Join(guardMessages, ", "))
+		if permitted != "" {
+			message += "." + permitted
+		}
+		return message
 	}
 
-	var permitted string
-	if len(e.PermittedTriggers) > 0 {
-		triggers := make([]string, len(e.PermittedTriggers))
-		for i, t := range e.PermittedTriggers {
-			triggers[i] = fmt.Sprintf("%v", t)
-		}
-		permitted = fmt.Sprintf(" Permitted triggers: %s.", strings.Join(triggers, ", "))
-	} else {
+	if permitted == "" {
 		permitted = " No valid leaving transitions are permitted from state."
 	}
 
@@ -65,15 +79,55 @@ func (e *InvalidTransitionError) Error() string {
 		e.State, e.Trigger, permitted)
 }
 
-// ParameterConversionError indicates an error during parameter conversion.
+// AsInvalidTransition reports whether err is or wraps an InvalidTransitionError, and returns its
+// trigger and state converted back to their concrete types. It returns false if they are not of
+// types TTrigger and TState.
+func AsInvalidTransition[TState, TTrigger comparable](err error) (trigger TTrigger, state TState, ok bool) {
+	var invalid *InvalidTransitionError
+	if !errors.As(err, &invalid) {
+		return trigger, state, false
+	}
+	trigger, triggerOK := invalid.Trigger.(TTrigger)
+	state, stateOK := invalid.State.(TState)
+	if !triggerOK || !stateOK {
+		var zeroTrigger TTrigger
+		var zeroState TState
+		return zeroTrigger, zeroState, false
+	}
+	return trigger, state, true
+}
+
+// formattedGuardError is an unmet guard whose reason was rewritten by SetGuardReasonFormatter.
+type formattedGuardError struct {
+	reason string
+	err    error
+}
+
+func (e *formattedGuardError) Error() string {
+	return e.reason
+}
+
+// Unwrap returns the original guard error.
+func (e *formattedGuardError) Unwrap() error {
+	return e.err
+}
+
+// ParameterConversionError indicates an error during parameter conversion,
+// such as a decoder registered with SetArgDecoder failing. Err holds the underlying error, if any.
 type ParameterConversionError struct {
 	Message string
+	Err     error
 }
 
 func (e *ParameterConversionError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the underlying error.
+func (e *ParameterConversionError) Unwrap() error {
+	return e.Err
+}
+
 // GuardRejectionError represents an expected guard rejection.
 // Use this to indicate that a guard intentionally blocked a transition,
 // as opposed to an unexpected error during guard evaluation.
@@ -105,3 +159,125 @@ func IsGuardRejection(err error) bool {
 	var rejection *GuardRejectionError
 	return errors.As(err, &rejection)
 }
+
+// RetryError is returned by a guard to signal that the transition is not possible yet
+// but may become possible later, as opposed to a definitive rejection.
+// When no behaviour for the fired trigger is permitted and one of the guards asked for a retry,
+// Fire returns the guard's error, as returned with any wrapping, instead of an InvalidTransitionError,
+// so callers can detect it with errors.As.
+// The state machine never retries on its own: re-firing the trigger is up to the caller.
+type RetryError struct {
+	Reason string
+}
+
+func (e *RetryError) Error() string {
+	return "retry later: " + e.Reason
+}
+
+// Retry creates a RetryError with the given reason.
+// Use this in guard functions to indicate that the trigger should be fired again later:
+//
+//	PermitIf(TriggerX, StateB, func(_ context.Context, _ any) error {
+//	    if !resourceReady() {
+//	        return stateless.Retry("resource not ready")
+//	    }
+//	    return nil
+//	})
+func Retry(reason string) error {
+	return &RetryError{Reason: reason}
+}
+
+// IsRetry returns true if the error is or contains a RetryError.
+func IsRetry(err error) bool {
+	var retry *RetryError
+	return errors.As(err, &retry)
+}
+
+// ArgumentTypeError is returned by Fire when the args of a trigger do not match the type
+// registered with SetTriggerParameters. Actual is nil when no args were passed.
+type ArgumentTypeError struct {
+	Trigger  any
+	Expected reflect.Type
+	Actual   reflect.Type
+}
+
+func (e *ArgumentTypeError) Error() string {
+	if e.Actual == nil {
+		return fmt.Sprintf("trigger '%v' expects args of type %v, but no args were passed", e.Trigger, e.Expected)
+	}
+	return fmt.Sprintf("trigger '%v' expects args of type %v, but got %v", e.Trigger, e.Expected, e.Actual)
+}
+
+// MaxDepthExceededError is returned by Fire in FiringImmediate mode when triggers fired from
+// within actions nest deeper than the limit set with SetMaxImmediateDepth.
+type MaxDepthExceededError struct {
+	Trigger  any
+	MaxDepth int
+}
+
+func (e *MaxDepthExceededError) Error() string {
+	return fmt.Sprintf(
+		"firing trigger '%v' exceeds the maximum immediate firing depth of %d; "+
+			"check for triggers fired recursively from actions",
+		e.Trigger, e.MaxDepth)
+}
+
+// UnconfiguredStateError is returned by Fire when the current state of the state machine was never
+// configured, for example because external storage returned a stale or unknown state.
+type UnconfiguredStateError struct {
+	State any
+}
+
+func (e *UnconfiguredStateError) Error() string {
+	return fmt.Sprintf("state '%v' has not been configured; use Configure to configure it", e.State)
+}
+
+// EntryFailedError is returned by Fire when an entry action of a state configured with
+// OnEntryFailure fails and the state machine moves to the configured failure state instead.
+// Err is the original entry error; FailureErr is set if entering the failure state failed as well.
+type EntryFailedError struct {
+	State        any
+	FailureState any
+	Err          error
+	FailureErr   error
+}
+
+func (e *EntryFailedError) Error() string {
+	if e.FailureErr != nil {
+		return fmt.Sprintf("entering state '%v' failed: %v; entering failure state '%v' also failed: %v",
+			e.State, e.Err, e.FailureState, e.FailureErr)
+	}
+	return fmt.Sprintf("entering state '%v' failed, moved to failure state '%v': %v", e.State, e.FailureState, e.Err)
+}
+
+// Unwrap returns the original entry error and, if any, the error from entering the failure state.
+func (e *EntryFailedError) Unwrap() []error {
+	if e.FailureErr != nil {
+		return []error{e.Err, e.FailureErr}
+	}
+	return []error{e.Err}
+}
+
+// BranchRejection describes a branch of PermitIfExhaustive whose guard was not met.
+type BranchRejection struct {
+	Destination any
+	Description string
+	Reason      error
+}
+
+// NoBranchMatchedError is returned by Fire when none of the branches configured with
+// PermitIfExhaustive for the trigger has its guard met.
+type NoBranchMatchedError struct {
+	Trigger  any
+	State    any
+	Branches []BranchRejection
+}
+
+func (e *NoBranchMatchedError) Error() string {
+	branches := make([]string, len(e.Branches))
+	for i, b := range e.Branches {
+		branches[i] = fmt.Sprintf("to '%v' when %s: %v", b.Destination, b.Description, b.Reason)
+	}
+	return fmt.Sprintf("no branch of trigger '%v' matched in state '%v'; evaluated branches: %s",
+		e.Trigger, e.State, strings.Join(branches, "; "))
+}
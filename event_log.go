@@ -0,0 +1,127 @@
+package stateless
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// LoggedEvent is a trigger recorded by the event log, see EnableEventLog.
+type LoggedEvent[TTrigger comparable] struct {
+	Event[TTrigger]
+
+	// Timestamp is when the trigger was processed, according to the machine's Clock.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// EnableEventLog makes the state machine record every trigger it processes, with its args and a
+// timestamp, so that its state can be rebuilt later with Replay. Triggers are recorded when they
+// are processed, which for FiringQueued machines may be after Fire returned. A trigger is recorded
+// if it was handled without error, or if it changed the state before an action failed; triggers
+// that were rejected without effect are not recorded.
+func (sm *StateMachine[TState, TTrigger]) EnableEventLog() {
+	sm.eventLogMutex.Lock()
+	defer sm.eventLogMutex.Unlock()
+	if sm.eventLog == nil {
+		sm.eventLog = []LoggedEvent[TTrigger]{}
+	}
+	sm.eventLogEnabled.Store(true)
+}
+
+// EventLog returns a copy of the events recorded since EnableEventLog was called,
+// oldest first, or nil if the event log is not enabled.
+func (sm *StateMachine[TState, TTrigger]) EventLog() []LoggedEvent[TTrigger] {
+	sm.eventLogMutex.Lock()
+	defer sm.eventLogMutex.Unlock()
+	return slices.Clone(sm.eventLog)
+}
+
+// logEvent records a processed trigger if the event log is enabled.
+func (sm *StateMachine[TState, TTrigger]) logEvent(tr TTrigger, args any) {
+	if !sm.eventLogEnabled.Load() {
+		return
+	}
+	sm.eventLogMutex.Lock()
+	defer sm.eventLogMutex.Unlock()
+	if sm.eventLog == nil {
+		return
+	}
+	sm.eventLog = append(sm.eventLog, LoggedEvent[TTrigger]{
+		Event:     Event[TTrigger]{Trigger: tr, Args: args},
+		Timestamp: sm.clock.Now(),
+	})
+}
+
+// processTrigger processes a single trigger and records it in the event log.
+func (sm *StateMachine[TState, TTrigger]) processTrigger(
+	ctx context.Context,
+	tr TTrigger,
+	args any,
+) (Transition[TState, TTrigger], error) {
+	sm.processing.Add(1)
+	defer sm.processing.Add(-1)
+	ctx = sm.withMachineValues(ctx)
+
+	timed := len(sm.timedHandlers) > 0 && !sm.replayMode
+	var start time.Time
+	if timed {
+		start = sm.clock.Now()
+	}
+
+	source := sm.State()
+	transition, err := sm.internalFire(ctx, tr, args)
+	if !sm.replayMode && (err == nil || sm.State() != source) {
+		sm.logEvent(tr, args)
+	}
+	if !sm.replayMode && err == nil {
+		sm.logTransition(ctx, transition)
+	}
+	if timed && err == nil && (transition.Kind == TransitionExternal || sm.emitCompletedForNonTransitions) {
+		duration := sm.clock.Now().Sub(start)
+		for _, handler := range sm.timedHandlers {
+			handler(transition, duration)
+		}
+	}
+	return transition, err
+}
+
+// SetReplayMode controls whether fired triggers only move the state. In replay mode, handlers are
+// resolved and guards, dynamic selectors and initial-transition guards are evaluated as usual so the
+// same transitions are chosen, but entry, exit and internal actions (including OnAnyEntry and
+// OnAnyExit), OnTransitioning handlers, OnTransitioned, OnTransitionCompleted and OnTerminalState
+// callbacks are skipped, no PermitTick timer starts, and triggers are not added to the event log.
+// Initial transitions are still followed. Turning it off resumes normal behaviour from the state
+// reached, starting its timers if the machine is active.
+// Disabled by default.
+func (sm *StateMachine[TState, TTrigger]) SetReplayMode(replay bool) {
+	sm.replayMode = replay
+	sm.updateTickers()
+}
+
+// Replay rebuilds a state machine from an event log. It calls config to create and configure a
+// fresh machine, then fires the events in order in replay mode (see SetReplayMode): states change
+// and guards are evaluated to choose transitions, but no actions run and no events are raised.
+// The machine then behaves normally. If its event log is enabled, it is set to the replayed events.
+//
+// The first event that fails stops the replay, and its error is returned.
+func Replay[TState, TTrigger comparable](
+	config func() *StateMachine[TState, TTrigger],
+	events []LoggedEvent[TTrigger],
+) (*StateMachine[TState, TTrigger], error) {
+	sm := config()
+	sm.SetReplayMode(true)
+	for i, event := range events {
+		if err := sm.FireCtx(context.Background(), event.Trigger, event.Args); err != nil {
+			return nil, fmt.Errorf("replaying event %d (trigger '%v'): %w", i, event.Trigger, err)
+		}
+	}
+	sm.SetReplayMode(false)
+
+	sm.eventLogMutex.Lock()
+	defer sm.eventLogMutex.Unlock()
+	if sm.eventLog != nil {
+		sm.eventLog = slices.Clone(events)
+	}
+	return sm, nil
+}
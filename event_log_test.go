@@ -0,0 +1,158 @@
+package stateless_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/atlekbai/stateless"
+)
+
+func TestEventLog_Replay(t *testing.T) {
+	var entries []string
+	config := func() *stateless.StateMachine[State, Trigger] {
+		sm := stateless.NewStateMachine[State, Trigger](StateA)
+		sm.EnableEventLog()
+		sm.Configure(StateA).
+			Permit(TriggerX, StateB)
+		sm.Configure(StateB).
+			OnEntry(func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+				entries = append(entries, "EntryB")
+				return nil
+			}).
+			PermitIf(TriggerY, StateC, func(_ context.Context, args any) error {
+				if args != "go" {
+					return stateless.Reject("not go")
+				}
+				return nil
+			})
+		sm.Configure(StateC)
+		return sm
+	}
+
+	sm := config()
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := sm.Fire(TriggerY, "stop"); err == nil {
+		t.Fatal("expected guard rejection")
+	}
+	if err := sm.Fire(TriggerY, "go"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	log := sm.EventLog()
+	if len(log) != 2 {
+		t.Fatalf("expected 2 logged events, got %v", log)
+	}
+	if log[0].Trigger != TriggerX || log[1].Trigger != TriggerY || log[1].Args != "go" {
+		t.Errorf("unexpected log: %v", log)
+	}
+	if log[0].Timestamp.IsZero() {
+		t.Error("expected events to be timestamped")
+	}
+
+	entries = nil
+	replayed, err := stateless.Replay(config, log)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if replayed.State() != StateC {
+		t.Errorf("expected replayed machine in StateC, got %v", replayed.State())
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no actions during replay, got %v", entries)
+	}
+	if len(replayed.EventLog()) != 2 {
+		t.Errorf("expected replayed machine to keep the log, got %v", replayed.EventLog())
+	}
+}
+
+func TestReplay_Error(t *testing.T) {
+	config := func() *stateless.StateMachine[State, Trigger] {
+		sm := stateless.NewStateMachine[State, Trigger](StateA)
+		sm.Configure(StateA).Permit(TriggerX, StateB)
+		sm.Configure(StateB)
+		return sm
+	}
+	events := []stateless.LoggedEvent[Trigger]{
+		{Event: stateless.Event[Trigger]{Trigger: TriggerX}},
+		{Event: stateless.Event[Trigger]{Trigger: TriggerY}},
+	}
+
+	sm, err := stateless.Replay(config, events)
+
+	var invalid *stateless.InvalidTransitionError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected InvalidTransitionError, got %v", err)
+	}
+	if sm != nil {
+		t.Errorf("expected no machine on error, got %v", sm)
+	}
+}
+
+func TestEventLog_DisabledByDefault(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if log := sm.EventLog(); log != nil {
+		t.Errorf("expected no event log, got %v", log)
+	}
+}
+
+func TestSetReplayMode(t *testing.T) {
+	var record []string
+	action := func(name string) stateless.TransitionAction[State, Trigger] {
+		return func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			record = append(record, name)
+			return nil
+		}
+	}
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		OnExit(action("ExitA")).
+		InternalTransition(TriggerY, action("InternalA")).
+		Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		OnEntry(action("EntryB")).
+		InitialTransition(StateC).
+		Permit(TriggerZ, StateD)
+	sm.Configure(StateC).
+		SubstateOf(StateB).
+		OnEntry(action("EntryC"))
+	sm.Configure(StateD).
+		OnEntry(action("EntryD"))
+	sm.OnTransitioned(func(t stateless.Transition[State, Trigger]) {
+		record = append(record, "Transitioned")
+	})
+	sm.OnTransitionCompleted(func(t stateless.Transition[State, Trigger]) {
+		record = append(record, "Completed")
+	})
+
+	sm.SetReplayMode(true)
+	for _, tr := range []Trigger{TriggerY, TriggerX} {
+		if err := sm.Fire(tr, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if sm.State() != StateC {
+		t.Errorf("expected initial transition to be followed to StateC, got %v", sm.State())
+	}
+	if len(record) != 0 {
+		t.Errorf("expected no actions or events in replay mode, got %v", record)
+	}
+
+	sm.SetReplayMode(false)
+	if err := sm.Fire(TriggerZ, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"Transitioned", "EntryD", "Completed"}
+	if !slices.Equal(record, expected) {
+		t.Errorf("expected %v after leaving replay mode, got %v", expected, record)
+	}
+}
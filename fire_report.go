@@ -0,0 +1,117 @@
+package stateless
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// FireReport describes the outcome of a trigger fired with FireWithReport.
+type FireReport[TState, TTrigger comparable] struct {
+	// Transition is the transition that occurred, as returned by FireResult.
+	Transition Transition[TState, TTrigger]
+
+	// Queued is set in FiringQueued mode when the trigger was only enqueued because another
+	// trigger was being processed; the rest of the report is then empty.
+	Queued bool
+
+	// HandlingState is the state whose configuration handled the trigger: the state it was fired
+	// in or the superstate the behaviour is inherited from. Valid when Handled is set.
+	HandlingState TState
+
+	// Handled is false if no configured behaviour handled the trigger, for example when it was
+	// swallowed by OnUnhandledTrigger or resolved by OnUnhandledTriggerHandler.
+	Handled bool
+
+	// Retries are the retry signals raised while the trigger was processed: guards that returned
+	// Retry, even if another behaviour handled the trigger, and a RetryError returned by Fire.
+	Retries []*RetryError
+
+	// Warnings describe other non-fatal issues, such as the trigger being an alias or going unhandled.
+	Warnings []string
+}
+
+// fireReportKey carries the fireRecorder of a trigger fired with FireWithReport in a context.
+type fireReportKey struct{}
+
+// fireRecorder collects the report of a trigger while it is processed. Processing may happen on
+// another goroutine in FiringQueued mode, and finish after FireWithReport returned, so the
+// recorder is locked and ignores everything once closed.
+type fireRecorder[TState, TTrigger comparable] struct {
+	mutex     sync.Mutex
+	closed    bool
+	processed bool
+	report    FireReport[TState, TTrigger]
+}
+
+// fireRecorderFrom returns the recorder carried by ctx, or nil.
+func fireRecorderFrom[TState, TTrigger comparable](ctx context.Context) *fireRecorder[TState, TTrigger] {
+	recorder, _ := ctx.Value(fireReportKey{}).(*fireRecorder[TState, TTrigger])
+	return recorder
+}
+
+// start records that processing of the trigger has started.
+func (r *fireRecorder[TState, TTrigger]) start() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if !r.closed {
+		r.processed = true
+	}
+}
+
+// record applies update to the report unless the recorder is closed.
+func (r *fireRecorder[TState, TTrigger]) record(update func(report *FireReport[TState, TTrigger])) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if !r.closed {
+		update(&r.report)
+	}
+}
+
+// FireWithReport fires a trigger like FireResult and also returns a FireReport with the state
+// that handled the trigger, the retry signals raised while processing it and other non-fatal
+// issues. The returned error is the one FireResult would return.
+//
+// Only the trigger itself is reported: triggers fired from its actions are not. In FiringQueued
+// mode, a trigger that is only enqueued is reported with Queued set.
+func (sm *StateMachine[TState, TTrigger]) FireWithReport(
+	ctx context.Context,
+	tr TTrigger,
+	args any,
+) (FireReport[TState, TTrigger], error) {
+	recorder := &fireRecorder[TState, TTrigger]{}
+	transition, err := sm.fire(context.WithValue(ctx, fireReportKey{}, recorder), tr, args)
+
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.closed = true
+	if !recorder.processed && sm.firingMode == FiringQueued && err == nil {
+		return FireReport[TState, TTrigger]{Queued: true}, nil
+	}
+
+	report := recorder.report
+	report.Transition = transition
+	if canonical := sm.canonicalTrigger(tr); canonical != tr {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("trigger '%v' was fired as an alias of '%v'", tr, canonical))
+	}
+	var retry *RetryError
+	if errors.As(err, &retry) && !containsRetry(report.Retries, retry) {
+		report.Retries = append(report.Retries, retry)
+	}
+	if !report.Handled && err == nil {
+		report.Warnings = append(report.Warnings,
+			fmt.Sprintf("trigger '%v' was not handled in state '%v'", transition.Trigger, transition.Source))
+	}
+	return report, err
+}
+
+// containsRetry reports whether retries already holds retry.
+func containsRetry(retries []*RetryError, retry *RetryError) bool {
+	for _, r := range retries {
+		if r == retry {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package stateless
+
+import (
+	"context"
+	"sync"
+)
+
+// transitionResultKey carries the transitionResult of a trigger fired with FireForValue in a context.
+type transitionResultKey struct{}
+
+// transitionResult holds the value set with Transition.SetResult while a trigger fired with
+// FireForValue is processed. Processing may happen on another goroutine in FiringQueued mode, and
+// finish after FireForValue returned, so the result is locked and ignores values once closed.
+type transitionResult struct {
+	mutex   sync.Mutex
+	claimed bool
+	closed  bool
+	value   any
+}
+
+// transitionResultFrom returns the result carried by ctx, or nil.
+func transitionResultFrom(ctx context.Context) *transitionResult {
+	result, _ := ctx.Value(transitionResultKey{}).(*transitionResult)
+	return result
+}
+
+// claim reports whether the result is free, and takes it for the trigger being processed if so.
+// Triggers fired from the actions of that trigger find it taken.
+func (r *transitionResult) claim() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.claimed {
+		return false
+	}
+	r.claimed = true
+	return true
+}
+
+// set stores value unless the result is closed.
+func (r *transitionResult) set(value any) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if !r.closed {
+		r.value = value
+	}
+}
+
+// withTransitionResult returns ctx without a result if the trigger about to be processed is not
+// the one the result was created for, so that only the transitions of that trigger carry it.
+func withTransitionResult(ctx context.Context) context.Context {
+	if result := transitionResultFrom(ctx); result != nil && !result.claim() {
+		return context.WithValue(ctx, transitionResultKey{}, (*transitionResult)(nil))
+	}
+	return ctx
+}
+
+// FireForValue fires a trigger like FireCtx and returns the value set by its actions with
+// Transition.SetResult, or nil if none was set. Every action and handler given a transition of the
+// trigger can set it, including exit, entry and internal actions; when several do, the last value
+// wins. Triggers fired from those actions do not set the value of this one. The value is discarded
+// if the fire fails.
+//
+// In FiringQueued mode, if another trigger is being processed the trigger is only enqueued and
+// nil is returned.
+func (sm *StateMachine[TState, TTrigger]) FireForValue(ctx context.Context, tr TTrigger, args any) (any, error) {
+	result := &transitionResult{}
+	_, err := sm.fire(context.WithValue(ctx, transitionResultKey{}, result), tr, args)
+
+	result.mutex.Lock()
+	defer result.mutex.Unlock()
+	result.closed = true
+	if err != nil {
+		return nil, err
+	}
+	return result.value, nil
+}
@@ -0,0 +1,58 @@
+package stateless
+
+import "context"
+
+// drainingQueueKey marks the context of triggers processed from the queue of a state machine,
+// whose value is the machine, so that FireAndWait can refuse to wait on its own queue.
+type drainingQueueKey struct{}
+
+// FireAndWait fires a trigger and, in FiringQueued mode, blocks until the machine has processed
+// its queue, returning the first error from processing. If nothing is being processed the trigger
+// is processed right away, as with FireCtx. Otherwise it is enqueued and FireAndWait waits until
+// the queue is empty or processing stops with an error, or until ctx is done, in which case the
+// trigger stays queued and ctx.Err() is returned. In FiringImmediate mode it is equivalent to FireCtx.
+//
+// Waiting on the queue from one of its own actions would never return, so calling FireAndWait with
+// the context of an action of the same machine returns an InvalidOperationError.
+func (sm *StateMachine[TState, TTrigger]) FireAndWait(ctx context.Context, tr TTrigger, args any) error {
+	if sm.firingMode != FiringQueued {
+		return sm.FireCtx(ctx, tr, args)
+	}
+	if ctx.Value(drainingQueueKey{}) == any(sm) {
+		return &InvalidOperationError{
+			Message: "FireAndWait cannot be called from an action of the same state machine; use Fire instead",
+		}
+	}
+
+	sm.mutex.Lock()
+	sm.eventQueue.Push(QueuedEvent[TTrigger]{
+		Event:   Event[TTrigger]{Trigger: tr, Args: args},
+		Context: ctx,
+	})
+	if !sm.firing {
+		sm.firing = true
+		sm.mutex.Unlock()
+		_, err := sm.processQueue()
+		return err
+	}
+	done := make(chan error, 1)
+	sm.queueWaiters = append(sm.queueWaiters, done)
+	sm.mutex.Unlock()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stopFiring clears the firing flag once processQueue stops and tells the FireAndWait callers
+// waiting for the queue how processing ended. The caller must hold sm.mutex.
+func (sm *StateMachine[TState, TTrigger]) stopFiring(err error) {
+	sm.firing = false
+	for _, waiter := range sm.queueWaiters {
+		waiter <- err
+	}
+	sm.queueWaiters = nil
+}
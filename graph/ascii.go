@@ -0,0 +1,67 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/atlekbai/stateless"
+)
+
+// AsciiGraph generates a plain-text state diagram from state machine info, for terminals and logs.
+// Each state is listed with its outgoing transitions as "A --X--> B" lines; guards are appended
+// to the trigger in brackets. Substates are indented under their superstate and the initial
+// state is marked with a leading "*". Dynamic transitions are listed once per possible destination.
+func AsciiGraph(machineInfo *stateless.StateMachineInfo) string {
+	sg := NewStateGraph(machineInfo)
+
+	initial := ""
+	if sg.InitialState != nil {
+		initial = fmt.Sprintf("%v", sg.InitialState.UnderlyingState)
+	}
+
+	leaving := make(map[*State][]*Transition)
+	for _, transit := range sg.getSortedTransitions() {
+		if transit.SourceState != nil && transit.DestinationState != nil {
+			leaving[transit.SourceState] = append(leaving[transit.SourceState], transit)
+		}
+	}
+
+	var sb strings.Builder
+	var writeState func(state *State, indent string)
+	writeState = func(state *State, indent string) {
+		marker := ""
+		if state.StateName == initial {
+			marker = "*"
+		}
+		fmt.Fprintf(&sb, "%s%s%s\n", indent, marker, state.StateName)
+
+		for _, transit := range leaving[state] {
+			fmt.Fprintf(&sb, "%s  %s --%s--> %s\n",
+				indent, state.StateName, asciiLabel(transit), transit.DestinationState.StateName)
+		}
+
+		substates := sg.getSubStates(state)
+		sort.Slice(substates, func(i, j int) bool { return substates[i].StateName < substates[j].StateName })
+		for _, sub := range substates {
+			writeState(sub, indent+"  ")
+		}
+	}
+
+	for _, name := range sg.getSortedStateNames() {
+		if state := sg.States[name]; state.StateInfo == nil || state.StateInfo.Superstate == nil {
+			writeState(state, "")
+		}
+	}
+
+	return sb.String()
+}
+
+// asciiLabel returns the trigger of a transition followed by its guards.
+func asciiLabel(transit *Transition) string {
+	label := fmt.Sprintf("%v", transit.Trigger.UnderlyingTrigger)
+	for _, guard := range collectGuards(transit) {
+		label += " [" + guard + "]"
+	}
+	return label
+}
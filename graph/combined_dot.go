@@ -0,0 +1,67 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atlekbai/stateless"
+)
+
+// Region is a state machine rendered as one region of a combined graph.
+type Region struct {
+	// Name labels the region. Regions without a name are labelled by their position.
+	Name string
+
+	// Info describes the state machine of the region.
+	Info *stateless.StateMachineInfo
+}
+
+// regionDotGraphStyle renders a state machine as a cluster of a combined DOT graph.
+type regionDotGraphStyle struct {
+	*UmlDotGraphStyle
+
+	index int
+	name  string
+}
+
+// GetPrefix returns the text that starts the region's cluster.
+// The cluster is closed by GetInitialTransition.
+func (s *regionDotGraphStyle) GetPrefix() string {
+	return fmt.Sprintf("\nsubgraph \"cluster_region%d\"\n\t{\n\tlabel = \"%s\"\n", s.index, EscapeLabel(s.name))
+}
+
+// CombinedDotGraph renders several state machines as separate regions of a single UML DOT graph,
+// for example machines modelling independent aspects of the same component. Each region is a
+// cluster with its own initial node, labelled "Region 1", "Region 2" and so on.
+// This is a visualization aid only; it does not make the machines orthogonal regions of one machine.
+func CombinedDotGraph(infos ...*stateless.StateMachineInfo) string {
+	regions := make([]Region, len(infos))
+	for i, info := range infos {
+		regions[i] = Region{Info: info}
+	}
+	return CombinedDotGraphRegions(regions...)
+}
+
+// CombinedDotGraphRegions is like CombinedDotGraph, but labels each region with its Name.
+func CombinedDotGraphRegions(regions ...Region) string {
+	var sb strings.Builder
+	sb.WriteString(NewUmlDotGraphStyle().GetPrefix())
+
+	for i, region := range regions {
+		index := i + 1
+		name := region.Name
+		if name == "" {
+			name = fmt.Sprintf("Region %d", index)
+		}
+		style := &regionDotGraphStyle{
+			UmlDotGraphStyle: &UmlDotGraphStyle{nodePrefix: fmt.Sprintf("region%d_", index)},
+			index:            index,
+			name:             name,
+		}
+		sb.WriteString(NewStateGraph(region.Info).ToGraph(style))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("}")
+	return sb.String()
+}
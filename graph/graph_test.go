@@ -2,6 +2,8 @@ package graph_test
 
 import (
 	"context"
+	"encoding/xml"
+	"io"
 	"strings"
 	"testing"
 
@@ -279,6 +281,27 @@ func TestDotGraph_SimpleTransition(t *testing.T) {
 	}
 }
 
+func TestDotGraph_ActivationActions(t *testing.T) {
+	sm := stateless.NewStateMachine[TestState, TestTrigger](TestStateA)
+	sm.Configure(TestStateA).
+		OnActivate(func(_ context.Context) error { return nil }).
+		OnDeactivate(func(_ context.Context) error { return nil }).
+		Permit(TestTriggerX, TestStateB)
+	sm.Configure(TestStateB)
+
+	dotGraph := graph.UmlDotGraph(sm.GetInfo())
+
+	if !strings.Contains(dotGraph, `"A" [label="A|activate / `) {
+		t.Errorf("Expected state A to show its activate action, got:\n%s", dotGraph)
+	}
+	if !strings.Contains(dotGraph, `\ndeactivate / `) {
+		t.Errorf("Expected state A to show its deactivate action, got:\n%s", dotGraph)
+	}
+	if !strings.Contains(dotGraph, `"B" [label="B"]`) {
+		t.Errorf("Expected state B to have no actions, got:\n%s", dotGraph)
+	}
+}
+
 func TestDotGraph_TwoSimpleTransitions(t *testing.T) {
 	sm := stateless.NewStateMachine[TestState, TestTrigger](TestStateA)
 	sm.Configure(TestStateA).
@@ -695,7 +718,7 @@ func TestMermaidGraph_TransitionWithIgnore(t *testing.T) {
 		t.Errorf("Expected graph to contain A --> B : X transition, got:\n%s", mermaidGraph)
 	}
 	// Should contain ignore transition (self-loop)
-	if !strings.Contains(mermaidGraph, "A --> A : Y") {
+	if !strings.Contains(mermaidGraph, "A --> A : Y (ignored)") {
 		t.Errorf("Expected graph to contain A --> A : Y (ignored) transition, got:\n%s", mermaidGraph)
 	}
 }
@@ -775,8 +798,26 @@ func TestMermaidGraph_InternalTransition(t *testing.T) {
 	mermaidGraph := graph.MermaidGraph(sm.GetInfo(), nil)
 
 	// Should contain self-loop for internal transition
-	if !strings.Contains(mermaidGraph, "A --> A : X") {
-		t.Errorf("Expected graph to contain A --> A : X internal transition, got:\n%s", mermaidGraph)
+	if !strings.Contains(mermaidGraph, "A --> A : X (internal)") {
+		t.Errorf("Expected graph to contain A --> A : X (internal) transition, got:\n%s", mermaidGraph)
+	}
+}
+
+func TestMermaidGraph_SelfLoopKindsAreDistinct(t *testing.T) {
+	sm := stateless.NewStateMachine[TestState, TestTrigger](TestStateA)
+	sm.Configure(TestStateA).
+		InternalTransition(TestTriggerX, func(_ context.Context, _ stateless.Transition[TestState, TestTrigger]) error {
+			return nil
+		}).
+		PermitReentry(TestTriggerY).
+		IgnoreIf(TestTriggerZ, func(_ context.Context, _ any) error { return nil }, "idle")
+
+	mermaidGraph := graph.MermaidGraph(sm.GetInfo(), nil)
+
+	for _, want := range []string{"A --> A : X (internal)\n", "A --> A : Y\n", "A --> A : Z (ignored) [idle]"} {
+		if !strings.Contains(mermaidGraph, want) {
+			t.Errorf("Expected graph to contain %q, got:\n%s", want, mermaidGraph)
+		}
 	}
 }
 
@@ -798,3 +839,318 @@ func TestMermaidGraph_OnEntryWithTriggerCheck(t *testing.T) {
 		t.Errorf("Expected graph to contain transition, got:\n%s", mermaidGraph)
 	}
 }
+
+func TestMermaidGraph_ActionNotes(t *testing.T) {
+	sm := stateless.NewStateMachine[TestState, TestTrigger](TestStateA)
+	sm.Configure(TestStateA).
+		Permit(TestTriggerX, TestStateB).
+		OnExit(func(ctx context.Context, tr stateless.Transition[TestState, TestTrigger]) error { return nil })
+	sm.Configure(TestStateB).
+		SubstateOf(TestStateD).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[TestState, TestTrigger]) error { return nil })
+	sm.Configure(TestStateD)
+
+	mermaidGraph := graph.MermaidGraphWithActionNotes(sm.GetInfo(), nil)
+
+	expectedA := "\tnote right of A\n\t\texit / " + stateless.DefaultFunctionDescription + "\n\tend note"
+	if !strings.Contains(mermaidGraph, expectedA) {
+		t.Errorf("Expected graph to contain exit note for A, got:\n%s", mermaidGraph)
+	}
+	expectedB := "\tnote right of B\n\t\tentry / " + stateless.DefaultFunctionDescription + "\n\tend note"
+	if !strings.Contains(mermaidGraph, expectedB) {
+		t.Errorf("Expected graph to contain entry note for substate B, got:\n%s", mermaidGraph)
+	}
+	if strings.Contains(mermaidGraph, "note right of D") {
+		t.Errorf("Expected no note for D without actions, got:\n%s", mermaidGraph)
+	}
+
+	// Notes are opt-in
+	if plain := graph.MermaidGraph(sm.GetInfo(), nil); strings.Contains(plain, "note") {
+		t.Errorf("Expected no notes by default, got:\n%s", plain)
+	}
+}
+
+func TestGraphMLGraph(t *testing.T) {
+	sm := stateless.NewStateMachine[TestState, TestTrigger](TestStateA)
+	sm.Configure(TestStateA).
+		PermitIf(TestTriggerX, TestStateB, func(_ context.Context, _ any) error { return nil }, "ready & able").
+		PermitDynamic(
+			TestTriggerZ,
+			func(_ context.Context, _ any) (TestState, error) { return TestStateD, nil },
+			stateless.DynamicStateInfo{DestinationState: "D", Criterion: "ChoseD"},
+		)
+	sm.Configure(TestStateB).
+		InitialTransition(TestStateC).
+		Permit(TestTriggerY, TestStateA)
+	sm.Configure(TestStateC).SubstateOf(TestStateB)
+	sm.Configure(TestStateD)
+
+	graphML := graph.GraphMLGraph(sm.GetInfo())
+
+	// The document must be well-formed XML
+	decoder := xml.NewDecoder(strings.NewReader(graphML))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid XML: %v\n%s", err, graphML)
+		}
+	}
+
+	expected := []string{
+		`<graph id="G" edgedefault="directed">`,
+		`<node id="n1" yfiles.foldertype="group">`,
+		`<graph id="n1:" edgedefault="directed">`,
+		`<node id="n1::n0">`,
+		`<y:NodeLabel>C</y:NodeLabel>`,
+		`<y:Shape type="diamond"/>`,
+		`<edge id="e0" source="n0" target="n1">`,
+		`<y:EdgeLabel>X [ready &amp; able]</y:EdgeLabel>`,
+		`<y:EdgeLabel>Y</y:EdgeLabel>`,
+		`source="n0" target="n3"`,
+		`source="n3" target="n2"`,
+		`source="init" target="n0"`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(graphML, want) {
+			t.Errorf("expected GraphML to contain %q, got:\n%s", want, graphML)
+		}
+	}
+}
+
+func TestDotGraph_IgnoreIfShowsGuardDescription(t *testing.T) {
+	sm := stateless.NewStateMachine[TestState, TestTrigger](TestStateA)
+	sm.Configure(TestStateA).
+		Ignore(TestTriggerX).
+		IgnoreIf(TestTriggerY, func(_ context.Context, _ any) error { return nil }, "busy").
+		IgnoreIf(TestTriggerZ, func(_ context.Context, _ any) error { return nil })
+
+	dotGraph := graph.UmlDotGraph(sm.GetInfo())
+
+	if !strings.Contains(dotGraph, `"A" -> "A" [style="solid", label="X"];`) {
+		t.Errorf("Expected unguarded ignore to render without brackets, got:\n%s", dotGraph)
+	}
+	if !strings.Contains(dotGraph, `"A" -> "A" [style="solid", label="Y [busy]"];`) {
+		t.Errorf("Expected guarded ignore to render its guard description, got:\n%s", dotGraph)
+	}
+	if !strings.Contains(dotGraph, `"A" -> "A" [style="solid", label="Z [Function]"];`) {
+		t.Errorf("Expected anonymous guard to render the default description, got:\n%s", dotGraph)
+	}
+}
+
+func TestAsciiGraph(t *testing.T) {
+	sm := stateless.NewStateMachine[TestState, TestTrigger](TestStateA)
+	sm.Configure(TestStateA).
+		PermitIf(TestTriggerX, TestStateC, func(_ context.Context, _ any) error { return nil }, "ready")
+	sm.Configure(TestStateB).
+		Permit(TestTriggerZ, TestStateA)
+	sm.Configure(TestStateC).
+		SubstateOf(TestStateB).
+		Permit(TestTriggerY, TestStateD)
+	sm.Configure(TestStateD)
+
+	expected := "*A\n" +
+		"  A --X [ready]--> C\n" +
+		"B\n" +
+		"  B --Z--> A\n" +
+		"  C\n" +
+		"    C --Y--> D\n" +
+		"D\n"
+
+	if got := graph.AsciiGraph(sm.GetInfo()); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestMermaidGraphOpts(t *testing.T) {
+	sm := stateless.NewStateMachine[TestState, TestTrigger](TestStateA)
+	sm.Configure(TestStateA).
+		PermitIf(TestTriggerX, TestStateB, func(_ context.Context, _ any) error { return nil }, "ready")
+	sm.Configure(TestStateB)
+
+	info := sm.GetInfo()
+	direction := graph.LeftToRight
+	withGuards := graph.MermaidGraphOpts(info, graph.MermaidOptions{
+		Direction:  &direction,
+		Title:      "Orders",
+		ShowGuards: true,
+		Theme:      "dark",
+	})
+
+	expectedPrefix := "---\ntitle: Orders\nconfig:\n  theme: dark\n---\nstateDiagram-v2\n\tdirection LR"
+	if !strings.HasPrefix(withGuards, expectedPrefix) {
+		t.Errorf("expected prefix:\n%s\ngot:\n%s", expectedPrefix, withGuards)
+	}
+	if !strings.Contains(withGuards, "A --> B : X [ready]") {
+		t.Errorf("expected guarded transition label, got:\n%s", withGuards)
+	}
+
+	withoutGuards := graph.MermaidGraphOpts(info, graph.MermaidOptions{})
+	if !strings.HasPrefix(withoutGuards, "stateDiagram-v2") {
+		t.Errorf("expected no frontmatter, got:\n%s", withoutGuards)
+	}
+	if strings.Contains(withoutGuards, "[ready]") || !strings.Contains(withoutGuards, "A --> B : X") {
+		t.Errorf("expected transition label without guard, got:\n%s", withoutGuards)
+	}
+
+	if graph.MermaidGraph(info, &direction) != graph.MermaidGraphOpts(info, graph.MermaidOptions{
+		Direction:  &direction,
+		ShowGuards: true,
+	}) {
+		t.Error("expected MermaidGraph to match MermaidGraphOpts with guards shown")
+	}
+}
+
+func TestCombinedDotGraph(t *testing.T) {
+	power := stateless.NewStateMachine[TestState, TestTrigger](TestStateA)
+	power.Configure(TestStateA).Permit(TestTriggerX, TestStateB)
+	power.Configure(TestStateB)
+
+	network := stateless.NewStateMachine[TestState, TestTrigger](TestStateB)
+	network.Configure(TestStateA)
+	network.Configure(TestStateB).Permit(TestTriggerY, TestStateA)
+
+	dotGraph := graph.CombinedDotGraphRegions(
+		graph.Region{Name: "Power", Info: power.GetInfo()},
+		graph.Region{Info: network.GetInfo()},
+	)
+
+	for _, expected := range []string{
+		"subgraph \"cluster_region1\"",
+		"label = \"Power\"",
+		"subgraph \"cluster_region2\"",
+		"label = \"Region 2\"",
+		`"region1_A" [label="A"];`,
+		`"region2_A" [label="A"];`,
+		`"region1_A" -> "region1_B" [style="solid", label="X"];`,
+		`"region2_B" -> "region2_A" [style="solid", label="Y"];`,
+		`"region1_init" -> "region1_A"`,
+		`"region2_init" -> "region2_B"`,
+	} {
+		if !strings.Contains(dotGraph, expected) {
+			t.Errorf("expected graph to contain %s, got:\n%s", expected, dotGraph)
+		}
+	}
+	if strings.Count(dotGraph, "{") != strings.Count(dotGraph, "}") {
+		t.Errorf("expected balanced braces, got:\n%s", dotGraph)
+	}
+
+	unnamed := graph.CombinedDotGraph(power.GetInfo(), network.GetInfo())
+	if !strings.Contains(unnamed, "label = \"Region 1\"") || !strings.Contains(unnamed, "label = \"Region 2\"") {
+		t.Errorf("expected regions labelled by position, got:\n%s", unnamed)
+	}
+}
+
+func TestGraph_ColorTag(t *testing.T) {
+	sm := stateless.NewStateMachine[TestState, TestTrigger](TestStateA)
+	sm.Configure(TestStateA).WithTag("color", "lightblue").Permit(TestTriggerX, TestStateB)
+	sm.Configure(TestStateB).WithTag("color", "pink").WithTag("permission", "admin").SubstateOf(TestStateC)
+	sm.Configure(TestStateC).WithTag("color", "lightblue")
+	sm.Configure(TestStateD)
+
+	info := sm.GetInfo()
+	if tags := info.States[1].Tags; tags["color"] != "pink" || tags["permission"] != "admin" {
+		t.Errorf("expected the tags of B in StateInfo, got %v", tags)
+	}
+
+	style := graph.NewUmlDotGraphStyle()
+	style.ColorTag = "color"
+	dotGraph := graph.NewStateGraph(info).ToGraph(style)
+	for _, expected := range []string{
+		`"A" [label="A", style=filled, fillcolor="lightblue"];`,
+		`"B" [label="B", style=filled, fillcolor="pink"];`,
+		"\tlabel = \"C\"\n\tstyle = filled\n\tfillcolor = \"lightblue\"\n",
+		`"D" [label="D"];`,
+	} {
+		if !strings.Contains(dotGraph, expected) {
+			t.Errorf("expected DOT graph to contain %q, got:\n%s", expected, dotGraph)
+		}
+	}
+
+	mermaid := graph.MermaidGraphOpts(info, graph.MermaidOptions{ColorTag: "color"})
+	expected := "\n\tclassDef color0 fill:lightblue\n\tclassDef color1 fill:pink" +
+		"\n\tclass A color0\n\tclass B color1\n\tclass C color0"
+	if !strings.Contains(mermaid, expected) {
+		t.Errorf("expected Mermaid graph to contain %q, got:\n%s", expected, mermaid)
+	}
+}
+
+func TestDotGraph_ResumedMachineShowsTrueInitialState(t *testing.T) {
+	sm := stateless.NewStateMachineResumed[TestState, TestTrigger](TestStateA, TestStateB)
+	sm.Configure(TestStateA).Permit(TestTriggerX, TestStateB)
+	sm.Configure(TestStateB)
+
+	dotGraph := graph.UmlDotGraph(sm.GetInfo())
+
+	if !strings.Contains(dotGraph, `init -> "A"`) {
+		t.Errorf("expected the initial arrow to point at A, got:\n%s", dotGraph)
+	}
+}
+
+func TestGraph_Legend(t *testing.T) {
+	sm := stateless.NewStateMachine[TestState, TestTrigger](TestStateA)
+	sm.Configure(TestStateA).Permit(TestTriggerX, TestStateB)
+	sm.Configure(TestStateB)
+	info := sm.GetInfo()
+
+	if dotGraph := graph.UmlDotGraph(info); strings.Contains(dotGraph, "legend") {
+		t.Errorf("expected no legend by default, got:\n%s", dotGraph)
+	}
+
+	style := graph.NewUmlDotGraphStyle()
+	style.ShowLegend = true
+	dotGraph := graph.NewStateGraph(info).ToGraph(style)
+	initial := "\n init [label=\"\", shape=point];\n init -> \"A\"[style = \"solid\"]\n}"
+	if !strings.HasSuffix(dotGraph, graph.DotLegend()+initial) {
+		t.Errorf("expected the legend ahead of the initial transition, got:\n%s", dotGraph)
+	}
+	for _, expected := range []string{
+		`"legend_decision" [shape = "diamond", label = "dynamic destination selector"];`,
+		`"legend_state" -> "legend_destination" [style="solid", label="Trigger [guard]"];`,
+		"subgraph \"cluster_legend_superstate\"\n\t{\n\tlabel = \"Superstate\"\n",
+	} {
+		if !strings.Contains(dotGraph, expected) {
+			t.Errorf("expected DOT legend to contain %q, got:\n%s", expected, dotGraph)
+		}
+	}
+
+	mermaid := graph.MermaidGraphOpts(info, graph.MermaidOptions{ShowLegend: true})
+	if !strings.HasSuffix(mermaid, "\n[*] --> A"+graph.MermaidLegend()) {
+		t.Errorf("expected the legend after the initial transition, got:\n%s", mermaid)
+	}
+	for _, expected := range []string{
+		"\t\tstate legend_decision <<choice>>\n",
+		"\t\tlegend_destination --> legend_destination : Trigger (internal)\n",
+		"\t\tlegend_destination --> legend_destination : Trigger (ignored)\n",
+	} {
+		if !strings.Contains(mermaid, expected) {
+			t.Errorf("expected Mermaid legend to contain %q, got:\n%s", expected, mermaid)
+		}
+	}
+}
+
+func TestGraph_FinalStates(t *testing.T) {
+	sm := stateless.NewStateMachine[TestState, TestTrigger](TestStateA)
+	sm.Configure(TestStateA).Permit(TestTriggerX, TestStateB).Permit(TestTriggerY, TestStateD)
+	sm.Configure(TestStateB).SubstateOf(TestStateC).MarkFinal()
+	sm.Configure(TestStateC)
+	sm.Configure(TestStateD).MarkFinal()
+	info := sm.GetInfo()
+
+	dotGraph := graph.UmlDotGraph(info)
+	expected := "\n init -> \"A\"[style = \"solid\"]" +
+		"\n final [label=\"\", shape=point, peripheries=2];" +
+		"\n \"B\" -> final[style = \"solid\"]" +
+		"\n \"D\" -> final[style = \"solid\"]" +
+		"\n}"
+	if !strings.HasSuffix(dotGraph, expected) {
+		t.Errorf("expected DOT graph to end with %q, got:\n%s", expected, dotGraph)
+	}
+
+	mermaid := graph.MermaidGraph(info, nil)
+	if expected := "\n[*] --> A\nB --> [*]\nD --> [*]"; !strings.HasSuffix(mermaid, expected) {
+		t.Errorf("expected Mermaid graph to end with %q, got:\n%s", expected, mermaid)
+	}
+}
@@ -0,0 +1,205 @@
+package graph
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/atlekbai/stateless"
+)
+
+// graphMLHeader opens a GraphML document with the yEd graphics extensions declared.
+const graphMLHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns"` +
+	` xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"` +
+	` xmlns:y="http://www.yworks.com/xml/graphml"` +
+	` xsi:schemaLocation="http://graphml.graphdrawing.org/xmlns` +
+	` http://www.yworks.com/xml/schema/graphml/1.1/ygraphml.xsd">
+  <key id="d0" for="node" yfiles.type="nodegraphics"/>
+  <key id="d1" for="edge" yfiles.type="edgegraphics"/>
+  <graph id="G" edgedefault="directed">
+`
+
+// GraphMLGraph generates a GraphML document from state machine info, suitable for import into yEd.
+// Each state becomes a node, superstates become group nodes with a nested graph of their substates,
+// and each transition becomes an edge labeled with its trigger, entry actions and guards.
+// Dynamic transitions are rendered as an edge into a diamond decision node and edges out of it.
+func GraphMLGraph(machineInfo *stateless.StateMachineInfo) string {
+	sg := NewStateGraph(machineInfo)
+	w := &graphMLWriter{graph: sg, ids: make(map[string]string)}
+	return w.write()
+}
+
+// graphMLWriter renders a StateGraph as GraphML.
+type graphMLWriter struct {
+	graph *StateGraph
+	sb    strings.Builder
+
+	// ids maps state and decision node names to GraphML node ids.
+	ids map[string]string
+
+	edgeCount int
+}
+
+// write renders the whole document.
+func (w *graphMLWriter) write() string {
+	w.sb.WriteString(graphMLHeader)
+
+	var roots []*State
+	for _, name := range w.graph.getSortedStateNames() {
+		if state := w.graph.States[name]; state.StateInfo == nil || state.StateInfo.Superstate == nil {
+			roots = append(roots, state)
+		}
+	}
+	for i, state := range roots {
+		w.writeState(state, fmt.Sprintf("n%d", i), "    ")
+	}
+
+	for i, dec := range w.graph.Decisions {
+		id := fmt.Sprintf("n%d", len(roots)+i)
+		w.ids[dec.NodeName] = id
+		w.writeNode(id, dec.Method.Description(), "diamond", "    ")
+	}
+
+	w.writeTransitions()
+
+	if w.graph.InitialState != nil {
+		if target, ok := w.ids[fmt.Sprintf("%v", w.graph.InitialState.UnderlyingState)]; ok {
+			w.writeNode("init", "", "ellipse", "    ")
+			w.writeEdge("init", target, "")
+		}
+	}
+
+	w.sb.WriteString("  </graph>\n")
+	w.sb.WriteString("</graphml>\n")
+	return w.sb.String()
+}
+
+// writeState writes a state node, nesting its substates in a group node when it has any.
+func (w *graphMLWriter) writeState(state *State, id, indent string) {
+	w.ids[state.StateName] = id
+
+	substates := w.graph.getSubStates(state)
+	if len(substates) == 0 {
+		w.writeNode(id, stateLabel(state), "roundrectangle", indent)
+		return
+	}
+
+	sort.Slice(substates, func(i, j int) bool { return substates[i].StateName < substates[j].StateName })
+
+	fmt.Fprintf(&w.sb, "%s<node id=\"%s\" yfiles.foldertype=\"group\">\n", indent, id)
+	fmt.Fprintf(&w.sb, "%s  <data key=\"d0\">\n", indent)
+	fmt.Fprintf(&w.sb, "%s    <y:ProxyAutoBoundsNode>\n", indent)
+	fmt.Fprintf(&w.sb, "%s      <y:Realizers active=\"0\">\n", indent)
+	fmt.Fprintf(&w.sb, "%s        <y:GroupNode>\n", indent)
+	fmt.Fprintf(&w.sb, "%s          <y:NodeLabel>%s</y:NodeLabel>\n", indent, escapeXML(stateLabel(state)))
+	fmt.Fprintf(&w.sb, "%s          <y:Shape type=\"roundrectangle\"/>\n", indent)
+	fmt.Fprintf(&w.sb, "%s        </y:GroupNode>\n", indent)
+	fmt.Fprintf(&w.sb, "%s      </y:Realizers>\n", indent)
+	fmt.Fprintf(&w.sb, "%s    </y:ProxyAutoBoundsNode>\n", indent)
+	fmt.Fprintf(&w.sb, "%s  </data>\n", indent)
+	fmt.Fprintf(&w.sb, "%s  <graph id=\"%s:\" edgedefault=\"directed\">\n", indent, id)
+	for i, sub := range substates {
+		w.writeState(sub, fmt.Sprintf("%s::n%d", id, i), indent+"    ")
+	}
+	fmt.Fprintf(&w.sb, "%s  </graph>\n", indent)
+	fmt.Fprintf(&w.sb, "%s</node>\n", indent)
+}
+
+// writeNode writes a simple node with the given label and shape.
+func (w *graphMLWriter) writeNode(id, label, shape, indent string) {
+	fmt.Fprintf(&w.sb, "%s<node id=\"%s\">\n", indent, id)
+	fmt.Fprintf(&w.sb, "%s  <data key=\"d0\">\n", indent)
+	fmt.Fprintf(&w.sb, "%s    <y:ShapeNode>\n", indent)
+	fmt.Fprintf(&w.sb, "%s      <y:NodeLabel>%s</y:NodeLabel>\n", indent, escapeXML(label))
+	fmt.Fprintf(&w.sb, "%s      <y:Shape type=\"%s\"/>\n", indent, shape)
+	fmt.Fprintf(&w.sb, "%s    </y:ShapeNode>\n", indent)
+	fmt.Fprintf(&w.sb, "%s  </data>\n", indent)
+	fmt.Fprintf(&w.sb, "%s</node>\n", indent)
+}
+
+// writeTransitions writes an edge per transition, routing dynamic transitions through their decision node.
+func (w *graphMLWriter) writeTransitions() {
+	fromDecision := make(map[*Transition]*Decision)
+	for _, dec := range w.graph.Decisions {
+		for _, transit := range dec.Leaving {
+			fromDecision[transit] = dec
+		}
+	}
+
+	for _, transit := range w.graph.getSortedTransitions() {
+		if transit.DestinationState == nil || fromDecision[transit] != nil {
+			continue
+		}
+		w.writeEdge(w.ids[transit.SourceState.StateName], w.ids[transit.DestinationState.StateName], edgeLabel(transit))
+	}
+
+	for _, dec := range w.graph.Decisions {
+		decisionID := w.ids[dec.NodeName]
+		for _, transit := range dec.Arriving {
+			w.writeEdge(w.ids[transit.SourceState.StateName], decisionID, edgeLabel(transit))
+		}
+		for _, transit := range dec.Leaving {
+			w.writeEdge(decisionID, w.ids[transit.DestinationState.StateName], "")
+		}
+	}
+}
+
+// writeEdge writes a directed edge with an optional label.
+func (w *graphMLWriter) writeEdge(source, target, label string) {
+	fmt.Fprintf(&w.sb, "    <edge id=\"e%d\" source=\"%s\" target=\"%s\">\n", w.edgeCount, source, target)
+	w.sb.WriteString("      <data key=\"d1\">\n")
+	w.sb.WriteString("        <y:PolyLineEdge>\n")
+	w.sb.WriteString("          <y:Arrows source=\"none\" target=\"standard\"/>\n")
+	if label != "" {
+		fmt.Fprintf(&w.sb, "          <y:EdgeLabel>%s</y:EdgeLabel>\n", escapeXML(label))
+	}
+	w.sb.WriteString("        </y:PolyLineEdge>\n")
+	w.sb.WriteString("      </data>\n")
+	w.sb.WriteString("    </edge>\n")
+	w.edgeCount++
+}
+
+// stateLabel returns the state name followed by its entry and exit actions, one per line.
+func stateLabel(state *State) string {
+	lines := []string{state.StateName}
+	for _, act := range state.EntryActions {
+		lines = append(lines, "entry / "+act)
+	}
+	for _, act := range state.ExitActions {
+		lines = append(lines, "exit / "+act)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// edgeLabel returns the trigger of a transition followed by its entry actions and guards.
+func edgeLabel(transit *Transition) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%v", transit.Trigger.UnderlyingTrigger))
+
+	if transit.ExecuteEntryExitActions && len(transit.DestinationEntryActions) > 0 {
+		var actions []string
+		for _, act := range transit.DestinationEntryActions {
+			actions = append(actions, act.Description())
+		}
+		sb.WriteString(" / ")
+		sb.WriteString(strings.Join(actions, ", "))
+	}
+
+	for _, guard := range collectGuards(transit) {
+		sb.WriteString(" [")
+		sb.WriteString(guard)
+		sb.WriteString("]")
+	}
+
+	return sb.String()
+}
+
+// escapeXML escapes special characters in XML text and attribute values.
+func escapeXML(s string) string {
+	var sb strings.Builder
+	// Writing to a strings.Builder never fails
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
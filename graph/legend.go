@@ -0,0 +1,81 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DotLegend returns a DOT subgraph explaining the notation of UmlDotGraph, drawn with the same
+// shapes and labels: the initial point, states with their actions, guarded transitions, reentries,
+// internal transitions and ignored triggers, the diamond of a dynamic transition's selector, a
+// superstate cluster and the final state. Its nodes are prefixed with "legend_"; add it to a digraph, or set
+// UmlDotGraphStyle.ShowLegend to have it included.
+func DotLegend() string {
+	var sb strings.Builder
+	sb.WriteString("\nsubgraph \"cluster_legend\"\n")
+	sb.WriteString("\t{\n")
+	sb.WriteString("\tlabel = \"Legend\"\n")
+	sb.WriteString("\"legend_init\" [label=\"\", shape=point];\n")
+	sb.WriteString("\"legend_state\" [label=\"State|entry / action\\nexit / action\"];\n")
+	sb.WriteString("\"legend_destination\" [label=\"Destination\"];\n")
+	sb.WriteString("\"legend_decision\" [shape = \"diamond\", label = \"dynamic destination selector\"];\n")
+	sb.WriteString("\"legend_final\" [label=\"\", shape=point, peripheries=2];\n")
+	sb.WriteString("\nsubgraph \"cluster_legend_superstate\"\n")
+	sb.WriteString("\t{\n")
+	sb.WriteString("\tlabel = \"Superstate\"\n")
+	sb.WriteString("\"legend_substate\" [label=\"Substate\"];\n")
+	sb.WriteString("}\n")
+	for _, edge := range legendEdges(false) {
+		sb.WriteString(formatOneLine(edge.from, edge.to, edge.label))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// MermaidLegend returns a Mermaid composite state explaining the notation of MermaidGraph, drawn with
+// the same arrows and labels: the initial state, guarded transitions, reentries, internal transitions,
+// ignored triggers, the choice node of a dynamic transition, a superstate and the final state. Its states are prefixed
+// with "legend_"; add it to a stateDiagram-v2, or set MermaidOptions.ShowLegend to have it included.
+func MermaidLegend() string {
+	var sb strings.Builder
+	sb.WriteString("\n\tstate \"Legend\" as legend {\n")
+	sb.WriteString("\t\tstate \"State\" as legend_state\n")
+	sb.WriteString("\t\tstate \"Destination\" as legend_destination\n")
+	sb.WriteString("\t\tstate legend_decision <<choice>>\n")
+	sb.WriteString("\t\tnote right of legend_decision : dynamic destination selector\n")
+	sb.WriteString("\t\tstate \"Superstate\" as legend_superstate {\n")
+	sb.WriteString("\t\t\tstate \"Substate\" as legend_substate\n")
+	sb.WriteString("\t\t}\n")
+	sb.WriteString("\t\t[*] --> legend_state : initial state\n")
+	for _, edge := range legendEdges(true) {
+		sb.WriteString(fmt.Sprintf("\t\t%s --> %s : %s\n", edge.from, edge.to, edge.label))
+	}
+	sb.WriteString("\t}\n")
+	return sb.String()
+}
+
+// legendEdge is an example transition shown in a legend.
+type legendEdge struct {
+	from, to, label string
+}
+
+// legendEdges returns the example transitions of a legend. Mermaid labels internal transitions and
+// ignored triggers, which DOT draws alike; DOT draws the initial transition itself.
+func legendEdges(mermaid bool) []legendEdge {
+	edges := []legendEdge{
+		{"legend_state", "legend_destination", "Trigger [guard]"},
+		{"legend_state", "legend_state", "Reentry / entry action"},
+	}
+	if mermaid {
+		return append(edges,
+			legendEdge{"legend_destination", "legend_destination", "Trigger (internal)"},
+			legendEdge{"legend_destination", "legend_destination", "Trigger (ignored)"},
+			legendEdge{"legend_destination", "[*]", "final state"},
+		)
+	}
+	return append([]legendEdge{{"legend_init", "legend_state", "initial state"}}, append(edges,
+		legendEdge{"legend_destination", "legend_destination", "Internal or ignored trigger"},
+		legendEdge{"legend_destination", "legend_final", "final state"},
+	)...)
+}
@@ -2,6 +2,8 @@ package graph
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"unicode"
 
@@ -24,6 +26,25 @@ const (
 
 // MermaidGraphStyle generates Mermaid graphs.
 type MermaidGraphStyle struct {
+	// ShowActionNotes enables notes listing the entry and exit actions of each state.
+	ShowActionNotes bool
+
+	// HideGuards omits guard descriptions from transition labels.
+	HideGuards bool
+
+	// Title is emitted as the diagram title when not empty.
+	Title string
+
+	// Theme selects a Mermaid theme (for example "default", "dark" or "forest") when not empty.
+	Theme string
+
+	// ColorTag names the state tag (see StateNode.WithTag) whose value is used as the fill color
+	// of the state through a class definition. Empty disables coloring.
+	ColorTag string
+
+	// ShowLegend appends MermaidLegend to the diagram.
+	ShowLegend bool
+
 	graph               *StateGraph
 	direction           *MermaidGraphDirection
 	stateMap            map[string]*State
@@ -44,6 +65,7 @@ func (s *MermaidGraphStyle) GetPrefix() string {
 	s.buildSanitizedNamedStateMap()
 
 	var sb strings.Builder
+	sb.WriteString(s.formatFrontmatter())
 	sb.WriteString("stateDiagram-v2")
 
 	if s.direction != nil {
@@ -59,6 +81,59 @@ func (s *MermaidGraphStyle) GetPrefix() string {
 		}
 	}
 
+	sb.WriteString(s.formatColorClasses())
+
+	return sb.String()
+}
+
+// formatColorClasses defines a class per color found in the ColorTag of the states and assigns
+// each tagged state to its class.
+func (s *MermaidGraphStyle) formatColorClasses() string {
+	if s.ColorTag == "" || s.graph == nil {
+		return ""
+	}
+
+	classes := make(map[string]string)
+	var assignments []string
+	for _, stateName := range s.graph.getSortedStateNames() {
+		state := s.graph.States[stateName]
+		color, ok := stateTag(state, s.ColorTag)
+		if !ok {
+			continue
+		}
+		class, defined := classes[color]
+		if !defined {
+			class = fmt.Sprintf("color%d", len(classes))
+			classes[color] = class
+		}
+		assignments = append(assignments, fmt.Sprintf("\n\tclass %s %s", s.getSanitizedStateName(stateName), class))
+	}
+
+	var sb strings.Builder
+	for _, color := range slices.Sorted(maps.Keys(classes)) {
+		sb.WriteString(fmt.Sprintf("\n\tclassDef %s fill:%s", classes[color], color))
+	}
+	for _, assignment := range assignments {
+		sb.WriteString(assignment)
+	}
+	return sb.String()
+}
+
+// formatFrontmatter formats the YAML frontmatter carrying the title and theme, if any.
+func (s *MermaidGraphStyle) formatFrontmatter() string {
+	if s.Title == "" && s.Theme == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	if s.Title != "" {
+		sb.WriteString(fmt.Sprintf("title: %s\n", s.Title))
+	}
+	if s.Theme != "" {
+		sb.WriteString(fmt.Sprintf("config:\n  theme: %s\n", s.Theme))
+	}
+	sb.WriteString("---\n")
 	return sb.String()
 }
 
@@ -73,12 +148,40 @@ func (s *MermaidGraphStyle) FormatOneCluster(superState *SuperState) string {
 	}
 
 	sb.WriteString("\t}")
+
+	// Notes are emitted outside the composite state; nested superstates get theirs from their own cluster
+	sb.WriteString(s.formatActionNote(superState.State))
+	for _, subState := range superState.SubStates {
+		if subState.StateInfo == nil || len(subState.StateInfo.Substates) == 0 {
+			sb.WriteString(s.formatActionNote(subState))
+		}
+	}
+
 	return sb.String()
 }
 
-// FormatOneState formats a single state (Mermaid doesn't need explicit state definitions).
-func (s *MermaidGraphStyle) FormatOneState(_ *State) string {
-	return ""
+// FormatOneState formats a single state. Mermaid doesn't need explicit state definitions,
+// so only the action note is emitted when ShowActionNotes is enabled.
+func (s *MermaidGraphStyle) FormatOneState(state *State) string {
+	return s.formatActionNote(state)
+}
+
+// formatActionNote formats a note listing the entry and exit actions of a state.
+func (s *MermaidGraphStyle) formatActionNote(state *State) string {
+	if !s.ShowActionNotes || (len(state.EntryActions) == 0 && len(state.ExitActions) == 0) {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n\tnote right of %s", s.getSanitizedStateName(state.StateName)))
+	for _, act := range state.EntryActions {
+		sb.WriteString(fmt.Sprintf("\n\t\tentry / %s", act))
+	}
+	for _, act := range state.ExitActions {
+		sb.WriteString(fmt.Sprintf("\n\t\texit / %s", act))
+	}
+	sb.WriteString("\n\tend note")
+	return sb.String()
 }
 
 // FormatOneDecisionNode formats a decision node.
@@ -91,7 +194,20 @@ func (s *MermaidGraphStyle) FormatAllTransitions(
 	transitions []*Transition,
 	_ []*Decision,
 ) []string {
-	return FormatTransitions(s, transitions)
+	return formatLabeledTransitions(s, transitions, mermaidTriggerLabel)
+}
+
+// mermaidTriggerLabel labels internal transitions and ignored triggers, which Mermaid would
+// otherwise draw the same as a reentry without entry actions.
+func mermaidTriggerLabel(transit *Transition) string {
+	switch {
+	case transit.IsInternalTransition:
+		return triggerLabel(transit) + " (internal)"
+	case transit.IsIgnored:
+		return triggerLabel(transit) + " (ignored)"
+	default:
+		return triggerLabel(transit)
+	}
 }
 
 // FormatOneTransition formats a single transition.
@@ -110,7 +226,7 @@ func (s *MermaidGraphStyle) FormatOneTransition(
 		sb.WriteString(strings.Join(actions, ", "))
 	}
 
-	if len(guards) > 0 {
+	if len(guards) > 0 && !s.HideGuards {
 		for _, info := range guards {
 			if sb.Len() > 0 {
 				sb.WriteString(" ")
@@ -127,14 +243,25 @@ func (s *MermaidGraphStyle) FormatOneTransition(
 	return fmt.Sprintf("\t%s --> %s : %s", sanitizedSource, sanitizedDest, sb.String())
 }
 
-// GetInitialTransition returns the text for the initial state transition.
+// GetInitialTransition returns the text for the initial state transition, followed by the
+// transitions of final states to the end state.
 func (s *MermaidGraphStyle) GetInitialTransition(initialState *stateless.StateInfo) string {
-	if initialState == nil {
-		return ""
+	initial := ""
+	if initialState != nil {
+		sanitizedStateName := s.getSanitizedStateName(fmt.Sprintf("%v", initialState.UnderlyingState))
+		initial = fmt.Sprintf("\n[*] --> %s", sanitizedStateName)
 	}
-
-	sanitizedStateName := s.getSanitizedStateName(fmt.Sprintf("%v", initialState.UnderlyingState))
-	return fmt.Sprintf("\n[*] --> %s", sanitizedStateName)
+	if s.graph != nil {
+		for _, stateName := range s.graph.getSortedStateNames() {
+			if isFinalState(s.graph.States[stateName]) {
+				initial += fmt.Sprintf("\n%s --> [*]", s.getSanitizedStateName(stateName))
+			}
+		}
+	}
+	if s.ShowLegend {
+		initial += MermaidLegend()
+	}
+	return initial
 }
 
 // buildSanitizedNamedStateMap builds a map of sanitized state names to states.
@@ -204,6 +331,43 @@ func GetDirectionCode(direction MermaidGraphDirection) string {
 
 // MermaidGraph generates a Mermaid graph from state machine info.
 func MermaidGraph(machineInfo *stateless.StateMachineInfo, direction *MermaidGraphDirection) string {
+	return MermaidGraphOpts(machineInfo, MermaidOptions{Direction: direction, ShowGuards: true})
+}
+
+// MermaidOptions configures the output of MermaidGraphOpts.
+type MermaidOptions struct {
+	// Direction sets the flow direction; nil leaves it to Mermaid.
+	Direction *MermaidGraphDirection
+	// Title is emitted as the diagram title when not empty.
+	Title string
+	// ShowGuards includes guard descriptions in transition labels.
+	ShowGuards bool
+	// Theme selects a Mermaid theme (for example "default", "dark" or "forest") when not empty.
+	Theme string
+	// ColorTag names the state tag used as the fill color of each state; empty disables coloring.
+	ColorTag string
+	// ShowLegend appends a legend explaining the notation to the diagram.
+	ShowLegend bool
+}
+
+// MermaidGraphOpts generates a Mermaid graph from state machine info using the given options.
+// The title and theme are emitted as YAML frontmatter ahead of the diagram.
+func MermaidGraphOpts(machineInfo *stateless.StateMachineInfo, opts MermaidOptions) string {
+	graph := NewStateGraph(machineInfo)
+	style := NewMermaidGraphStyle(graph, opts.Direction)
+	style.Title = opts.Title
+	style.Theme = opts.Theme
+	style.HideGuards = !opts.ShowGuards
+	style.ColorTag = opts.ColorTag
+	style.ShowLegend = opts.ShowLegend
+	return graph.ToGraph(style)
+}
+
+// MermaidGraphWithActionNotes generates a Mermaid graph from state machine info,
+// with a note next to each state listing its entry and exit actions.
+func MermaidGraphWithActionNotes(machineInfo *stateless.StateMachineInfo, direction *MermaidGraphDirection) string {
 	graph := NewStateGraph(machineInfo)
-	return graph.ToGraph(NewMermaidGraphStyle(graph, direction))
+	style := NewMermaidGraphStyle(graph, direction)
+	style.ShowActionNotes = true
+	return graph.ToGraph(style)
 }
@@ -59,11 +59,13 @@ func (sg *StateGraph) addSuperstates(machineInfo *stateless.StateMachineInfo) {
 // createSuperState creates a SuperState from StateInfo.
 func (sg *StateGraph) createSuperState(stateInfo *stateless.StateInfo) *SuperState {
 	state := &State{
-		StateName:    fmt.Sprintf("%v", stateInfo.UnderlyingState),
-		NodeName:     fmt.Sprintf("%v", stateInfo.UnderlyingState),
-		EntryActions: sg.extractEntryActionDescriptions(stateInfo),
-		ExitActions:  sg.extractExitActionDescriptions(stateInfo),
-		StateInfo:    stateInfo,
+		StateName:         fmt.Sprintf("%v", stateInfo.UnderlyingState),
+		NodeName:          fmt.Sprintf("%v", stateInfo.UnderlyingState),
+		EntryActions:      sg.extractEntryActionDescriptions(stateInfo),
+		ExitActions:       sg.extractExitActionDescriptions(stateInfo),
+		ActivateActions:   sg.extractActionDescriptions(stateInfo.ActivateActions),
+		DeactivateActions: sg.extractActionDescriptions(stateInfo.DeactivateActions),
+		StateInfo:         stateInfo,
 	}
 	return &SuperState{
 		State:     state,
@@ -89,11 +91,13 @@ func (sg *StateGraph) addSubstates(superState *SuperState, substates []*stateles
 		} else {
 			// Regular state
 			sub := &State{
-				StateName:    stateName,
-				NodeName:     stateName,
-				EntryActions: sg.extractEntryActionDescriptions(subStateInfo),
-				ExitActions:  sg.extractExitActionDescriptions(subStateInfo),
-				StateInfo:    subStateInfo,
+				StateName:         stateName,
+				NodeName:          stateName,
+				EntryActions:      sg.extractEntryActionDescriptions(subStateInfo),
+				ExitActions:       sg.extractExitActionDescriptions(subStateInfo),
+				ActivateActions:   sg.extractActionDescriptions(subStateInfo.ActivateActions),
+				DeactivateActions: sg.extractActionDescriptions(subStateInfo.DeactivateActions),
+				StateInfo:         subStateInfo,
 			}
 			sg.States[stateName] = sub
 			superState.SubStates = append(superState.SubStates, sub)
@@ -108,11 +112,13 @@ func (sg *StateGraph) addSingleStates(machineInfo *stateless.StateMachineInfo) {
 		stateName := fmt.Sprintf("%v", stateInfo.UnderlyingState)
 		if _, exists := sg.States[stateName]; !exists {
 			sg.States[stateName] = &State{
-				StateName:    stateName,
-				NodeName:     stateName,
-				EntryActions: sg.extractEntryActionDescriptions(stateInfo),
-				ExitActions:  sg.extractExitActionDescriptions(stateInfo),
-				StateInfo:    stateInfo,
+				StateName:         stateName,
+				NodeName:          stateName,
+				EntryActions:      sg.extractEntryActionDescriptions(stateInfo),
+				ExitActions:       sg.extractExitActionDescriptions(stateInfo),
+				ActivateActions:   sg.extractActionDescriptions(stateInfo.ActivateActions),
+				DeactivateActions: sg.extractActionDescriptions(stateInfo.DeactivateActions),
+				StateInfo:         stateInfo,
 			}
 		}
 	}
@@ -138,6 +144,7 @@ func (sg *StateGraph) addTransitions(machineInfo *stateless.StateMachineInfo) {
 						DestinationState:        toState,
 						Guards:                  fix.GetGuardConditions(),
 						ExecuteEntryExitActions: !fix.GetIsInternalTransition(),
+						IsInternalTransition:    fix.GetIsInternalTransition(),
 					},
 				}
 				sg.Transitions = append(sg.Transitions, stay.Transition)
@@ -216,6 +223,7 @@ func (sg *StateGraph) addTransitions(machineInfo *stateless.StateMachineInfo) {
 					DestinationState:        fromState,
 					Guards:                  ignored.GetGuardConditions(),
 					ExecuteEntryExitActions: false,
+					IsIgnored:               true,
 				},
 			}
 			sg.Transitions = append(sg.Transitions, stay.Transition)
@@ -268,6 +276,15 @@ func (sg *StateGraph) extractExitActionDescriptions(stateInfo *stateless.StateIn
 	return descriptions
 }
 
+// extractActionDescriptions extracts the descriptions of activation or deactivation actions.
+func (sg *StateGraph) extractActionDescriptions(actions []stateless.InvocationInfo) []string {
+	var descriptions []string
+	for _, action := range actions {
+		descriptions = append(descriptions, action.Description())
+	}
+	return descriptions
+}
+
 // ToGraph converts the state graph to a string representation using the specified style.
 func (sg *StateGraph) ToGraph(style Style) string {
 	var sb strings.Builder
@@ -400,3 +417,16 @@ func (sg *StateGraph) isDecision(state *State) bool {
 	}
 	return false
 }
+
+// stateTag returns the value of the tag key of a state, if key is not empty and the state has it.
+func isFinalState(state *State) bool {
+	return state != nil && state.StateInfo != nil && state.StateInfo.IsFinal
+}
+
+func stateTag(state *State, key string) (string, bool) {
+	if key == "" || state == nil || state.StateInfo == nil {
+		return "", false
+	}
+	value, ok := state.StateInfo.Tags[key]
+	return value, ok
+}
@@ -38,10 +38,16 @@ type Style interface {
 // FormatTransitions is a helper that formats all transitions using the given style.
 // This eliminates duplicate logic between different style implementations.
 func FormatTransitions(style Style, transitions []*Transition) []string {
+	return formatLabeledTransitions(style, transitions, triggerLabel)
+}
+
+// formatLabeledTransitions formats all transitions using the given style, labelling each with the
+// trigger text returned by label.
+func formatLabeledTransitions(style Style, transitions []*Transition, label func(*Transition) string) []string {
 	var lines []string
 
 	for _, transit := range transitions {
-		line := formatSingleTransition(style, transit)
+		line := formatSingleTransition(style, transit, label(transit))
 		if line != "" {
 			lines = append(lines, line)
 		}
@@ -50,17 +56,22 @@ func FormatTransitions(style Style, transitions []*Transition) []string {
 	return lines
 }
 
-func formatSingleTransition(style Style, transit *Transition) string {
+// triggerLabel returns the trigger of a transition as text.
+func triggerLabel(transit *Transition) string {
+	return fmt.Sprintf("%v", transit.Trigger.UnderlyingTrigger)
+}
+
+func formatSingleTransition(style Style, transit *Transition, trigger string) string {
 	// Determine if this is a stay transition
 	if transit.SourceState == transit.DestinationState {
-		return formatStayTransition(style, transit)
+		return formatStayTransition(style, transit, trigger)
 	} else if transit.DestinationState != nil {
-		return formatRegularTransition(style, transit)
+		return formatRegularTransition(style, transit, trigger)
 	}
 	return ""
 }
 
-func formatStayTransition(style Style, transit *Transition) string {
+func formatStayTransition(style Style, transit *Transition, trigger string) string {
 	var actions []string
 	if transit.ExecuteEntryExitActions {
 		for _, act := range transit.DestinationEntryActions {
@@ -76,14 +87,14 @@ func formatStayTransition(style Style, transit *Transition) string {
 
 	return style.FormatOneTransition(
 		transit.SourceState.NodeName,
-		fmt.Sprintf("%v", transit.Trigger.UnderlyingTrigger),
+		trigger,
 		actions,
 		transit.SourceState.NodeName,
 		guards,
 	)
 }
 
-func formatRegularTransition(style Style, transit *Transition) string {
+func formatRegularTransition(style Style, transit *Transition, trigger string) string {
 	var actions []string
 	for _, act := range transit.DestinationEntryActions {
 		actions = append(actions, act.Description())
@@ -93,7 +104,7 @@ func formatRegularTransition(style Style, transit *Transition) string {
 
 	return style.FormatOneTransition(
 		transit.SourceState.NodeName,
-		fmt.Sprintf("%v", transit.Trigger.UnderlyingTrigger),
+		trigger,
 		actions,
 		transit.DestinationState.NodeName,
 		guards,
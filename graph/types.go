@@ -19,6 +19,12 @@ type State struct {
 	// ExitActions are the exit actions for this state.
 	ExitActions []string
 
+	// ActivateActions are the activation actions for this state.
+	ActivateActions []string
+
+	// DeactivateActions are the deactivation actions for this state.
+	DeactivateActions []string
+
 	// Leaving are the transitions leaving this state.
 	Leaving []*Transition
 
@@ -74,6 +80,13 @@ type Transition struct {
 
 	// ExecuteEntryExitActions indicates if entry/exit actions should be executed.
 	ExecuteEntryExitActions bool
+
+	// IsInternalTransition indicates an internal transition, which runs an action without
+	// leaving the state.
+	IsInternalTransition bool
+
+	// IsIgnored indicates a trigger that is ignored in the state.
+	IsIgnored bool
 }
 
 // StayTransition represents a transition from a state to itself.
@@ -2,13 +2,27 @@ package graph
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/atlekbai/stateless"
 )
 
 // UmlDotGraphStyle generates DOT graphs in basic UML style.
-type UmlDotGraphStyle struct{}
+type UmlDotGraphStyle struct {
+	// ColorTag names the state tag (see StateNode.WithTag) whose value is used as the fill color
+	// of the state, for example "color" with states tagged "lightblue". Empty disables coloring.
+	ColorTag string
+
+	// ShowLegend appends DotLegend to the graph.
+	ShowLegend bool
+
+	// finalStates are the names of the final states formatted so far, drawn with the initial transition.
+	finalStates []string
+
+	// nodePrefix is prepended to node identifiers, keeping the nodes of combined graphs apart.
+	nodePrefix string
+}
 
 // NewUmlDotGraphStyle creates a new UML DOT graph style.
 func NewUmlDotGraphStyle() *UmlDotGraphStyle {
@@ -18,6 +32,7 @@ func NewUmlDotGraphStyle() *UmlDotGraphStyle {
 // GetPrefix returns the text that starts a new DOT graph.
 func (s *UmlDotGraphStyle) GetPrefix() string {
 	var sb strings.Builder
+	s.finalStates = nil
 	sb.WriteString("digraph {\n")
 	sb.WriteString("compound=true;\n")
 	sb.WriteString("node [shape=Mrecord]\n")
@@ -32,22 +47,22 @@ func (s *UmlDotGraphStyle) FormatOneCluster(superState *SuperState) string {
 
 	label.WriteString(EscapeLabel(superState.StateName))
 
-	if len(superState.EntryActions) > 0 || len(superState.ExitActions) > 0 {
+	if actions := stateActionLines(superState.State); len(actions) > 0 {
 		label.WriteString("\\n----------")
-		for _, act := range superState.EntryActions {
-			label.WriteString("\\nentry / ")
-			label.WriteString(EscapeLabel(act))
-		}
-		for _, act := range superState.ExitActions {
-			label.WriteString("\\nexit / ")
-			label.WriteString(EscapeLabel(act))
+		for _, act := range actions {
+			label.WriteString("\\n")
+			label.WriteString(act)
 		}
 	}
 
+	s.addFinalState(superState.State)
 	sb.WriteString("\n")
-	sb.WriteString(fmt.Sprintf("subgraph \"cluster%s\"\n", EscapeLabel(superState.NodeName)))
+	sb.WriteString(fmt.Sprintf("subgraph \"cluster%s\"\n", s.nodeID(superState.NodeName)))
 	sb.WriteString("\t{\n")
 	sb.WriteString(fmt.Sprintf("\tlabel = \"%s\"\n", label.String()))
+	if color, ok := stateTag(superState.State, s.ColorTag); ok {
+		sb.WriteString(fmt.Sprintf("\tstyle = filled\n\tfillcolor = \"%s\"\n", EscapeLabel(color)))
+	}
 
 	for _, subState := range superState.SubStates {
 		sb.WriteString(s.FormatOneState(subState))
@@ -59,33 +74,58 @@ func (s *UmlDotGraphStyle) FormatOneCluster(superState *SuperState) string {
 
 // FormatOneState formats a single state.
 func (s *UmlDotGraphStyle) FormatOneState(state *State) string {
+	s.addFinalState(state)
+	id := s.nodeID(state.StateName)
 	escapedName := EscapeLabel(state.StateName)
 
-	if len(state.EntryActions) == 0 && len(state.ExitActions) == 0 {
-		return fmt.Sprintf("\"%s\" [label=\"%s\"];\n", escapedName, escapedName)
+	var fill string
+	if color, ok := stateTag(state, s.ColorTag); ok {
+		fill = fmt.Sprintf(", style=filled, fillcolor=\"%s\"", EscapeLabel(color))
+	}
+
+	actions := stateActionLines(state)
+	if len(actions) == 0 {
+		return fmt.Sprintf("\"%s\" [label=\"%s\"%s];\n", id, escapedName, fill)
 	}
 
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("\"%s\" [label=\"%s|", escapedName, escapedName))
+	sb.WriteString(fmt.Sprintf("\"%s\" [label=\"%s|", id, escapedName))
+	sb.WriteString(strings.Join(actions, "\\n"))
+	sb.WriteString("\"" + fill + "];\n")
 
+	return sb.String()
+}
+
+// addFinalState records state if it is final.
+func (s *UmlDotGraphStyle) addFinalState(state *State) {
+	if isFinalState(state) && !slices.Contains(s.finalStates, state.StateName) {
+		s.finalStates = append(s.finalStates, state.StateName)
+	}
+}
+
+// stateActionLines returns the escaped action lines shown in a state box:
+// activation, entry, exit and deactivation actions, in that order.
+func stateActionLines(state *State) []string {
 	var actions []string
+	for _, act := range state.ActivateActions {
+		actions = append(actions, "activate / "+EscapeLabel(act))
+	}
 	for _, act := range state.EntryActions {
 		actions = append(actions, "entry / "+EscapeLabel(act))
 	}
 	for _, act := range state.ExitActions {
 		actions = append(actions, "exit / "+EscapeLabel(act))
 	}
-
-	sb.WriteString(strings.Join(actions, "\\n"))
-	sb.WriteString("\"];\n")
-
-	return sb.String()
+	for _, act := range state.DeactivateActions {
+		actions = append(actions, "deactivate / "+EscapeLabel(act))
+	}
+	return actions
 }
 
 // FormatOneDecisionNode formats a decision node.
 func (s *UmlDotGraphStyle) FormatOneDecisionNode(nodeName, label string) string {
 	return fmt.Sprintf("\"%s\" [shape = \"diamond\", label = \"%s\"];\n",
-		EscapeLabel(nodeName), EscapeLabel(label))
+		s.nodeID(nodeName), EscapeLabel(label))
 }
 
 // FormatAllTransitions formats all transitions.
@@ -123,28 +163,60 @@ func (s *UmlDotGraphStyle) FormatOneTransition(
 		}
 	}
 
-	return formatOneLine(sourceNodeName, destinationNodeName, sb.String())
+	return formatOneLine(s.nodePrefix+sourceNodeName, s.nodePrefix+destinationNodeName, sb.String())
 }
 
-// GetInitialTransition returns the text for the initial state transition.
+// GetInitialTransition returns the text for the initial state transition, followed by the
+// transitions of final states to a UML final state, and closes the graph.
 func (s *UmlDotGraphStyle) GetInitialTransition(initialState *stateless.StateInfo) string {
-	if initialState == nil {
-		return "\n}"
+	var sb strings.Builder
+	if s.ShowLegend {
+		sb.WriteString(DotLegend())
 	}
 
-	initialStateName := fmt.Sprintf("%v", initialState.UnderlyingState)
+	if initialState != nil {
+		initialStateName := fmt.Sprintf("%v", initialState.UnderlyingState)
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf(" %s [label=\"\", shape=point];", s.initID()))
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf(" %s -> \"%s\"[style = \"solid\"]", s.initID(), s.nodeID(initialStateName)))
+	}
+
+	if len(s.finalStates) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf(" %s [label=\"\", shape=point, peripheries=2];", s.finalID()))
+		for _, name := range s.finalStates {
+			sb.WriteString("\n")
+			sb.WriteString(fmt.Sprintf(" \"%s\" -> %s[style = \"solid\"]", s.nodeID(name), s.finalID()))
+		}
+	}
 
-	var sb strings.Builder
-	sb.WriteString("\n")
-	sb.WriteString(" init [label=\"\", shape=point];")
-	sb.WriteString("\n")
-	sb.WriteString(fmt.Sprintf(" init -> \"%s\"[style = \"solid\"]", EscapeLabel(initialStateName)))
 	sb.WriteString("\n")
 	sb.WriteString("}")
-
 	return sb.String()
 }
 
+// nodeID returns the escaped DOT identifier of the node with the given name.
+func (s *UmlDotGraphStyle) nodeID(name string) string {
+	return EscapeLabel(s.nodePrefix + name)
+}
+
+// initID returns the DOT identifier of the initial pseudo-state node.
+func (s *UmlDotGraphStyle) initID() string {
+	if s.nodePrefix == "" {
+		return "init"
+	}
+	return "\"" + s.nodeID("init") + "\""
+}
+
+// finalID returns the DOT identifier of the final state node.
+func (s *UmlDotGraphStyle) finalID() string {
+	if s.nodePrefix == "" {
+		return "final"
+	}
+	return "\"" + s.nodeID("final") + "\""
+}
+
 // formatOneLine formats a single transition line.
 func formatOneLine(fromNodeName, toNodeName, label string) string {
 	return fmt.Sprintf("\"%s\" -> \"%s\" [style=\"solid\", label=\"%s\"];",
@@ -63,12 +63,17 @@ var EmptyTransitionGuard = TransitionGuard{Conditions: []GuardCondition{}}
 // NewTransitionGuard creates a new transition guard from a guard function.
 // The guard returns nil if the condition is met, or an error describing why it failed.
 func NewTransitionGuard(guard GuardFunc) TransitionGuard {
+	return newDescribedTransitionGuard(guard, "")
+}
+
+// newDescribedTransitionGuard creates a new transition guard whose condition carries the given description.
+func newDescribedTransitionGuard(guard GuardFunc, description string) TransitionGuard {
 	if guard == nil {
 		return EmptyTransitionGuard
 	}
 	return TransitionGuard{
 		Conditions: []GuardCondition{
-			NewGuardCondition(guard, CreateInvocationInfo(guard, "")),
+			NewGuardCondition(guard, CreateInvocationInfo(guard, description)),
 		},
 	}
 }
@@ -85,16 +90,48 @@ func (tg TransitionGuard) Guards() []GuardFunc {
 // GuardConditionsMet evaluates all guard conditions and returns an error if any fail.
 // Returns nil if all guard conditions are met.
 // If multiple conditions fail, returns all errors joined together.
+// Each evaluation is reported to the OnGuardEvaluated handlers of the firing state machine, if any.
 func (tg TransitionGuard) GuardConditionsMet(ctx context.Context, args any) error {
+	observe := guardObserverFrom(ctx)
 	var errs []error
 	for _, c := range tg.Conditions {
-		if err := c.Evaluate(ctx, args); err != nil {
+		err := c.Evaluate(ctx, args)
+		if observe != nil {
+			observe(c.Description(), err == nil, err)
+		}
+		if err != nil {
 			errs = append(errs, err)
 		}
 	}
 	return errors.Join(errs...)
 }
 
+// guardObserverKey carries the guard observer of the trigger being fired in a context.
+type guardObserverKey struct{}
+
+// guardObserver is told about each evaluated guard condition.
+type guardObserver func(description string, passed bool, err error)
+
+// withGuardObserver returns a context whose guard evaluations are reported to observe.
+func withGuardObserver(ctx context.Context, observe guardObserver) context.Context {
+	return context.WithValue(ctx, guardObserverKey{}, observe)
+}
+
+// withoutGuardObserver returns a context whose guard evaluations are not reported, for guards
+// evaluated only to describe the machine, such as when listing permitted triggers.
+func withoutGuardObserver(ctx context.Context) context.Context {
+	if guardObserverFrom(ctx) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, guardObserverKey{}, guardObserver(nil))
+}
+
+// guardObserverFrom returns the guard observer carried by ctx, or nil.
+func guardObserverFrom(ctx context.Context) guardObserver {
+	observe, _ := ctx.Value(guardObserverKey{}).(guardObserver)
+	return observe
+}
+
 // IsEmpty returns true if the transition guard has no conditions.
 func (tg TransitionGuard) IsEmpty() bool {
 	return len(tg.Conditions) == 0
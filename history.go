@@ -0,0 +1,52 @@
+package stateless
+
+import "context"
+
+// EnableHistory makes the state machine remember up to depth of the states it most recently left,
+// so that Back can return to them one after another. When the history is full, the oldest state is
+// forgotten. States are recorded as described for PreviousState, by fired transitions and GoTo but
+// not by Back. A depth of zero or less disables the history and clears it; changing the depth keeps
+// the most recent states that fit.
+func (sm *StateMachine[TState, TTrigger]) EnableHistory(depth int) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	sm.historyDepth = max(depth, 0)
+	if len(sm.history) > sm.historyDepth {
+		sm.history = sm.history[len(sm.history)-sm.historyDepth:]
+	}
+}
+
+// HistoryLength returns the number of states Back can currently return to.
+func (sm *StateMachine[TState, TTrigger]) HistoryLength() int {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+	return len(sm.history)
+}
+
+// Back moves the state machine into the state it most recently left and removes that state from
+// the history, running exit and entry actions as GoTo does. Back itself is not recorded in the
+// history, so calling it repeatedly walks further back; PreviousState is updated as for any other
+// change of state. If the state cannot be left because an exit action fails, the history is kept.
+// Back returns an InvalidOperationError when the history is empty or was not enabled. It is
+// serialized with fires and rejected after Shutdown or in replay mode as GoTo is.
+func (sm *StateMachine[TState, TTrigger]) Back(ctx context.Context) error {
+	return sm.runForced(ctx, "Back", func(ctx context.Context) error {
+		sm.mutex.Lock()
+		if len(sm.history) == 0 {
+			sm.mutex.Unlock()
+			return &InvalidOperationError{Message: "there is no state in the history to go back to"}
+		}
+		state := sm.history[len(sm.history)-1]
+		sm.history = sm.history[:len(sm.history)-1]
+		sm.mutex.Unlock()
+
+		source := sm.State()
+		err := sm.goTo(ctx, state, false)
+		if err != nil && sm.State() == source {
+			sm.mutex.Lock()
+			sm.history = append(sm.history, state)
+			sm.mutex.Unlock()
+		}
+		return err
+	})
+}
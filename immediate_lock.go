@@ -0,0 +1,65 @@
+package stateless
+
+import (
+	"runtime"
+	"strings"
+)
+
+// immediateFrameSuffix ends the name of runImmediate in stack traces.
+const immediateFrameSuffix = ").runImmediate"
+
+// runImmediate runs fn serialized with the other fires in FiringImmediate mode: it waits while a
+// fire is in progress on another goroutine and holds the lock until fn returns, unless the machine
+// is shutting down, in which case ErrShuttingDown is returned without running fn.
+//
+// A trigger fired from an action cannot wait for the fire that runs the action, since that fire only
+// completes after the action. It is run nested in that fire instead, as it was before fires were
+// serialized, also when it is not fired with the context passed to the action. Such a trigger is
+// recognized by runImmediate being on the stack of its goroutine, which is only looked up when the
+// lock is already held.
+func (sm *StateMachine[TState, TTrigger]) runImmediate(fn func() error) error {
+	if !sm.immediateMutex.TryLock() {
+		if firingOnGoroutine() {
+			return fn()
+		}
+		sm.immediateMutex.Lock()
+	}
+	defer sm.immediateMutex.Unlock()
+
+	if sm.isShuttingDown() {
+		return ErrShuttingDown
+	}
+	return fn()
+}
+
+// firingOnGoroutine reports whether the calling runImmediate is nested in another one on the same
+// goroutine, that is whether the goroutine is running an action of a fire in FiringImmediate mode.
+func firingOnGoroutine() bool {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	for n == len(pcs) {
+		pcs = make([]uintptr, 2*len(pcs))
+		n = runtime.Callers(2, pcs)
+	}
+
+	// The first runImmediate frame is the caller's; only others of the same instantiation count
+	var (
+		caller uintptr
+		found  bool
+	)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if strings.HasSuffix(frame.Function, immediateFrameSuffix) {
+			if found && frame.Entry == caller {
+				return true
+			}
+			if !found {
+				caller, found = frame.Entry, true
+			}
+		}
+		if !more {
+			return false
+		}
+	}
+}
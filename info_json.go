@@ -0,0 +1,177 @@
+package stateless
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// stateMachineJSON is the JSON schema of a StateMachineInfo.
+type stateMachineJSON struct {
+	StateType    string      `json:"stateType"`
+	TriggerType  string      `json:"triggerType"`
+	InitialState string      `json:"initialState,omitempty"`
+	States       []stateJSON `json:"states"`
+}
+
+// stateJSON is the JSON schema of a StateInfo. States are referenced by name.
+type stateJSON struct {
+	Name               string                  `json:"name"`
+	Superstate         string                  `json:"superstate,omitempty"`
+	Substates          []string                `json:"substates"`
+	EntryActions       []string                `json:"entryActions"`
+	ExitActions        []string                `json:"exitActions"`
+	Transitions        []transitionJSON        `json:"transitions"`
+	DynamicTransitions []dynamicTransitionJSON `json:"dynamicTransitions"`
+	IgnoredTriggers    []ignoredTriggerJSON    `json:"ignoredTriggers"`
+	Tags               map[string]string       `json:"tags,omitempty"`
+	IsFinal            bool                    `json:"isFinal,omitempty"`
+}
+
+// transitionJSON is the JSON schema of a FixedTransitionInfo.
+type transitionJSON struct {
+	Trigger     string   `json:"trigger"`
+	Destination string   `json:"destination"`
+	Guards      []string `json:"guards"`
+	IsInternal  bool     `json:"isInternal"`
+}
+
+// dynamicTransitionJSON is the JSON schema of a DynamicTransitionInfo.
+type dynamicTransitionJSON struct {
+	Trigger              string                   `json:"trigger"`
+	Selector             string                   `json:"selector"`
+	Guards               []string                 `json:"guards"`
+	PossibleDestinations []dynamicDestinationJSON `json:"possibleDestinations"`
+}
+
+// dynamicDestinationJSON is the JSON schema of a DynamicStateInfo.
+type dynamicDestinationJSON struct {
+	Destination string `json:"destination"`
+	Criterion   string `json:"criterion,omitempty"`
+}
+
+// ignoredTriggerJSON is the JSON schema of an IgnoredTransitionInfo.
+type ignoredTriggerJSON struct {
+	Trigger string   `json:"trigger"`
+	Guards  []string `json:"guards"`
+}
+
+// MarshalJSON encodes the machine topology with a stable schema for external tooling:
+// states are referenced by their formatted names, guards and actions by their descriptions,
+// and states, substates and transitions are listed in a deterministic order.
+func (info *StateMachineInfo) MarshalJSON() ([]byte, error) {
+	out := stateMachineJSON{
+		StateType:   info.StateType,
+		TriggerType: info.TriggerType,
+		States:      make([]stateJSON, 0, len(info.States)),
+	}
+	if info.InitialState != nil {
+		out.InitialState = formatValue(info.InitialState.UnderlyingState)
+	}
+
+	for _, state := range sortedStateInfos(info.States) {
+		out.States = append(out.States, newStateJSON(state))
+	}
+
+	return json.Marshal(out)
+}
+
+// newStateJSON converts a StateInfo to its JSON schema.
+func newStateJSON(state *StateInfo) stateJSON {
+	out := stateJSON{
+		Name:               formatValue(state.UnderlyingState),
+		Substates:          []string{},
+		EntryActions:       make([]string, 0, len(state.EntryActions)),
+		ExitActions:        make([]string, 0, len(state.ExitActions)),
+		Transitions:        make([]transitionJSON, 0, len(state.FixedTransitions)),
+		DynamicTransitions: make([]dynamicTransitionJSON, 0, len(state.DynamicTransitions)),
+		IgnoredTriggers:    make([]ignoredTriggerJSON, 0, len(state.IgnoredTriggers)),
+		Tags:               state.Tags,
+		IsFinal:            state.IsFinal,
+	}
+	if state.Superstate != nil {
+		out.Superstate = formatValue(state.Superstate.UnderlyingState)
+	}
+	for _, sub := range sortedStateInfos(state.Substates) {
+		out.Substates = append(out.Substates, formatValue(sub.UnderlyingState))
+	}
+	for _, act := range state.EntryActions {
+		out.EntryActions = append(out.EntryActions, act.Description())
+	}
+	for _, act := range state.ExitActions {
+		out.ExitActions = append(out.ExitActions, act.Description())
+	}
+
+	for _, tr := range sortedByTrigger(state.FixedTransitions) {
+		destination := ""
+		if tr.DestinationState != nil {
+			destination = formatValue(tr.DestinationState.UnderlyingState)
+		}
+		out.Transitions = append(out.Transitions, transitionJSON{
+			Trigger:     formatValue(tr.Trigger.UnderlyingTrigger),
+			Destination: destination,
+			Guards:      guardDescriptions(tr.GuardConditions),
+			IsInternal:  tr.IsInternalTransition,
+		})
+	}
+
+	for _, tr := range sortedByTrigger(state.DynamicTransitions) {
+		destinations := make([]dynamicDestinationJSON, 0, len(tr.PossibleDestinationStates))
+		for _, dst := range tr.PossibleDestinationStates {
+			destinations = append(destinations, dynamicDestinationJSON{
+				Destination: dst.DestinationState,
+				Criterion:   dst.Criterion,
+			})
+		}
+		out.DynamicTransitions = append(out.DynamicTransitions, dynamicTransitionJSON{
+			Trigger:              formatValue(tr.Trigger.UnderlyingTrigger),
+			Selector:             tr.DestinationStateSelectorDescription.Description(),
+			Guards:               guardDescriptions(tr.GuardConditions),
+			PossibleDestinations: destinations,
+		})
+	}
+
+	for _, tr := range sortedByTrigger(state.IgnoredTriggers) {
+		out.IgnoredTriggers = append(out.IgnoredTriggers, ignoredTriggerJSON{
+			Trigger: formatValue(tr.Trigger.UnderlyingTrigger),
+			Guards:  guardDescriptions(tr.GuardConditions),
+		})
+	}
+
+	return out
+}
+
+// formatValue formats a state or trigger value as a name.
+func formatValue(v any) string {
+	return fmt.Sprintf("%v", v)
+}
+
+// guardDescriptions returns the descriptions of guard conditions.
+func guardDescriptions(guards []InvocationInfo) []string {
+	descriptions := make([]string, 0, len(guards))
+	for _, guard := range guards {
+		descriptions = append(descriptions, guard.Description())
+	}
+	return descriptions
+}
+
+// sortedStateInfos returns a copy of the states ordered by their underlying state.
+func sortedStateInfos(states []*StateInfo) []*StateInfo {
+	sorted := slices.Clone(states)
+	slices.SortStableFunc(sorted, func(a, b *StateInfo) int {
+		return compareValues(a.UnderlyingState, b.UnderlyingState)
+	})
+	return sorted
+}
+
+// sortedByTrigger returns a copy of the transitions ordered by trigger.
+func sortedByTrigger[T any, PT interface {
+	*T
+	GetTrigger() TriggerInfo
+}](transitions []T) []T {
+	sorted := slices.Clone(transitions)
+	slices.SortStableFunc(sorted, func(a, b T) int {
+		return compareValues(PT(&a).GetTrigger().UnderlyingTrigger, PT(&b).GetTrigger().UnderlyingTrigger)
+	})
+	return sorted
+}
@@ -0,0 +1,32 @@
+package stateless
+
+import (
+	"context"
+	"maps"
+)
+
+// Link makes to react to the transitions of from: whenever from transitions because of a trigger
+// that is a key of mapping, the mapped trigger is fired on to, with the same args and context.
+// A mapped trigger that to does not handle is ignored, without invoking OnUnhandledTrigger;
+// other errors are discarded, although OnError handlers of to still see failing actions.
+// Transitions made by GoTo are not forwarded.
+//
+// Machines may be linked both ways. Each machine serializes its own triggers, so with FiringQueued
+// a trigger sent back to a machine that is still firing is queued instead of deadlocking. With
+// FiringImmediate it is handled right away, nested in the fire of the transition that caused it,
+// and cycles are bounded by SetMaxImmediateDepth.
+func Link[TState, TTrigger comparable](
+	from *StateMachine[TState, TTrigger],
+	to *StateMachine[TState, TTrigger],
+	mapping map[TTrigger]TTrigger,
+) {
+	mapping = maps.Clone(mapping)
+	from.onTransitionedEvent.RegisterCtx(func(ctx context.Context, t Transition[TState, TTrigger]) {
+		if t.Kind == TransitionForced {
+			return
+		}
+		if mapped, ok := mapping[t.Trigger]; ok {
+			_ = to.FireCtx(withIgnoreUnhandled(ctx), mapped, t.Args)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package stateless_test
+
+import (
+	"testing"
+
+	"github.com/atlekbai/stateless"
+)
+
+func TestLink_PingPong(t *testing.T) {
+	// StateA idle, StateB waiting for the pong, StateC done; TriggerX is ping, TriggerY is pong
+	pinger := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued)
+	pinger.Configure(StateA).Permit(TriggerX, StateB)
+	pinger.Configure(StateB).Permit(TriggerY, StateC)
+	pinger.Configure(StateC)
+
+	// StateA idle, StateB answered
+	ponger := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued)
+	ponger.Configure(StateA).Permit(TriggerX, StateB)
+	ponger.Configure(StateB)
+
+	stateless.Link(pinger, ponger, map[Trigger]Trigger{TriggerX: TriggerX})
+	stateless.Link(ponger, pinger, map[Trigger]Trigger{TriggerX: TriggerY})
+
+	if err := pinger.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if ponger.State() != StateB {
+		t.Errorf("expected ponger in StateB, got %v", ponger.State())
+	}
+	if pinger.State() != StateC {
+		t.Errorf("expected pinger in StateC, got %v", pinger.State())
+	}
+}
+
+func TestLink_PingPongImmediate(t *testing.T) {
+	pinger := stateless.NewStateMachine[State, Trigger](StateA)
+	pinger.Configure(StateA).Permit(TriggerX, StateB)
+	pinger.Configure(StateB).Permit(TriggerY, StateC)
+	pinger.Configure(StateC)
+
+	ponger := stateless.NewStateMachine[State, Trigger](StateA)
+	ponger.Configure(StateA).Permit(TriggerX, StateB)
+	ponger.Configure(StateB)
+
+	stateless.Link(pinger, ponger, map[Trigger]Trigger{TriggerX: TriggerX})
+	stateless.Link(ponger, pinger, map[Trigger]Trigger{TriggerX: TriggerY})
+
+	if err := pinger.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if ponger.State() != StateB {
+		t.Errorf("expected ponger in StateB, got %v", ponger.State())
+	}
+	if pinger.State() != StateC {
+		t.Errorf("expected pinger in StateC, got %v", pinger.State())
+	}
+}
+
+func TestLink_ImmediateCycleBoundedByMaxDepth(t *testing.T) {
+	first := stateless.NewStateMachine[State, Trigger](StateA)
+	second := stateless.NewStateMachine[State, Trigger](StateA)
+	transitions := 0
+	for _, sm := range []*stateless.StateMachine[State, Trigger]{first, second} {
+		sm.Configure(StateA).Permit(TriggerX, StateB)
+		sm.Configure(StateB).Permit(TriggerX, StateA)
+		sm.OnTransitioned(func(_ stateless.Transition[State, Trigger]) {
+			transitions++
+		})
+		sm.SetMaxImmediateDepth(5)
+	}
+
+	stateless.Link(first, second, map[Trigger]Trigger{TriggerX: TriggerX})
+	stateless.Link(second, first, map[Trigger]Trigger{TriggerX: TriggerX})
+
+	if err := first.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if transitions != 10 {
+		t.Errorf("expected the cycle to stop after 10 transitions, got %d", transitions)
+	}
+}
+
+func TestLink_IgnoresUnhandled(t *testing.T) {
+	from := stateless.NewStateMachine[State, Trigger](StateA)
+	from.Configure(StateA).Permit(TriggerX, StateB)
+	from.Configure(StateB).Permit(TriggerY, StateA)
+
+	unhandled := 0
+	to := stateless.NewStateMachine[State, Trigger](StateA)
+	to.Configure(StateA).Permit(TriggerZ, StateB)
+	to.OnUnhandledTrigger(func(_ State, _ Trigger, _ []error) {
+		unhandled++
+	})
+
+	stateless.Link(from, to, map[Trigger]Trigger{TriggerX: TriggerY, TriggerY: TriggerZ})
+
+	if err := from.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if to.State() != StateA || unhandled != 0 {
+		t.Errorf("expected unhandled linked trigger to be ignored, got state %v and %d unhandled calls",
+			to.State(), unhandled)
+	}
+
+	if err := from.Fire(TriggerY, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if to.State() != StateB {
+		t.Errorf("expected linked trigger to move to StateB, got %v", to.State())
+	}
+}
@@ -0,0 +1,64 @@
+package stateless
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// WithLogger makes the state machine log to logger: each handled trigger that is not ignored at
+// Info with its source, destination, trigger and kind, each guard condition that is not met at
+// Debug, and each action error at Error, before it is passed to the OnError handler. Records are
+// logged with the context the trigger was fired with, so handlers can pick up values such as trace
+// IDs from it. Passing nil turns logging off.
+func (sm *StateMachine[TState, TTrigger]) WithLogger(logger *slog.Logger) {
+	sm.logger = logger
+}
+
+// logTransition logs a handled trigger.
+func (sm *StateMachine[TState, TTrigger]) logTransition(ctx context.Context, transition Transition[TState, TTrigger]) {
+	if sm.logger == nil || transition.Kind == TransitionIgnored {
+		return
+	}
+	sm.logger.LogAttrs(ctx, slog.LevelInfo, "state machine transition",
+		slog.String("source", fmt.Sprint(transition.Source)),
+		slog.String("destination", fmt.Sprint(transition.Destination)),
+		slog.String("trigger", fmt.Sprint(transition.Trigger)),
+		slog.String("kind", transition.Kind.String()),
+	)
+}
+
+// logGuardRejection logs a guard condition that was not met.
+func (sm *StateMachine[TState, TTrigger]) logGuardRejection(
+	ctx context.Context,
+	state TState,
+	trigger TTrigger,
+	description string,
+	err error,
+) {
+	sm.logger.LogAttrs(ctx, slog.LevelDebug, "state machine guard rejected",
+		slog.String("state", fmt.Sprint(state)),
+		slog.String("trigger", fmt.Sprint(trigger)),
+		slog.String("guard", description),
+		slog.Any("reason", err),
+	)
+}
+
+// logActionError logs an error returned by an action.
+func (sm *StateMachine[TState, TTrigger]) logActionError(
+	ctx context.Context,
+	transition Transition[TState, TTrigger],
+	phase ActionPhase,
+	err error,
+) {
+	if sm.logger == nil {
+		return
+	}
+	sm.logger.LogAttrs(ctx, slog.LevelError, "state machine action failed",
+		slog.String("source", fmt.Sprint(transition.Source)),
+		slog.String("destination", fmt.Sprint(transition.Destination)),
+		slog.String("trigger", fmt.Sprint(transition.Trigger)),
+		slog.String("phase", phase.String()),
+		slog.Any("error", err),
+	)
+}
@@ -0,0 +1,50 @@
+package stateless_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/atlekbai/stateless"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.WithLogger(logger)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB).
+		PermitIf(TriggerY, StateC, func(ctx context.Context, args any) error {
+			return stateless.Reject("closed")
+		}, "is open")
+	sm.Configure(StateB).OnExit(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+		return errors.New("boom")
+	}).Permit(TriggerX, StateA)
+
+	_ = sm.Fire(TriggerY, nil)
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = sm.Fire(TriggerX, nil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := []string{
+		`level=DEBUG msg="state machine guard rejected" state=StateA trigger=TriggerY guard="is open" reason=closed`,
+		`level=INFO msg="state machine transition" source=StateA destination=StateB trigger=TriggerX kind=External`,
+		`level=ERROR msg="state machine action failed" source=StateB destination=StateA trigger=TriggerX phase=Exit ` +
+			`error=boom`,
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d log lines, got %d:\n%s", len(expected), len(lines), buf.String())
+	}
+	for i, line := range lines {
+		if !strings.HasSuffix(line, expected[i]) {
+			t.Errorf("expected line %d to end with %q, got %q", i, expected[i], line)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package stateless
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// compareValues orders two state or trigger values. Integers, floats and strings are compared
+// by value; any other type is compared by its formatted representation.
+func compareValues(a, b any) int {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Kind() == vb.Kind() {
+		switch va.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return cmp.Compare(va.Int(), vb.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return cmp.Compare(va.Uint(), vb.Uint())
+		case reflect.Float32, reflect.Float64:
+			return cmp.Compare(va.Float(), vb.Float())
+		case reflect.String:
+			return cmp.Compare(va.String(), vb.String())
+		default:
+		}
+	}
+	if c := cmp.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b)); c != 0 {
+		return c
+	}
+	return cmp.Compare(fmt.Sprintf("%#v", a), fmt.Sprintf("%#v", b))
+}
+
+// sortValues sorts states or triggers in place using compareValues.
+func sortValues[T comparable](values []T) {
+	slices.SortStableFunc(values, func(a, b T) int {
+		return compareValues(a, b)
+	})
+}
@@ -0,0 +1,239 @@
+package stateless
+
+import (
+	"fmt"
+	"slices"
+)
+
+// PathTo returns a shortest sequence of triggers that, fired one after another, leads the machine
+// from one state to another according to its configuration, and false if no sequence does.
+//
+// Guards, disabled triggers and actions are not considered, since they depend on runtime values,
+// so a path is only guaranteed to be possible if the guards along it are met. Transitions configured
+// on a superstate are available in its substates, and initial transitions are followed as firing
+// would, except into itself. Dynamic transitions lead to the PossibleDestinationStates they were
+// configured with, matched by formatted state name. Among paths of equal length, the one with the
+// lowest triggers is returned. The path from a state to itself is empty.
+func (sm *StateMachine[TState, TTrigger]) PathTo(from, to TState) ([]TTrigger, bool) {
+	representations := sm.representations()
+	statesByName := indexStatesByName(representations)
+
+	type step struct {
+		previous TState
+		trigger  TTrigger
+	}
+	steps := map[TState]step{}
+	visited := map[TState]struct{}{from: {}}
+	queue := []TState{from}
+	for len(queue) > 0 && !isVisited(visited, to) {
+		state := queue[0]
+		queue = queue[1:]
+		for _, edge := range pathEdges(representations, statesByName, state) {
+			destination := edge.Destination
+			if destination != to {
+				destination = settledState(representations, destination)
+			}
+			if isVisited(visited, destination) {
+				continue
+			}
+			visited[destination] = struct{}{}
+			steps[destination] = step{previous: state, trigger: edge.Trigger}
+			queue = append(queue, destination)
+		}
+	}
+	if !isVisited(visited, to) {
+		return nil, false
+	}
+
+	triggers := []TTrigger{}
+	for state := to; state != from; state = steps[state].previous {
+		triggers = append(triggers, steps[state].trigger)
+	}
+	for i, j := 0, len(triggers)-1; i < j; i, j = i+1, j-1 {
+		triggers[i], triggers[j] = triggers[j], triggers[i]
+	}
+	return triggers, true
+}
+
+// EnumeratePaths returns the sequences of transitions the machine can take from its initial state
+// according to its configuration, with at most maxDepth transitions each, for example to generate
+// tests exercising the state graph. Transitions are considered as by PathTo, and the destination of
+// each is the state the machine rests in after following initial transitions, which is the source of
+// the next one.
+//
+// A path ends after maxDepth transitions, in a state with no transitions, or with a transition back
+// to a state already on it, so cycles are taken once per path while states are revisited by other
+// paths. Only complete paths are returned, not their prefixes, ordered by their triggers and then by
+// their destinations. Nil is returned if maxDepth is not positive or the initial state has no
+// transitions.
+func (sm *StateMachine[TState, TTrigger]) EnumeratePaths(maxDepth int) [][]TransitionEdge[TState, TTrigger] {
+	if maxDepth <= 0 {
+		return nil
+	}
+	representations := sm.representations()
+	statesByName := indexStatesByName(representations)
+
+	var paths [][]TransitionEdge[TState, TTrigger]
+	var path []TransitionEdge[TState, TTrigger]
+	onPath := map[TState]struct{}{}
+	var visit func(state TState)
+	visit = func(state TState) {
+		edges := slices.Compact(pathEdges(representations, statesByName, state))
+		if len(path) == maxDepth || len(edges) == 0 {
+			if len(path) > 0 {
+				paths = append(paths, slices.Clone(path))
+			}
+			return
+		}
+
+		onPath[state] = struct{}{}
+		defer delete(onPath, state)
+		for _, edge := range edges {
+			edge.Destination = settledState(representations, edge.Destination)
+			path = append(path, edge)
+			if isVisited(onPath, edge.Destination) {
+				paths = append(paths, slices.Clone(path))
+			} else {
+				visit(edge.Destination)
+			}
+			path = path[:len(path)-1]
+		}
+	}
+	visit(sm.initialState)
+	return paths
+}
+
+// indexStatesByName indexes the configured states by formatted name, to resolve the possible destinations
+// of dynamic transitions.
+func indexStatesByName[TState, TTrigger comparable](
+	representations map[TState]*StateRepresentation[TState, TTrigger],
+) map[string]TState {
+	byName := make(map[string]TState, len(representations))
+	for state := range representations {
+		byName[fmt.Sprint(state)] = state
+	}
+	return byName
+}
+
+// isVisited reports whether state is in visited.
+func isVisited[TState comparable](visited map[TState]struct{}, state TState) bool {
+	_, ok := visited[state]
+	return ok
+}
+
+// pathEdges returns the transitions that change state when fired in state, including those
+// configured on its superstates, ordered by trigger and then by destination.
+func pathEdges[TState, TTrigger comparable](
+	representations map[TState]*StateRepresentation[TState, TTrigger],
+	statesByName map[string]TState,
+	state TState,
+) []TransitionEdge[TState, TTrigger] {
+	var edges []TransitionEdge[TState, TTrigger]
+	add := func(trigger TTrigger, destination TState) {
+		edges = append(edges, TransitionEdge[TState, TTrigger]{Source: state, Trigger: trigger, Destination: destination})
+	}
+	addPossible := func(trigger TTrigger, info DynamicTransitionInfo) {
+		for _, possible := range info.PossibleDestinationStates {
+			if destination, ok := statesByName[possible.DestinationState]; ok {
+				add(trigger, destination)
+			}
+		}
+	}
+
+	for rep := representations[state]; rep != nil; rep = rep.Superstate() {
+		for trigger, behaviours := range rep.TriggerBehaviours() {
+			for _, behaviour := range behaviours {
+				switch b := behaviour.(type) {
+				case *TransitioningTriggerBehaviour[TState, TTrigger]:
+					add(trigger, b.Destination)
+				case *ReentryTriggerBehaviour[TState, TTrigger]:
+					add(trigger, b.Destination)
+				case *DynamicTriggerBehaviour[TState, TTrigger]:
+					addPossible(trigger, b.TransitionInfo)
+				case *InternalOrTransitionTriggerBehaviour[TState, TTrigger]:
+					addPossible(trigger, b.TransitionInfo)
+				}
+			}
+		}
+	}
+	slices.SortFunc(edges, compareEdges[TState, TTrigger])
+	return edges
+}
+
+// settledState follows the initial transitions from state to the state the machine rests in.
+func settledState[TState, TTrigger comparable](
+	representations map[TState]*StateRepresentation[TState, TTrigger],
+	state TState,
+) TState {
+	seen := map[TState]struct{}{}
+	for {
+		rep, ok := representations[state]
+		if !ok || !rep.HasInitialTransition() || isVisited(seen, state) {
+			return state
+		}
+		seen[state] = struct{}{}
+		state = rep.InitialTransitionTarget()
+	}
+}
+
+// IsolatedStates returns the configured states that no transition leads to or leaves, in order,
+// which are most likely typos creating empty states when passed to Configure. A state is isolated if
+// it handles no trigger and has no default transition, is neither a superstate nor a substate, is not
+// the initial state, and no state names it as the destination of a transition, including the
+// possible destinations of dynamic transitions, nor as the target of an initial transition, default
+// transition or entry failure route. States only entered with GoTo are reported as well.
+func (sm *StateMachine[TState, TTrigger]) IsolatedStates() []TState {
+	representations := sm.representations()
+	statesByName := indexStatesByName(representations)
+
+	referenced := map[TState]struct{}{sm.initialState: {}}
+	reference := func(state TState) {
+		referenced[state] = struct{}{}
+	}
+	referencePossible := func(info DynamicTransitionInfo) {
+		for _, possible := range info.PossibleDestinationStates {
+			if state, ok := statesByName[possible.DestinationState]; ok {
+				reference(state)
+			}
+		}
+	}
+	for _, rep := range representations {
+		for _, behaviours := range rep.TriggerBehaviours() {
+			for _, behaviour := range behaviours {
+				switch b := behaviour.(type) {
+				case *TransitioningTriggerBehaviour[TState, TTrigger]:
+					reference(b.Destination)
+				case *ReentryTriggerBehaviour[TState, TTrigger]:
+					reference(b.Destination)
+				case *DynamicTriggerBehaviour[TState, TTrigger]:
+					referencePossible(b.TransitionInfo)
+				case *InternalOrTransitionTriggerBehaviour[TState, TTrigger]:
+					referencePossible(b.TransitionInfo)
+				}
+			}
+		}
+		if rep.HasInitialTransition() {
+			reference(rep.InitialTransitionTarget())
+		}
+		for _, initial := range rep.GuardedInitialTransitions() {
+			reference(initial.Target)
+		}
+		if rep.HasDefaultTransition() {
+			reference(rep.DefaultDestination())
+		}
+		if failureState, ok := rep.EntryFailureState(); ok {
+			reference(failureState)
+		}
+	}
+
+	var isolated []TState
+	for state, rep := range representations {
+		if len(rep.TriggerBehaviours()) > 0 || rep.HasDefaultTransition() ||
+			len(rep.GetSubstates()) > 0 || rep.Superstate() != nil || isVisited(referenced, state) {
+			continue
+		}
+		isolated = append(isolated, state)
+	}
+	sortValues(isolated)
+	return isolated
+}
@@ -0,0 +1,74 @@
+package stateless
+
+import (
+	"context"
+	"slices"
+)
+
+// errNoPreviousState is returned by the selector of a PermitBack behaviour evaluated outside of a
+// state machine, which is the only place the previous state is known.
+var errNoPreviousState = &InvalidOperationError{Message: "there is no previous state to go back to"}
+
+// PreviousState returns the state the machine was in before its last change of state, and false
+// before the state first changed. Transitions record their source state, whether fired or made by
+// GoTo; initial transitions into substates do not, so after moving from A into B, whose initial
+// transition leads to B1, the previous state is A. Reentries, including those of a superstate of the
+// current state, leave the previous state unchanged.
+func (sm *StateMachine[TState, TTrigger]) PreviousState() (TState, bool) {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+	return sm.previousState, sm.hasPreviousState
+}
+
+// recordDeparture remembers the state of source as the previous state when a transition leaves it,
+// which reentering it or one of its superstates does not, and pushes it onto the history if enabled
+// and pushHistory is set.
+func (sm *StateMachine[TState, TTrigger]) recordDeparture(
+	source *StateRepresentation[TState, TTrigger],
+	destination TState,
+	pushHistory bool,
+) {
+	if source.IsIncludedIn(destination) {
+		return
+	}
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	sm.previousState, sm.hasPreviousState = source.UnderlyingState(), true
+	if pushHistory && sm.historyDepth > 0 {
+		if len(sm.history) == sm.historyDepth {
+			sm.history = slices.Delete(sm.history, 0, 1)
+		}
+		sm.history = append(sm.history, source.UnderlyingState())
+	}
+}
+
+// backDestination returns the destination of a PermitBack transition.
+func (sm *StateMachine[TState, TTrigger]) backDestination() (TState, error) {
+	previous, ok := sm.PreviousState()
+	if !ok {
+		return previous, errNoPreviousState
+	}
+	return previous, nil
+}
+
+// PermitBack configures the state to transition back to the machine's previous state, as reported by
+// PreviousState, when the specified trigger is fired. Configured on a superstate, it leads back from
+// any of its substates. The previous state is the one before the latest change of state, so firing
+// the trigger twice returns to where the first fire started. Firing it before the state first changed
+// returns an InvalidOperationError.
+func (sn *StateNode[TState, TTrigger]) PermitBack(tr TTrigger) *StateNode[TState, TTrigger] {
+	selector := func(context.Context, any) (TState, error) {
+		var zero TState
+		return zero, errNoPreviousState
+	}
+	info := DynamicTransitionInfo{
+		transitionInfoBase: transitionInfoBase{
+			Trigger: NewTriggerInfo(tr),
+		},
+		DestinationStateSelectorDescription: NewInvocationInfo("PreviousState", "previous state"),
+	}
+	behaviour := NewDynamicTriggerBehaviour(tr, selector, EmptyTransitionGuard, info)
+	behaviour.back = true
+	sn.representation.AddTriggerBehaviour(behaviour)
+	return sn
+}
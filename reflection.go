@@ -54,7 +54,9 @@ func (i InvocationInfo) Description() string {
 	return i.MethodName
 }
 
-// getFunctionName returns the name of a function.
+// getFunctionName returns the name of a function: the bare name of a named function or method,
+// without its package and receiver, so that "pkg.(*handler).OnOpen-fm" becomes "OnOpen". The names
+// of closures are kept qualified, which Description reports as compiler-generated.
 func getFunctionName(fn any) string {
 	if fn == nil {
 		return ""
@@ -64,9 +66,31 @@ func getFunctionName(fn any) string {
 	if idx := strings.LastIndex(name, "/"); idx >= 0 {
 		name = name[idx+1:]
 	}
+	// Method values are suffixed with -fm, and generic instantiations named with [...]
+	name = strings.TrimSuffix(name, "-fm")
+	name = strings.ReplaceAll(name, "[...]", "")
+	if isClosureName(name) {
+		return name
+	}
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
 	return name
 }
 
+// isClosureName reports whether a qualified function name names a closure, such as
+// "pkg.Outer.func1" or "pkg.Outer.func1.2".
+func isClosureName(name string) bool {
+	segments := strings.Split(name, ".")
+	for _, segment := range segments[1:] {
+		digits := strings.TrimPrefix(segment, "func")
+		if digits != "" && strings.Trim(digits, "0123456789") == "" {
+			return true
+		}
+	}
+	return false
+}
+
 // ActionInfo describes an action with optional trigger information.
 type ActionInfo struct {
 	InvocationInfo
@@ -151,6 +175,12 @@ type StateInfo struct {
 
 	// IgnoredTriggers are triggers ignored for this state.
 	IgnoredTriggers []IgnoredTransitionInfo
+
+	// Tags is the metadata attached with WithTag, or nil if there is none.
+	Tags map[string]string
+
+	// IsFinal is true if the state was marked final with MarkFinal.
+	IsFinal bool
 }
 
 // String returns the string representation of the state.
@@ -216,6 +246,9 @@ type FixedTransitionInfo struct {
 
 	// DestinationState is the state that will be transitioned into on activation.
 	DestinationState *StateInfo
+
+	// InternalAction describes the action of an internal transition (zero for other transitions).
+	InternalAction InvocationInfo
 }
 
 // DynamicStateInfo contains information about a possible destination state for a dynamic transition.
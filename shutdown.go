@@ -0,0 +1,24 @@
+package stateless
+
+// Shutdown stops the state machine from processing further triggers, for a graceful shutdown of a
+// long-lived machine. The trigger being processed, if any, completes along with the triggers its
+// actions fire in FiringImmediate mode.
+//
+// In FiringQueued mode, processing of the queue stops before its next event, leaving the remaining
+// events in the queue, for example to be processed by another machine sharing a persistent
+// TriggerQueue, and the fire processing it and any FireAndWait callers return ErrShuttingDown.
+// Triggers fired afterwards are enqueued without being processed. In FiringImmediate mode, triggers
+// fired afterwards return ErrShuttingDown without being processed.
+//
+// The context of each trigger is honored as before: a trigger whose context is done is not
+// processed, and processing of the queue stops with its error.
+func (sm *StateMachine[TState, TTrigger]) Shutdown() {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	sm.shuttingDown.Store(true)
+}
+
+// isShuttingDown reports whether Shutdown was called.
+func (sm *StateMachine[TState, TTrigger]) isShuttingDown() bool {
+	return sm.shuttingDown.Load()
+}
@@ -0,0 +1,82 @@
+package stateless
+
+import "context"
+
+// Snapshot captures the runtime status of a state machine for crash recovery: the current state,
+// whether the machine is active, and the triggers waiting in its queue. Configuration is not part
+// of a snapshot. Snapshot has JSON tags so it can be persisted directly; queued args then come back
+// in their generic JSON form, which a decoder registered with SetArgDecoder can convert when the
+// trigger is processed.
+type Snapshot[TState, TTrigger comparable] struct {
+	// State is the current state.
+	State TState `json:"state"`
+
+	// Active indicates whether the machine was activated.
+	Active bool `json:"active"`
+
+	// Queue contains the pending triggers of a FiringQueued machine, front first.
+	Queue []Event[TTrigger] `json:"queue,omitempty"`
+}
+
+// Snapshot captures the current state, activation status and pending queued triggers.
+// The queue is read with TriggerQueue.Events and left untouched.
+func (sm *StateMachine[TState, TTrigger]) Snapshot() Snapshot[TState, TTrigger] {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	pending := sm.eventQueue.Events()
+	queue := make([]Event[TTrigger], 0, len(pending))
+	for _, event := range pending {
+		queue = append(queue, event.Event)
+	}
+
+	return Snapshot[TState, TTrigger]{
+		State:  sm.State(),
+		Active: sm.isActive,
+		Queue:  queue,
+	}
+}
+
+// Restore puts the machine back into the status captured by a snapshot. The state is set and the
+// activation status restored without running entry, exit or activation actions, and without raising
+// transition events. The current queue is replaced by the snapshot's pending triggers, which are then
+// processed: in FiringQueued mode as a queue, in FiringImmediate mode one after the other. The first
+// error aborts processing and is returned. Restore cannot be called while the machine is firing.
+func (sm *StateMachine[TState, TTrigger]) Restore(s Snapshot[TState, TTrigger]) error {
+	sm.mutex.Lock()
+	if sm.firing {
+		sm.mutex.Unlock()
+		return &InvalidOperationError{Message: "cannot restore a snapshot while the state machine is firing"}
+	}
+
+	sm.setState(s.State)
+	sm.isActive = s.Active
+	for {
+		if _, ok := sm.eventQueue.Pop(); !ok {
+			break
+		}
+	}
+
+	if sm.firingMode != FiringQueued {
+		sm.mutex.Unlock()
+		for _, event := range s.Queue {
+			if err := sm.FireCtx(context.Background(), event.Trigger, event.Args); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, event := range s.Queue {
+		sm.eventQueue.Push(QueuedEvent[TTrigger]{Event: event, Context: context.Background()})
+	}
+	if sm.eventQueue.Len() == 0 {
+		sm.mutex.Unlock()
+		return nil
+	}
+	sm.firing = true
+	sm.mutex.Unlock()
+
+	_, err := sm.processQueue()
+	return err
+}
@@ -0,0 +1,95 @@
+package stateless_test
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/atlekbai/stateless"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued)
+
+	var snapshot stateless.Snapshot[State, Trigger]
+	entries := 0
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		OnEntry(func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			entries++
+			sm.Fire(TriggerY, nil)
+			snapshot = sm.Snapshot()
+			return nil
+		}).
+		Permit(TriggerY, StateC)
+
+	if err := sm.Activate(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateC {
+		t.Fatalf("expected StateC, got %v", sm.State())
+	}
+
+	if snapshot.State != StateB || !snapshot.Active {
+		t.Errorf("expected active snapshot in StateB, got %+v", snapshot)
+	}
+	if len(snapshot.Queue) != 1 || snapshot.Queue[0].Trigger != TriggerY {
+		t.Fatalf("expected TriggerY to be queued, got %v", snapshot.Queue)
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded stateless.Snapshot[State, Trigger]
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	restored := sm.Clone(StateA)
+	if err := restored.Restore(decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if restored.State() != StateC {
+		t.Errorf("expected queued TriggerY to move the restored machine to StateC, got %v", restored.State())
+	}
+	if !restored.Snapshot().Active {
+		t.Error("expected restored machine to be active")
+	}
+	if entries != 1 {
+		t.Errorf("expected entry actions of StateB not to run again, got %d entries", entries)
+	}
+}
+
+func TestSnapshot_LeavesCustomQueueUntouched(t *testing.T) {
+	queue := &recordingQueue{}
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued, queue)
+
+	var snapshot stateless.Snapshot[State, Trigger]
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		OnEntry(func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			sm.Fire(TriggerY, nil)
+			sm.Fire(TriggerZ, nil)
+			snapshot = sm.Snapshot()
+			return nil
+		}).
+		Permit(TriggerY, StateC)
+	sm.Configure(StateC).Ignore(TriggerZ)
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(snapshot.Queue) != 2 || snapshot.Queue[0].Trigger != TriggerY || snapshot.Queue[1].Trigger != TriggerZ {
+		t.Errorf("expected TriggerY and TriggerZ to be queued, got %v", snapshot.Queue)
+	}
+	if expected := []Trigger{TriggerX, TriggerY, TriggerZ}; !slices.Equal(queue.pushed, expected) {
+		t.Errorf("expected Snapshot not to push onto the queue, got pushes %v", queue.pushed)
+	}
+}
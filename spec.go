@@ -0,0 +1,488 @@
+package stateless
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// MachineSpec is a declarative description of a state machine configuration.
+// States, triggers, guards and actions are referenced by name and resolved by BuildFromSpec.
+//
+// Specs can be stored as JSON with encoding/json, for example:
+//
+//	{
+//	  "initialState": "Idle",
+//	  "states": [
+//	    {"name": "Idle", "transitions": [{"trigger": "Start", "destination": "Running", "guard": "ready"}]},
+//	    {"name": "Running", "initialTransition": "Warmup", "entryActions": ["log"]},
+//	    {"name": "Warmup", "superstate": "Running", "ignoredTriggers": [{"trigger": "Start"}]}
+//	  ]
+//	}
+//
+// Optional fields are omitted when empty, and unmarshaling a marshaled spec yields an equal spec.
+type MachineSpec struct {
+	// InitialState is the name of the state the machine starts in.
+	InitialState string `json:"initialState"`
+
+	// States contains the configuration of each state.
+	States []StateSpec `json:"states"`
+}
+
+// StateSpec describes the configuration of a single state.
+type StateSpec struct {
+	// Name is the name of the state.
+	Name string `json:"name"`
+
+	// Superstate is the name of the parent state (empty for a root state).
+	Superstate string `json:"superstate,omitempty"`
+
+	// InitialTransition is the name of the substate entered automatically (empty for none).
+	InitialTransition string `json:"initialTransition,omitempty"`
+
+	// Transitions are the transitions leaving this state.
+	Transitions []TransitionSpec `json:"transitions,omitempty"`
+
+	// IgnoredTriggers are the triggers ignored in this state.
+	IgnoredTriggers []IgnoredTriggerSpec `json:"ignoredTriggers,omitempty"`
+
+	// EntryActions are the names of the actions executed when entering this state.
+	EntryActions []string `json:"entryActions,omitempty"`
+
+	// ExitActions are the names of the actions executed when exiting this state.
+	ExitActions []string `json:"exitActions,omitempty"`
+}
+
+// TransitionSpec describes a transition triggered from a state.
+// A transition whose destination is the state itself is configured as a reentry.
+type TransitionSpec struct {
+	// Trigger is the name of the trigger.
+	Trigger string `json:"trigger"`
+
+	// Destination is the name of the destination state.
+	Destination string `json:"destination"`
+
+	// Guard is the name of the guard condition (empty for an unguarded transition).
+	Guard string `json:"guard,omitempty"`
+}
+
+// IgnoredTriggerSpec describes a trigger ignored in a state.
+type IgnoredTriggerSpec struct {
+	// Trigger is the name of the trigger.
+	Trigger string `json:"trigger"`
+
+	// Guard is the name of the guard condition (empty to always ignore).
+	Guard string `json:"guard,omitempty"`
+}
+
+// Validate checks that the spec is consistent on its own, without resolving names: every state it
+// references is described in States, state names are unique, superstates form no cycle, initial
+// transitions target substates, trigger, guard and action names are not empty, and no state handles
+// a trigger with more than one unguarded transition or ignored trigger. All problems found are
+// returned joined, as ArgumentErrors.
+//
+// BuildFromSpec does not call Validate, and accepts specs that leave states only referenced as
+// destinations undescribed; Validate is meant for specs stored as data, where such a state is more
+// likely a typo.
+func (spec *MachineSpec) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, &ArgumentError{ParamName: "spec", Message: fmt.Sprintf(format, args...)})
+	}
+
+	states := make(map[string]*StateSpec, len(spec.States))
+	for i := range spec.States {
+		stateSpec := &spec.States[i]
+		if stateSpec.Name == "" {
+			fail("state %d has no name", i)
+			continue
+		}
+		if _, ok := states[stateSpec.Name]; ok {
+			fail("state %q is described more than once", stateSpec.Name)
+			continue
+		}
+		states[stateSpec.Name] = stateSpec
+	}
+	declared := func(name string) bool {
+		_, ok := states[name]
+		return ok
+	}
+
+	if spec.InitialState == "" {
+		fail("no initial state")
+	} else if !declared(spec.InitialState) {
+		fail("initial state %q is not described", spec.InitialState)
+	}
+
+	for _, stateSpec := range spec.States {
+		if stateSpec.Name == "" {
+			continue
+		}
+		name := stateSpec.Name
+
+		if stateSpec.Superstate != "" {
+			if !declared(stateSpec.Superstate) {
+				fail("state %q: superstate %q is not described", name, stateSpec.Superstate)
+			} else if specIsAncestor(states, name, stateSpec.Superstate) {
+				fail("state %q: superstate %q makes a cycle", name, stateSpec.Superstate)
+			}
+		}
+		if target := stateSpec.InitialTransition; target != "" {
+			if !declared(target) {
+				fail("state %q: initial transition target %q is not described", name, target)
+			} else if target == name || !specIsAncestor(states, name, target) {
+				fail("state %q: initial transition target %q is not a substate", name, target)
+			}
+		}
+
+		unguarded := map[string]int{}
+		for _, ts := range stateSpec.Transitions {
+			if ts.Trigger == "" {
+				fail("state %q: transition to %q has no trigger", name, ts.Destination)
+			}
+			if !declared(ts.Destination) {
+				fail("state %q: destination %q of trigger %q is not described", name, ts.Destination, ts.Trigger)
+			}
+			if ts.Guard == "" {
+				unguarded[ts.Trigger]++
+			}
+		}
+		for _, is := range stateSpec.IgnoredTriggers {
+			if is.Trigger == "" {
+				fail("state %q: ignored trigger has no name", name)
+			}
+			if is.Guard == "" {
+				unguarded[is.Trigger]++
+			}
+		}
+		for _, trigger := range slices.Sorted(maps.Keys(unguarded)) {
+			if unguarded[trigger] > 1 && trigger != "" {
+				fail("state %q: trigger %q is handled by more than one unguarded behaviour", name, trigger)
+			}
+		}
+
+		for _, action := range slices.Concat(stateSpec.EntryActions, stateSpec.ExitActions) {
+			if action == "" {
+				fail("state %q: action has no name", name)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// specIsAncestor reports whether ancestor is state itself or one of its superstates in states,
+// stopping at a superstate cycle.
+func specIsAncestor(states map[string]*StateSpec, ancestor, state string) bool {
+	seen := map[string]bool{}
+	for state != "" && !seen[state] {
+		if state == ancestor {
+			return true
+		}
+		seen[state] = true
+		stateSpec, ok := states[state]
+		if !ok {
+			return false
+		}
+		state = stateSpec.Superstate
+	}
+	return false
+}
+
+// Spec returns a MachineSpec describing the configuration of the machine, the counterpart of
+// BuildFromSpec, for example to store a machine configured in Go as JSON. States and triggers are
+// named with their formatted value (fmt "%v"), guards and actions with their descriptions, which for
+// a machine built with BuildFromSpec are the registry names. Building the returned spec with parse
+// functions and registries matching those names yields a machine with the same configuration. States
+// are ordered by state value, and the transitions and ignored triggers of each state by trigger.
+//
+// A MachineSpec describes fixed transitions, reentries and ignored triggers guarded by at most one
+// condition, superstates, initial transitions, and entry and exit actions. Spec returns an
+// InvalidOperationError if a state is configured with anything else, such as a dynamic or internal
+// transition, a guarded initial transition, a default transition, an entry failure route, a tick or an
+// activation action. Tags and final marks are not described, and actions are described by name only,
+// so an action registered with OnEntryFromState is described as a plain entry action.
+func (sm *StateMachine[TState, TTrigger]) Spec() (MachineSpec, error) {
+	representations := sm.representations()
+	states := slices.Collect(maps.Keys(representations))
+	sortValues(states)
+
+	spec := MachineSpec{
+		InitialState: specName(sm.initialState),
+		States:       make([]StateSpec, 0, len(states)),
+	}
+	for _, state := range states {
+		stateSpec, err := describeStateSpec(representations[state])
+		if err != nil {
+			return MachineSpec{}, err
+		}
+		spec.States = append(spec.States, stateSpec)
+	}
+	return spec, nil
+}
+
+// specName returns the name of a state or trigger in a MachineSpec.
+func specName(value any) string {
+	return fmt.Sprintf("%v", value)
+}
+
+// describeStateSpec describes the configuration of a state as a StateSpec, or returns an
+// InvalidOperationError if it cannot be described.
+func describeStateSpec[TState, TTrigger comparable](rep *StateRepresentation[TState, TTrigger]) (StateSpec, error) {
+	state := rep.UnderlyingState()
+	unsupported := func(what string) error {
+		return &InvalidOperationError{
+			Message: fmt.Sprintf("state '%v' has %s, which a MachineSpec cannot describe", state, what),
+		}
+	}
+
+	_, hasEntryFailure := rep.EntryFailureState()
+	switch {
+	case len(rep.GuardedInitialTransitions()) > 0:
+		return StateSpec{}, unsupported("a guarded initial transition")
+	case rep.HasDefaultTransition():
+		return StateSpec{}, unsupported("a default transition")
+	case hasEntryFailure:
+		return StateSpec{}, unsupported("an entry failure route")
+	case len(rep.Ticks()) > 0:
+		return StateSpec{}, unsupported("a tick")
+	case len(rep.ActivateActions()) > 0 || len(rep.DeactivateActions()) > 0:
+		return StateSpec{}, unsupported("an activation or deactivation action")
+	}
+
+	stateSpec := StateSpec{Name: specName(state)}
+	if superstate := rep.Superstate(); superstate != nil {
+		stateSpec.Superstate = specName(superstate.UnderlyingState())
+	}
+	if rep.HasInitialTransition() {
+		stateSpec.InitialTransition = specName(rep.InitialTransitionTarget())
+	}
+
+	behavioursByTrigger := rep.TriggerBehaviours()
+	triggers := slices.Collect(maps.Keys(behavioursByTrigger))
+	sortValues(triggers)
+	for _, tr := range triggers {
+		for _, behaviour := range behavioursByTrigger[tr] {
+			conditions := behaviour.GetGuard().Conditions
+			if len(conditions) > 1 {
+				return StateSpec{}, unsupported(fmt.Sprintf("a guard of several conditions for trigger '%v'", tr))
+			}
+			var guard string
+			if len(conditions) == 1 {
+				guard = conditions[0].Description()
+			}
+
+			switch b := behaviour.(type) {
+			case *TransitioningTriggerBehaviour[TState, TTrigger]:
+				stateSpec.Transitions = append(stateSpec.Transitions,
+					TransitionSpec{Trigger: specName(tr), Destination: specName(b.Destination), Guard: guard})
+			case *ReentryTriggerBehaviour[TState, TTrigger]:
+				stateSpec.Transitions = append(stateSpec.Transitions,
+					TransitionSpec{Trigger: specName(tr), Destination: specName(b.Destination), Guard: guard})
+			case *IgnoredTriggerBehaviour[TState, TTrigger]:
+				stateSpec.IgnoredTriggers = append(stateSpec.IgnoredTriggers,
+					IgnoredTriggerSpec{Trigger: specName(tr), Guard: guard})
+			case *DynamicTriggerBehaviour[TState, TTrigger], *InternalOrTransitionTriggerBehaviour[TState, TTrigger]:
+				return StateSpec{}, unsupported(fmt.Sprintf("a dynamic transition for trigger '%v'", tr))
+			default:
+				return StateSpec{}, unsupported(fmt.Sprintf("an internal transition for trigger '%v'", tr))
+			}
+		}
+	}
+
+	for _, action := range rep.EntryActions() {
+		stateSpec.EntryActions = append(stateSpec.EntryActions, action.GetDescription().Description())
+	}
+	for _, action := range rep.ExitActions() {
+		stateSpec.ExitActions = append(stateSpec.ExitActions, action.GetDescription().Description())
+	}
+	return stateSpec, nil
+}
+
+// BuildFromSpec creates a state machine from a declarative specification.
+// State and trigger names are converted with parseState and parseTrigger, while guard and
+// action names are looked up in the guards and actions registries. The registry name is used
+// as the description of the guard or action, so it shows up in graphs and introspection.
+func BuildFromSpec[TState, TTrigger comparable](
+	spec MachineSpec,
+	parseState func(string) (TState, error),
+	parseTrigger func(string) (TTrigger, error),
+	guards map[string]GuardFunc,
+	actions map[string]TransitionAction[TState, TTrigger],
+) (*StateMachine[TState, TTrigger], error) {
+	b := &specBuilder[TState, TTrigger]{
+		parseState:   parseState,
+		parseTrigger: parseTrigger,
+		guards:       guards,
+		actions:      actions,
+	}
+
+	initial, err := b.state(spec.InitialState)
+	if err != nil {
+		return nil, err
+	}
+	b.sm = NewStateMachine[TState, TTrigger](initial)
+
+	// Configure behaviours first, then hierarchy, so that specs can reference states in any order.
+	for _, stateSpec := range spec.States {
+		if err := b.configureState(stateSpec); err != nil {
+			return nil, err
+		}
+	}
+	for _, stateSpec := range spec.States {
+		if err := b.configureHierarchy(stateSpec); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.sm, nil
+}
+
+// specBuilder holds the resolvers used while building a state machine from a MachineSpec.
+type specBuilder[TState, TTrigger comparable] struct {
+	sm           *StateMachine[TState, TTrigger]
+	parseState   func(string) (TState, error)
+	parseTrigger func(string) (TTrigger, error)
+	guards       map[string]GuardFunc
+	actions      map[string]TransitionAction[TState, TTrigger]
+}
+
+// configureState configures the transitions, ignored triggers and actions of a state.
+func (b *specBuilder[TState, TTrigger]) configureState(spec StateSpec) error {
+	state, err := b.state(spec.Name)
+	if err != nil {
+		return err
+	}
+	rep := b.sm.Configure(state).representation
+
+	for _, ts := range spec.Transitions {
+		tr, err := b.trigger(ts.Trigger)
+		if err != nil {
+			return err
+		}
+		dst, err := b.state(ts.Destination)
+		if err != nil {
+			return err
+		}
+		guard, err := b.guard(ts.Guard)
+		if err != nil {
+			return err
+		}
+		if dst == state {
+			rep.AddTriggerBehaviour(NewReentryTriggerBehaviour(tr, dst, guard))
+		} else {
+			rep.AddTriggerBehaviour(NewTransitioningTriggerBehaviour(tr, dst, guard))
+		}
+	}
+
+	for _, is := range spec.IgnoredTriggers {
+		tr, err := b.trigger(is.Trigger)
+		if err != nil {
+			return err
+		}
+		guard, err := b.guard(is.Guard)
+		if err != nil {
+			return err
+		}
+		rep.AddTriggerBehaviour(NewIgnoredTriggerBehaviour[TState](tr, guard))
+	}
+
+	for _, name := range spec.EntryActions {
+		act, err := b.action(name)
+		if err != nil {
+			return err
+		}
+		rep.AddEntryAction(NewEntryActionBehaviour(act, CreateInvocationInfo(act, name)))
+	}
+
+	for _, name := range spec.ExitActions {
+		act, err := b.action(name)
+		if err != nil {
+			return err
+		}
+		rep.AddExitAction(NewExitActionBehaviour(act, CreateInvocationInfo(act, name)))
+	}
+
+	return nil
+}
+
+// configureHierarchy configures the superstate and initial transition of a state.
+func (b *specBuilder[TState, TTrigger]) configureHierarchy(spec StateSpec) error {
+	state, err := b.state(spec.Name)
+	if err != nil {
+		return err
+	}
+	rep := b.sm.getRepresentation(state)
+
+	if spec.Superstate != "" {
+		superstate, err := b.state(spec.Superstate)
+		if err != nil {
+			return err
+		}
+		superRep := b.sm.getRepresentation(superstate)
+		if superRep.IsIncludedIn(state) {
+			return &InvalidOperationError{
+				Message: fmt.Sprintf("circular superstate relationship detected: %v -> %v", state, superstate),
+			}
+		}
+		rep.SetSuperstate(superRep)
+		superRep.AddSubstate(rep)
+	}
+
+	if spec.InitialTransition != "" {
+		target, err := b.state(spec.InitialTransition)
+		if err != nil {
+			return err
+		}
+		if target == state {
+			return &InvalidOperationError{
+				Message: fmt.Sprintf("initial transition to self is not allowed: state '%v'", state),
+			}
+		}
+		rep.SetInitialTransition(target)
+	}
+
+	return nil
+}
+
+// state parses a state name.
+func (b *specBuilder[TState, TTrigger]) state(name string) (TState, error) {
+	state, err := b.parseState(name)
+	if err != nil {
+		return state, &ArgumentError{ParamName: "state", Message: fmt.Sprintf("cannot parse state %q: %v", name, err)}
+	}
+	return state, nil
+}
+
+// trigger parses a trigger name.
+func (b *specBuilder[TState, TTrigger]) trigger(name string) (TTrigger, error) {
+	tr, err := b.parseTrigger(name)
+	if err != nil {
+		return tr, &ArgumentError{ParamName: "trigger", Message: fmt.Sprintf("cannot parse trigger %q: %v", name, err)}
+	}
+	return tr, nil
+}
+
+// guard resolves a guard name to a transition guard. An empty name yields an empty guard.
+func (b *specBuilder[TState, TTrigger]) guard(name string) (TransitionGuard, error) {
+	if name == "" {
+		return EmptyTransitionGuard, nil
+	}
+	gf, ok := b.guards[name]
+	if !ok || gf == nil {
+		return TransitionGuard{}, &ArgumentError{ParamName: "guards", Message: fmt.Sprintf("unknown guard %q", name)}
+	}
+	return TransitionGuard{
+		Conditions: []GuardCondition{NewGuardCondition(gf, CreateInvocationInfo(gf, name))},
+	}, nil
+}
+
+// action resolves an action name.
+func (b *specBuilder[TState, TTrigger]) action(name string) (TransitionAction[TState, TTrigger], error) {
+	act, ok := b.actions[name]
+	if !ok || act == nil {
+		return nil, &ArgumentError{ParamName: "actions", Message: fmt.Sprintf("unknown action %q", name)}
+	}
+	return act, nil
+}
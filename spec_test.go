@@ -0,0 +1,399 @@
+package stateless_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/atlekbai/stateless"
+)
+
+func parseState(name string) (State, error) {
+	for _, s := range []State{StateA, StateB, StateC, StateD} {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown state %q", name)
+}
+
+func parseTrigger(name string) (Trigger, error) {
+	for _, tr := range []Trigger{TriggerX, TriggerY, TriggerZ} {
+		if tr.String() == name {
+			return tr, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown trigger %q", name)
+}
+
+func TestBuildFromSpec(t *testing.T) {
+	record := []string{}
+	allow := true
+
+	spec := stateless.MachineSpec{
+		InitialState: "StateA",
+		States: []stateless.StateSpec{
+			{
+				Name: "StateA",
+				Transitions: []stateless.TransitionSpec{
+					{Trigger: "TriggerX", Destination: "StateB", Guard: "allowed"},
+					{Trigger: "TriggerZ", Destination: "StateA"},
+				},
+				IgnoredTriggers: []stateless.IgnoredTriggerSpec{{Trigger: "TriggerY"}},
+				ExitActions:     []string{"log"},
+			},
+			{
+				Name:              "StateB",
+				InitialTransition: "StateC",
+				Transitions: []stateless.TransitionSpec{
+					{Trigger: "TriggerY", Destination: "StateA"},
+				},
+			},
+			{
+				Name:         "StateC",
+				Superstate:   "StateB",
+				EntryActions: []string{"log"},
+			},
+		},
+	}
+
+	guards := map[string]stateless.GuardFunc{
+		"allowed": func(_ context.Context, _ any) error {
+			if !allow {
+				return stateless.Reject("not allowed")
+			}
+			return nil
+		},
+	}
+	actions := map[string]stateless.TransitionAction[State, Trigger]{
+		"log": func(_ context.Context, tr stateless.Transition[State, Trigger]) error {
+			record = append(record, fmt.Sprintf("%v->%v", tr.Source, tr.Destination))
+			return nil
+		},
+	}
+
+	sm, err := stateless.BuildFromSpec(spec, parseState, parseTrigger, guards, actions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := sm.Fire(TriggerY, nil); err != nil {
+		t.Fatalf("expected ignored trigger to succeed, got %v", err)
+	}
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateC {
+		t.Errorf("expected initial transition into StateC, got %v", sm.State())
+	}
+	if !sm.IsInState(StateB) {
+		t.Error("expected StateC to be a substate of StateB")
+	}
+
+	expected := []string{"StateA->StateB", "StateB->StateC"}
+	if len(record) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %v", len(expected), len(record), record)
+	}
+	for i := range expected {
+		if record[i] != expected[i] {
+			t.Errorf("expected %s at index %d, got %s", expected[i], i, record[i])
+		}
+	}
+
+	// Guard and action names become descriptions
+	for _, info := range sm.GetInfo().States {
+		if info.UnderlyingState != StateA {
+			continue
+		}
+		if len(info.ExitActions) != 1 || info.ExitActions[0].Description() != "log" {
+			t.Errorf("expected exit action described as 'log', got %v", info.ExitActions)
+		}
+		for _, fixed := range info.FixedTransitions {
+			if fixed.DestinationState.UnderlyingState == StateB && fixed.GuardConditions[0].Description() != "allowed" {
+				t.Errorf("expected guard described as 'allowed', got %v", fixed.GuardConditions)
+			}
+		}
+	}
+}
+
+func TestBuildFromSpec_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec stateless.MachineSpec
+	}{
+		{
+			name: "unknown state",
+			spec: stateless.MachineSpec{InitialState: "Nowhere"},
+		},
+		{
+			name: "unknown trigger",
+			spec: stateless.MachineSpec{
+				InitialState: "StateA",
+				States: []stateless.StateSpec{{
+					Name:        "StateA",
+					Transitions: []stateless.TransitionSpec{{Trigger: "Bogus", Destination: "StateB"}},
+				}},
+			},
+		},
+		{
+			name: "unknown guard",
+			spec: stateless.MachineSpec{
+				InitialState: "StateA",
+				States: []stateless.StateSpec{{
+					Name:        "StateA",
+					Transitions: []stateless.TransitionSpec{{Trigger: "TriggerX", Destination: "StateB", Guard: "missing"}},
+				}},
+			},
+		},
+		{
+			name: "unknown action",
+			spec: stateless.MachineSpec{
+				InitialState: "StateA",
+				States:       []stateless.StateSpec{{Name: "StateA", EntryActions: []string{"missing"}}},
+			},
+		},
+		{
+			name: "circular superstate",
+			spec: stateless.MachineSpec{
+				InitialState: "StateA",
+				States: []stateless.StateSpec{
+					{Name: "StateA", Superstate: "StateB"},
+					{Name: "StateB", Superstate: "StateA"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := stateless.BuildFromSpec[State, Trigger](tt.spec, parseState, parseTrigger, nil, nil)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			var argErr *stateless.ArgumentError
+			var opErr *stateless.InvalidOperationError
+			if !errors.As(err, &argErr) && !errors.As(err, &opErr) {
+				t.Errorf("expected ArgumentError or InvalidOperationError, got %T", err)
+			}
+		})
+	}
+}
+
+func TestMachineSpec_JSONRoundTrip(t *testing.T) {
+	spec := stateless.MachineSpec{
+		InitialState: "StateA",
+		States: []stateless.StateSpec{
+			{
+				Name:            "StateA",
+				Transitions:     []stateless.TransitionSpec{{Trigger: "TriggerX", Destination: "StateB", Guard: "allowed"}},
+				IgnoredTriggers: []stateless.IgnoredTriggerSpec{{Trigger: "TriggerY"}},
+				ExitActions:     []string{"log"},
+			},
+			{Name: "StateB", InitialTransition: "StateC"},
+			{Name: "StateC", Superstate: "StateB", EntryActions: []string{"log"}},
+		},
+	}
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, field := range []string{
+		`"initialState":"StateA"`, `"exitActions":["log"]`, `"guard":"allowed"`,
+		`"ignoredTriggers":[{"trigger":"TriggerY"}]`, `"initialTransition":"StateC"`, `"superstate":"StateB"`,
+	} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("expected JSON to contain %s, got %s", field, data)
+		}
+	}
+
+	var decoded stateless.MachineSpec
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, spec) {
+		t.Errorf("expected %+v after round trip, got %+v", spec, decoded)
+	}
+
+	guards := map[string]stateless.GuardFunc{"allowed": func(context.Context, any) error { return nil }}
+	actions := map[string]stateless.TransitionAction[State, Trigger]{
+		"log": func(context.Context, stateless.Transition[State, Trigger]) error { return nil },
+	}
+	original, err := stateless.BuildFromSpec(spec, parseState, parseTrigger, guards, actions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rebuilt, err := stateless.BuildFromSpec(decoded, parseState, parseTrigger, guards, actions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if original.Describe() != rebuilt.Describe() {
+		t.Errorf("expected equivalent machines, got:\n%s\nand:\n%s", original.Describe(), rebuilt.Describe())
+	}
+}
+
+func TestSpec_RoundTrip(t *testing.T) {
+	spec := stateless.MachineSpec{
+		InitialState: "StateA",
+		States: []stateless.StateSpec{
+			{
+				Name: "StateA",
+				Transitions: []stateless.TransitionSpec{
+					{Trigger: "TriggerX", Destination: "StateB", Guard: "allowed"},
+					{Trigger: "TriggerZ", Destination: "StateA"},
+				},
+				IgnoredTriggers: []stateless.IgnoredTriggerSpec{{Trigger: "TriggerY", Guard: "allowed"}},
+				ExitActions:     []string{"log"},
+			},
+			{Name: "StateB", InitialTransition: "StateC"},
+			{Name: "StateC", Superstate: "StateB", EntryActions: []string{"log", "log"}},
+		},
+	}
+	guards := map[string]stateless.GuardFunc{"allowed": func(context.Context, any) error { return nil }}
+	actions := map[string]stateless.TransitionAction[State, Trigger]{
+		"log": func(context.Context, stateless.Transition[State, Trigger]) error { return nil },
+	}
+
+	sm, err := stateless.BuildFromSpec(spec, parseState, parseTrigger, guards, actions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exported, err := sm.Spec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(exported, spec) {
+		t.Errorf("expected %+v, got %+v", spec, exported)
+	}
+
+	rebuilt, err := stateless.BuildFromSpec(exported, parseState, parseTrigger, guards, actions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.Describe() != rebuilt.Describe() {
+		t.Errorf("expected equivalent machines, got:\n%s\nand:\n%s", sm.Describe(), rebuilt.Describe())
+	}
+}
+
+func TestSpec_ConfiguredInGo(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateB)
+	sm.Configure(StateA).
+		PermitIf(TriggerX, StateB, func(context.Context, any) error { return nil }, "ready")
+	sm.Configure(StateB).
+		Permit(TriggerY, StateA).
+		OnEntry(func(context.Context, stateless.Transition[State, Trigger]) error { return nil }, "log")
+
+	spec, err := sm.Spec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := stateless.MachineSpec{
+		InitialState: "StateB",
+		States: []stateless.StateSpec{
+			{Name: "StateA", Transitions: []stateless.TransitionSpec{
+				{Trigger: "TriggerX", Destination: "StateB", Guard: "ready"},
+			}},
+			{
+				Name:         "StateB",
+				Transitions:  []stateless.TransitionSpec{{Trigger: "TriggerY", Destination: "StateA"}},
+				EntryActions: []string{"log"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(spec, expected) {
+		t.Errorf("expected %+v, got %+v", expected, spec)
+	}
+}
+
+func TestSpec_UnsupportedConfiguration(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB).
+		PermitDynamic(TriggerY, func(context.Context, any) (State, error) { return StateC, nil })
+
+	_, err := sm.Spec()
+	var invalidOperationErr *stateless.InvalidOperationError
+	if !errors.As(err, &invalidOperationErr) {
+		t.Fatalf("expected InvalidOperationError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "dynamic transition") {
+		t.Errorf("expected the dynamic transition to be named, got %v", err)
+	}
+}
+
+func TestMachineSpec_Validate(t *testing.T) {
+	valid := func() stateless.MachineSpec {
+		return stateless.MachineSpec{
+			InitialState: "StateA",
+			States: []stateless.StateSpec{
+				{Name: "StateA", Transitions: []stateless.TransitionSpec{{Trigger: "TriggerX", Destination: "StateB"}}},
+				{Name: "StateB", InitialTransition: "StateC"},
+				{Name: "StateC", Superstate: "StateB"},
+			},
+		}
+	}
+	tests := []struct {
+		name    string
+		modify  func(spec *stateless.MachineSpec)
+		message string
+	}{
+		{"no initial state", func(spec *stateless.MachineSpec) { spec.InitialState = "" }, "no initial state"},
+		{
+			"undescribed destination",
+			func(spec *stateless.MachineSpec) { spec.States[0].Transitions[0].Destination = "StateD" },
+			`destination "StateD" of trigger "TriggerX" is not described`,
+		},
+		{
+			"duplicate state",
+			func(spec *stateless.MachineSpec) {
+				spec.States = append(spec.States, stateless.StateSpec{Name: "StateA"})
+			},
+			`state "StateA" is described more than once`,
+		},
+		{
+			"superstate cycle",
+			func(spec *stateless.MachineSpec) { spec.States[1].Superstate = "StateC" },
+			`superstate "StateC" makes a cycle`,
+		},
+		{
+			"initial transition outside",
+			func(spec *stateless.MachineSpec) { spec.States[1].InitialTransition = "StateA" },
+			`initial transition target "StateA" is not a substate`,
+		},
+		{
+			"ambiguous trigger",
+			func(spec *stateless.MachineSpec) {
+				spec.States[0].IgnoredTriggers = []stateless.IgnoredTriggerSpec{{Trigger: "TriggerX"}}
+			},
+			`trigger "TriggerX" is handled by more than one unguarded behaviour`,
+		},
+		{
+			"empty action",
+			func(spec *stateless.MachineSpec) { spec.States[2].EntryActions = []string{""} },
+			"action has no name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := valid()
+			tt.modify(&spec)
+			err := spec.Validate()
+			var argErr *stateless.ArgumentError
+			if !errors.As(err, &argErr) || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("expected an ArgumentError containing %q, got %v", tt.message, err)
+			}
+		})
+	}
+
+	spec := valid()
+	if err := spec.Validate(); err != nil {
+		t.Errorf("unexpected error for a valid spec: %v", err)
+	}
+}
@@ -2,8 +2,15 @@ package stateless
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // FiringMode determines how the state machine handles multiple trigger fires.
@@ -11,7 +18,7 @@ type FiringMode int
 
 const (
 	// FiringImmediate causes triggers to be processed immediately (synchronously).
-	// This is the default mode.
+	// This is the default mode. Fires from several goroutines wait for each other.
 	FiringImmediate FiringMode = iota
 
 	// FiringQueued causes triggers to be queued and processed one at a time.
@@ -19,7 +26,67 @@ const (
 	FiringQueued
 )
 
+// ActionPhase identifies the stage of the state machine lifecycle in which an error occurred.
+type ActionPhase int
+
+const (
+	// PhaseEntry indicates an error returned from an entry action.
+	PhaseEntry ActionPhase = iota
+
+	// PhaseExit indicates an error returned from an exit action.
+	PhaseExit
+
+	// PhaseGuard indicates an unexpected (non-rejection) error returned from a guard.
+	PhaseGuard
+
+	// PhaseActivate indicates an error returned from an activation action.
+	PhaseActivate
+
+	// PhaseDeactivate indicates an error returned from a deactivation action.
+	PhaseDeactivate
+
+	// PhaseInternal indicates an error returned from an internal transition action.
+	PhaseInternal
+)
+
+// String returns the name of the action phase.
+func (p ActionPhase) String() string {
+	switch p {
+	case PhaseEntry:
+		return "Entry"
+	case PhaseExit:
+		return "Exit"
+	case PhaseGuard:
+		return "Guard"
+	case PhaseActivate:
+		return "Activate"
+	case PhaseDeactivate:
+		return "Deactivate"
+	case PhaseInternal:
+		return "Internal"
+	default:
+		return fmt.Sprintf("ActionPhase(%d)", int(p))
+	}
+}
+
+// ErrorHandler is called when an action returns an error.
+// Returning nil propagates the original error; returning a non-nil error replaces it.
+type ErrorHandler[TState, TTrigger comparable] func(
+	ctx context.Context,
+	t Transition[TState, TTrigger],
+	phase ActionPhase,
+	err error,
+) error
+
 // StateMachine represents a state machine that can transition between states based on triggers.
+//
+// Once configured, read-only queries (State, CanFire, IsInState, GetPermittedTriggers, GetInfo and
+// the other introspection methods) are safe to call from any goroutine while a trigger is being fired.
+// In FiringImmediate mode, fires from several goroutines are serialized: a fire waits until the one
+// in progress on another goroutine completed. Triggers fired from actions nest in the fire instead,
+// so an action must not wait for a trigger fired from another goroutine.
+// Configuring the machine concurrently with firing is not supported, and configuring it from an
+// action or guard while a trigger is being processed panics.
 type StateMachine[TState, TTrigger comparable] struct {
 	// stateAccessor is used to retrieve the current state.
 	stateAccessor func() TState
@@ -28,11 +95,47 @@ type StateMachine[TState, TTrigger comparable] struct {
 	stateMutator func(TState)
 
 	// stateRepresentations contains the configuration for each state.
-	stateRepresentations map[TState]*StateRepresentation[TState, TTrigger]
+	// Representations are created lazily, also while firing, so the map is replaced rather than modified,
+	// under representationsMutex, and loaded without it.
+	stateRepresentations atomic.Pointer[map[TState]*StateRepresentation[TState, TTrigger]]
+	representationsMutex sync.Mutex
 
 	// unhandledTriggerAction is called when a trigger is fired but not handled.
 	unhandledTriggerAction func(state TState, trigger TTrigger, unmetGuards []error)
 
+	// unhandledReturnsError makes fires return ErrTriggerUnhandled after calling unhandledTriggerAction.
+	unhandledReturnsError bool
+
+	// unhandledTriggerHandler decides what to do with a trigger that is not handled; see OnUnhandledTriggerHandler.
+	unhandledTriggerHandler UnhandledTriggerHandler[TState, TTrigger]
+
+	// errorHandler is called when an action returns an error.
+	errorHandler ErrorHandler[TState, TTrigger]
+
+	// transitioningHandlers are called before a transition starts and can veto it.
+	transitioningHandlers []TransitionAction[TState, TTrigger]
+
+	// guardEvaluatedHandlers are told about every guard condition evaluated while firing.
+	guardEvaluatedHandlers []func(trigger TTrigger, description string, passed bool, err error)
+
+	// guardErrorHandlers are told about guard errors other than rejections while firing.
+	guardErrorHandlers []func(ctx context.Context, trigger TTrigger, err error)
+
+	// guardReasonFormatter rewrites the reasons of unmet guards; nil keeps them unchanged.
+	guardReasonFormatter func(trigger TTrigger, description string) string
+
+	// anyEntryActions are run after the entry actions of every entered state.
+	anyEntryActions []TransitionAction[TState, TTrigger]
+
+	// anyExitActions are run after the exit actions of every exited state.
+	anyExitActions []TransitionAction[TState, TTrigger]
+
+	// timedHandlers are told how long each completed transition took; see OnTransitionTimed.
+	timedHandlers []func(t Transition[TState, TTrigger], duration time.Duration)
+
+	// terminalStateHandlers are called when a transition ends in a terminal state.
+	terminalStateHandlers []func(ctx context.Context, t Transition[TState, TTrigger])
+
 	// onTransitionedEvent is called when a transition is completed.
 	onTransitionedEvent *OnTransitionedEvent[TState, TTrigger]
 
@@ -43,31 +146,143 @@ type StateMachine[TState, TTrigger comparable] struct {
 	firingMode FiringMode
 
 	// eventQueue holds queued events when using FiringQueued mode.
-	eventQueue []queuedEvent[TState, TTrigger]
+	eventQueue TriggerQueue[TTrigger]
 
 	// firing indicates if the state machine is currently processing a trigger.
 	firing bool
 
-	// mutex protects the state machine from concurrent access.
-	mutex sync.Mutex
+	// queueWaiters are the FireAndWait callers waiting for the event queue to be processed.
+	queueWaiters []chan error
+
+	// shuttingDown is set by Shutdown to stop processing triggers. It is only set under mutex, so
+	// that the queue sees it consistently, but is loaded without it by immediate fires.
+	shuttingDown atomic.Bool
+
+	// values are the values attached with WithValue; the map is replaced, never modified, and only
+	// under mutex, so that fires can load it without locking.
+	values atomic.Pointer[map[any]any]
+
+	// previousState is the state before the latest change of state, if hasPreviousState is set.
+	previousState    TState
+	hasPreviousState bool
+
+	// history holds the most recent states left, up to historyDepth, with the latest last.
+	history      []TState
+	historyDepth int
+
+	// mutex protects the event queue, the firing flag, queueWaiters, shuttingDown, changes of values,
+	// the previous state and history.
+	mutex sync.RWMutex
 
 	// isActive indicates if the state machine has been activated.
 	isActive bool
 
 	// initialState stores the initial state of the state machine.
 	initialState TState
-}
 
-// queuedEvent represents an event waiting to be processed.
-type queuedEvent[TState, TTrigger comparable] struct {
-	trigger TTrigger
-	args    any
-	ctx     context.Context
+	// emitCompletedForNonTransitions makes internal transitions and ignored triggers raise OnTransitionCompleted.
+	emitCompletedForNonTransitions bool
+
+	// emitInitialTransitionEvents makes each initial-transition leg raise OnTransitionCompleted.
+	emitInitialTransitionEvents bool
+
+	// permitIdentityAsReentry makes Permit and PermitIf to the source state install a reentry behaviour.
+	permitIdentityAsReentry bool
+
+	// immediateMutex serializes fires in FiringImmediate mode; see runImmediate.
+	immediateMutex sync.Mutex
+
+	// immediateDepth counts the nested fires in progress in FiringImmediate mode. It is only
+	// accessed by the fire holding immediateMutex and those nested in it.
+	immediateDepth int32
+
+	// maxImmediateDepth bounds immediateDepth; zero or less disables the limit.
+	maxImmediateDepth atomic.Int32
+
+	// triggerParameters holds the args type registered for each trigger with SetTriggerParameters.
+	triggerParameters map[TTrigger]reflect.Type
+
+	// triggerAliases maps each alias registered with AliasTriggers to its canonical trigger.
+	triggerAliases map[TTrigger]TTrigger
+
+	// precompiledDispatch enables the dispatch table; see SetPrecompiledDispatch.
+	precompiledDispatch atomic.Bool
+
+	// dispatch is the dispatch table for the configuration version it records, built on demand.
+	dispatch atomic.Pointer[dispatchTable[TState, TTrigger]]
+
+	// dispatchMutex serializes changes of precompiledDispatch and dispatch, which fires load without it.
+	dispatchMutex sync.Mutex
+
+	// logger receives transitions, guard rejections and action errors; see WithLogger.
+	logger *slog.Logger
+
+	// reverseExitOrder makes exit actions run in reverse registration order; see SetReverseExitOrder.
+	reverseExitOrder atomic.Bool
+
+	// processing counts the triggers being processed, including nested ones; configuration panics while it is set.
+	processing atomic.Int32
+
+	// enteredAt is when the current state was entered, as an offset from clockEpoch; see TimeInState.
+	enteredAt atomic.Int64
+
+	// clock is the source of time; see SetClock.
+	clock Clock
+
+	// clockEpoch is the time of clock when it was set, which enteredAt is measured from.
+	clockEpoch time.Time
+
+	// hasTicks is set once any state configures a tick; until then there are no tickers to update.
+	hasTicks atomic.Bool
+
+	// tickMutex guards tickers.
+	tickMutex sync.Mutex
+
+	// tickers holds the running PermitTick timers of the active states.
+	tickers map[TState][]*tickRunner
+
+	// eventLogMutex guards eventLog.
+	eventLogMutex sync.Mutex
+
+	// eventLog holds the processed triggers; nil unless EnableEventLog was called.
+	eventLog []LoggedEvent[TTrigger]
+
+	// eventLogEnabled is set by EnableEventLog, so that triggers are not logged under eventLogMutex otherwise.
+	eventLogEnabled atomic.Bool
+
+	// disabledMutex guards disabledTriggers.
+	disabledMutex sync.RWMutex
+
+	// disabledTriggers holds the triggers disabled with DisableTrigger.
+	disabledTriggers map[TTrigger]struct{}
+
+	// anyDisabled is set while disabledTriggers is not empty, so that fires skip disabledMutex otherwise.
+	anyDisabled atomic.Bool
+
+	// replayMode makes fired triggers change state without running actions or raising events.
+	replayMode bool
+
+	// sealed is set by Builder.Build and makes configuration panic; see panicIfSealed.
+	sealed bool
+
+	// coverage records the transitions taken since EnableCoverage; nil while disabled.
+	coverage atomic.Pointer[coverageRecorder[TState, TTrigger]]
+
+	// argDecoders holds the args decoder registered for each trigger with SetArgDecoder.
+	argDecoders map[TTrigger]func(any) (any, error)
+
+	// configVersion is bumped whenever the configuration of any state changes.
+	configVersion atomic.Uint64
+
+	// infoCache holds the last result of GetInfo, valid while infoCacheVersion matches configVersion.
+	infoCache        *StateMachineInfo
+	infoCacheVersion uint64
+	infoMutex        sync.Mutex
 }
 
 // OnTransitionedEvent handles transition event callbacks.
 type OnTransitionedEvent[TState, TTrigger comparable] struct {
-	handlers []func(Transition[TState, TTrigger])
+	handlers []func(context.Context, Transition[TState, TTrigger])
 	mutex    sync.RWMutex
 }
 
@@ -78,6 +293,15 @@ func NewOnTransitionedEvent[TState, TTrigger comparable]() *OnTransitionedEvent[
 
 // Register adds a handler to the event.
 func (e *OnTransitionedEvent[TState, TTrigger]) Register(handler func(Transition[TState, TTrigger])) {
+	e.RegisterCtx(func(_ context.Context, t Transition[TState, TTrigger]) {
+		handler(t)
+	})
+}
+
+// RegisterCtx adds a handler that also receives the context of the fired trigger.
+func (e *OnTransitionedEvent[TState, TTrigger]) RegisterCtx(
+	handler func(context.Context, Transition[TState, TTrigger]),
+) {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 	e.handlers = append(e.handlers, handler)
@@ -90,12 +314,20 @@ func (e *OnTransitionedEvent[TState, TTrigger]) UnregisterAll() {
 	e.handlers = nil
 }
 
-// Invoke calls all registered handlers.
+// Invoke calls all registered handlers with a background context.
 func (e *OnTransitionedEvent[TState, TTrigger]) Invoke(transition Transition[TState, TTrigger]) {
+	e.InvokeCtx(context.Background(), transition)
+}
+
+// InvokeCtx calls all registered handlers with the given context.
+func (e *OnTransitionedEvent[TState, TTrigger]) InvokeCtx(
+	ctx context.Context,
+	transition Transition[TState, TTrigger],
+) {
 	e.mutex.RLock()
 	defer e.mutex.RUnlock()
 	for _, handler := range e.handlers {
-		handler(transition)
+		handler(ctx, transition)
 	}
 }
 
@@ -121,12 +353,27 @@ func NewStateMachine[TState, TTrigger comparable](initialState TState) *StateMac
 }
 
 // NewStateMachineWithMode creates a new state machine with the specified initial state and firing mode.
+// An optional TriggerQueue replaces the default in-memory queue used in FiringQueued mode.
 func NewStateMachineWithMode[TState, TTrigger comparable](
 	initialState TState,
 	firingMode FiringMode,
+	queue ...TriggerQueue[TTrigger],
 ) *StateMachine[TState, TTrigger] {
 	sm := NewStateMachine[TState, TTrigger](initialState)
 	sm.firingMode = firingMode
+	sm.setTriggerQueue(queue)
+	return sm
+}
+
+// NewStateMachineResumed creates a state machine that resumes in currentState, for example a persisted
+// workflow, while reporting initialState as its initial state in GetInfo and the graphs built from it.
+// Like any current state, currentState must be configured before firing, or Fire returns an
+// UnconfiguredStateError.
+func NewStateMachineResumed[TState, TTrigger comparable](
+	initialState, currentState TState,
+) *StateMachine[TState, TTrigger] {
+	sm := NewStateMachine[TState, TTrigger](currentState)
+	sm.initialState = initialState
 	return sm
 }
 
@@ -135,40 +382,96 @@ func NewStateMachineWithExternalStorage[TState, TTrigger comparable](
 	stateAccessor func() TState,
 	stateMutator func(TState),
 ) *StateMachine[TState, TTrigger] {
-	return &StateMachine[TState, TTrigger]{
+	sm := &StateMachine[TState, TTrigger]{
 		stateAccessor:              stateAccessor,
 		stateMutator:               stateMutator,
-		stateRepresentations:       make(map[TState]*StateRepresentation[TState, TTrigger]),
 		onTransitionedEvent:        NewOnTransitionedEvent[TState, TTrigger](),
 		onTransitionCompletedEvent: NewOnTransitionedEvent[TState, TTrigger](),
 		firingMode:                 FiringImmediate,
+		eventQueue:                 &sliceTriggerQueue[TTrigger]{},
 		initialState:               stateAccessor(),
 	}
+	sm.SetClock(systemClock{})
+	sm.maxImmediateDepth.Store(DefaultMaxImmediateDepth)
+	return sm
 }
 
 // NewStateMachineWithExternalStorageAndMode creates a new state machine with external state storage
 // and the specified firing mode.
+// An optional TriggerQueue replaces the default in-memory queue used in FiringQueued mode.
 func NewStateMachineWithExternalStorageAndMode[TState, TTrigger comparable](
 	stateAccessor func() TState,
 	stateMutator func(TState),
 	firingMode FiringMode,
+	queue ...TriggerQueue[TTrigger],
 ) *StateMachine[TState, TTrigger] {
 	sm := NewStateMachineWithExternalStorage[TState, TTrigger](stateAccessor, stateMutator)
 	sm.firingMode = firingMode
+	sm.setTriggerQueue(queue)
 	return sm
 }
 
+// setTriggerQueue installs the first of the optional queues, if any.
+func (sm *StateMachine[TState, TTrigger]) setTriggerQueue(queue []TriggerQueue[TTrigger]) {
+	if len(queue) > 0 && queue[0] != nil {
+		sm.eventQueue = queue[0]
+	}
+}
+
+// Clone creates a new state machine in the given initial state that shares this machine's
+// state configuration, firing mode and options. The clone has its own state storage, in-memory event queue
+// and activation status, and starts without any registered callbacks (OnTransitioned,
+// OnTransitionCompleted, OnTransitionTimed, OnTransitioning, OnTerminalState, OnAnyEntry, OnAnyExit, OnGuardEvaluated,
+// OnGuardError, OnError, OnUnhandledTrigger, OnUnhandledTriggerHandler).
+//
+// The clone uses the same history depth, starting with an empty history, and carries no values
+// attached with WithValue.
+//
+// Configuration is shared rather than copied, which makes cloning cheap. Changing the
+// configuration of existing states on either machine after cloning is unsupported.
+func (sm *StateMachine[TState, TTrigger]) Clone(initialState TState) *StateMachine[TState, TTrigger] {
+	clone := NewStateMachineWithMode[TState, TTrigger](initialState, sm.firingMode)
+	clone.emitCompletedForNonTransitions = sm.emitCompletedForNonTransitions
+	clone.emitInitialTransitionEvents = sm.emitInitialTransitionEvents
+	clone.permitIdentityAsReentry = sm.permitIdentityAsReentry
+	clone.unhandledReturnsError = sm.unhandledReturnsError
+	clone.maxImmediateDepth.Store(sm.maxImmediateDepth.Load())
+	clone.reverseExitOrder.Store(sm.reverseExitOrder.Load())
+	clone.hasTicks.Store(sm.hasTicks.Load())
+	clone.triggerParameters = maps.Clone(sm.triggerParameters)
+	clone.triggerAliases = maps.Clone(sm.triggerAliases)
+	clone.logger = sm.logger
+	clone.SetPrecompiledDispatch(sm.precompiledDispatch.Load())
+	clone.argDecoders = maps.Clone(sm.argDecoders)
+	clone.guardReasonFormatter = sm.guardReasonFormatter
+	clone.SetClock(sm.clock)
+	clone.historyDepth = sm.historyDepth
+	clone.stateRepresentations.Store(sm.stateRepresentations.Load())
+	return clone
+}
+
 // State returns the current state.
 func (sm *StateMachine[TState, TTrigger]) State() TState {
 	return sm.stateAccessor()
 }
 
 // Configure begins configuration of a state.
+// It panics if the machine was created by Builder.Build, whose configuration is final, or if it is
+// called while the machine processes a trigger, for example from an entry action; configuring a state
+// through a StateNode obtained earlier panics in that case too.
 func (sm *StateMachine[TState, TTrigger]) Configure(state TState) *StateNode[TState, TTrigger] {
-	return NewStateNode(
+	sm.panicIfSealed("Configure")
+	if sm.processing.Load() > 0 {
+		panic(fmt.Sprintf(
+			"stateless: Configure(%v) called while the state machine is processing a trigger; "+
+				"configure states before firing, not from actions or guards", state))
+	}
+	node := NewStateNode(
 		sm.getRepresentation(state),
 		sm.getRepresentation,
 	)
+	node.identityAsReentry = func() bool { return sm.permitIdentityAsReentry }
+	return node
 }
 
 // Fire fires a trigger with optional args (should be a struct or nil).
@@ -178,81 +481,333 @@ func (sm *StateMachine[TState, TTrigger]) Fire(tr TTrigger, args any) error {
 
 // FireCtx fires a trigger with a context and optional args.
 func (sm *StateMachine[TState, TTrigger]) FireCtx(ctx context.Context, tr TTrigger, args any) error {
-	sm.mutex.Lock()
+	_, err := sm.fire(ctx, tr, args)
+	return err
+}
+
+// FireResult fires a trigger with a context and optional args, and returns the transition that occurred.
+// The destination of the returned transition is the final state, after any initial transitions;
+// IsReentry and HasInitialTransition report whether the trigger reentered its source state and
+// whether initial transitions were applied. Internal transitions and ignored triggers (including
+// unhandled triggers swallowed by OnUnhandledTrigger) are reported with Kind set to TransitionInternal
+// or TransitionIgnored respectively.
+// In queued mode, if another trigger is being processed the trigger is only enqueued
+// and the zero Transition is returned.
+func (sm *StateMachine[TState, TTrigger]) FireResult(
+	ctx context.Context,
+	tr TTrigger,
+	args any,
+) (Transition[TState, TTrigger], error) {
+	return sm.fire(ctx, tr, args)
+}
+
+// FireByName fires the trigger whose formatted value (fmt "%v") equals triggerName.
+// The name is resolved against the triggers configured in the current state and its superstates,
+// whether or not their guards currently pass. An ArgumentError is returned if no configured
+// trigger has that name, or if several distinct triggers format to it.
+func (sm *StateMachine[TState, TTrigger]) FireByName(ctx context.Context, triggerName string, args any) error {
+	var matches []TTrigger
+	for rep := sm.currentRepresentation(); rep != nil; rep = rep.Superstate() {
+		for trigger := range rep.TriggerBehaviours() {
+			if fmt.Sprintf("%v", trigger) == triggerName && !slices.Contains(matches, trigger) {
+				matches = append(matches, trigger)
+			}
+		}
+	}
 
+	switch len(matches) {
+	case 0:
+		return &ArgumentError{
+			ParamName: "triggerName",
+			Message:   fmt.Sprintf("no trigger named '%s' is configured for state '%v'", triggerName, sm.State()),
+		}
+	case 1:
+		return sm.FireCtx(ctx, matches[0], args)
+	default:
+		return &ArgumentError{
+			ParamName: "triggerName",
+			Message: fmt.Sprintf(
+				"trigger name '%s' is ambiguous in state '%v': %d triggers share it",
+				triggerName, sm.State(), len(matches),
+			),
+		}
+	}
+}
+
+// FireOnly fires the only trigger permitted in the current state with args, for example to advance
+// a linear flow without naming its triggers. Permitted triggers are those GetPermittedTriggers returns
+// for args, including ignored ones. If none or several are permitted, nothing is fired and an
+// InvalidOperationError listing them is returned.
+func (sm *StateMachine[TState, TTrigger]) FireOnly(ctx context.Context, args any) error {
+	permitted := sm.GetPermittedTriggers(withoutGuardObserver(ctx), args)
+	if len(permitted) == 1 {
+		return sm.FireCtx(ctx, permitted[0], args)
+	}
+
+	sortValues(permitted)
+	message := fmt.Sprintf("no trigger is permitted in state '%v'", sm.State())
+	if len(permitted) > 1 {
+		message = fmt.Sprintf("%d triggers are permitted in state '%v', expected exactly one: %v",
+			len(permitted), sm.State(), permitted)
+	}
+	return &InvalidOperationError{Message: message}
+}
+
+// fire processes a trigger according to the firing mode and returns the resulting transition.
+func (sm *StateMachine[TState, TTrigger]) fire(
+	ctx context.Context,
+	tr TTrigger,
+	args any,
+) (Transition[TState, TTrigger], error) {
 	if sm.firingMode == FiringQueued {
-		sm.eventQueue = append(sm.eventQueue, queuedEvent[TState, TTrigger]{
-			trigger: tr,
-			args:    args,
-			ctx:     ctx,
+		sm.mutex.Lock()
+		sm.eventQueue.Push(QueuedEvent[TTrigger]{
+			Event:   Event[TTrigger]{Trigger: tr, Args: args},
+			Context: ctx,
 		})
 
 		if sm.firing {
 			sm.mutex.Unlock()
-			return nil
+			return Transition[TState, TTrigger]{}, nil
 		}
 
 		sm.firing = true
 		sm.mutex.Unlock()
 
-		for {
+		// The queue is empty when nothing is firing, so the first event processed is our own
+		return sm.processQueue()
+	}
+
+	var transition Transition[TState, TTrigger]
+	err := sm.runImmediate(func() error {
+		// Triggers fired from actions in immediate mode recurse; bound the depth instead of overflowing the stack
+		sm.immediateDepth++
+		defer func() { sm.immediateDepth-- }()
+		if limit := sm.maxImmediateDepth.Load(); limit > 0 && sm.immediateDepth > limit {
+			return &MaxDepthExceededError{Trigger: tr, MaxDepth: int(limit)}
+		}
+
+		var err error
+		transition, err = sm.processTrigger(ctx, tr, args)
+		return err
+	})
+	return transition, err
+}
+
+// processQueue processes queued events until the queue is empty and returns the transition
+// of the first one. The caller must have set the firing flag.
+func (sm *StateMachine[TState, TTrigger]) processQueue() (Transition[TState, TTrigger], error) {
+	var (
+		result    Transition[TState, TTrigger]
+		processed bool
+	)
+	for {
+		sm.mutex.Lock()
+		if sm.shuttingDown.Load() && sm.eventQueue.Len() > 0 {
+			sm.stopFiring(ErrShuttingDown)
+			sm.mutex.Unlock()
+			return Transition[TState, TTrigger]{}, ErrShuttingDown
+		}
+		event, ok := sm.eventQueue.Pop()
+		if !ok {
+			sm.stopFiring(nil)
+			sm.mutex.Unlock()
+			return result, nil
+		}
+		sm.mutex.Unlock()
+
+		ctx := context.WithValue(event.Context, drainingQueueKey{}, any(sm))
+		transition, err := sm.processTrigger(ctx, event.Trigger, event.Args)
+		if err != nil {
 			sm.mutex.Lock()
-			if len(sm.eventQueue) == 0 {
-				sm.firing = false
-				sm.mutex.Unlock()
-				return nil
-			}
-			event := sm.eventQueue[0]
-			sm.eventQueue = sm.eventQueue[1:]
+			sm.stopFiring(err)
 			sm.mutex.Unlock()
+			return Transition[TState, TTrigger]{}, err
+		}
+		if !processed {
+			result = transition
+			processed = true
+		}
+	}
+}
 
-			if err := sm.internalFire(event.ctx, event.trigger, event.args); err != nil {
-				sm.mutex.Lock()
-				sm.firing = false
-				sm.mutex.Unlock()
-				return err
-			}
+// internalFire processes a single trigger.
+func (sm *StateMachine[TState, TTrigger]) internalFire(
+	ctx context.Context,
+	tr TTrigger,
+	args any,
+) (Transition[TState, TTrigger], error) {
+	tr, args, err := sm.prepareTrigger(ctx, tr, args)
+	if err != nil {
+		return Transition[TState, TTrigger]{}, err
+	}
+
+	source := sm.State()
+	representation, ok := sm.lookupRepresentation(source)
+	if !ok {
+		return Transition[TState, TTrigger]{}, &UnconfiguredStateError{State: source}
+	}
+
+	// A result is collected for the fired trigger only, not for those fired from its actions
+	ctx = withTransitionResult(ctx)
+
+	// A report is collected for the fired trigger only, not for those fired from its actions
+	recorder := fireRecorderFrom[TState, TTrigger](ctx)
+	if recorder != nil {
+		ctx = context.WithValue(ctx, fireReportKey{}, (*fireRecorder[TState, TTrigger])(nil))
+		recorder.start()
+	}
+	ctx = sm.observeGuards(ctx, source, tr, recorder)
+
+	// Try to find a handler for the trigger; disabled triggers are unhandled
+	var result *TriggerBehaviourResult[TState, TTrigger]
+	if !sm.IsTriggerDisabled(tr) {
+		result = sm.findHandler(ctx, representation, tr, args)
+	}
+
+	if recorder != nil && result != nil && result.Handler != nil && result.Owner != nil {
+		recorder.record(func(report *FireReport[TState, TTrigger]) {
+			report.HandlingState, report.Handled = result.Owner.UnderlyingState(), true
+		})
+	}
+
+	// Check for unexpected errors during guard evaluation (not guard rejections)
+	if result != nil && result.UnexpectedError != nil {
+		for _, handler := range sm.guardErrorHandlers {
+			handler(ctx, tr, result.UnexpectedError)
 		}
+		return Transition[TState, TTrigger]{}, sm.handleActionError(
+			ctx,
+			NewTransition(source, source, tr, args),
+			PhaseGuard,
+			result.UnexpectedError,
+		)
 	}
 
-	sm.mutex.Unlock()
-	return sm.internalFire(ctx, tr, args)
+	if result == nil || result.Handler == nil {
+		return sm.fireUnhandled(ctx, source, tr, args, result, representation)
+	}
+	return sm.executeBehaviour(ctx, source, tr, args, result, representation)
 }
 
-// internalFire processes a single trigger.
-func (sm *StateMachine[TState, TTrigger]) internalFire(ctx context.Context, tr TTrigger, args any) error {
+// prepareTrigger checks that ctx is not done, resolves the alias of tr and decodes and validates
+// args, returning the trigger and args to process.
+func (sm *StateMachine[TState, TTrigger]) prepareTrigger(
+	ctx context.Context,
+	tr TTrigger,
+	args any,
+) (TTrigger, any, error) {
 	// Check for cancellation
 	select {
 	case <-ctx.Done():
-		return ctx.Err()
+		return tr, args, ctx.Err()
 	default:
 	}
 
-	source := sm.State()
-	representation := sm.getRepresentation(source)
+	tr = sm.canonicalTrigger(tr)
+	args, err := sm.decodeTriggerArgs(tr, args)
+	if err != nil {
+		return tr, args, err
+	}
+	if err := sm.validateTriggerArgs(tr, args); err != nil {
+		return tr, args, err
+	}
+	return tr, args, nil
+}
 
-	// Try to find a handler for the trigger
-	result := representation.TryFindHandler(ctx, tr, args)
+// observeGuards returns ctx with a guard observer that logs rejected guards, records retries in
+// the fire report and calls the OnGuardEvaluated handlers, or ctx itself if none of them is set.
+func (sm *StateMachine[TState, TTrigger]) observeGuards(
+	ctx context.Context,
+	source TState,
+	tr TTrigger,
+	recorder *fireRecorder[TState, TTrigger],
+) context.Context {
+	handlers := sm.guardEvaluatedHandlers
+	if len(handlers) == 0 && sm.logger == nil && recorder == nil {
+		return ctx
+	}
 
-	// Check for unexpected errors during guard evaluation (not guard rejections)
-	if result != nil && result.UnexpectedError != nil {
-		return result.UnexpectedError
+	return withGuardObserver(ctx, func(description string, passed bool, err error) {
+		if !passed && sm.logger != nil {
+			sm.logGuardRejection(ctx, source, tr, description, err)
+		}
+		var retry *RetryError
+		if recorder != nil && errors.As(err, &retry) {
+			recorder.record(func(report *FireReport[TState, TTrigger]) {
+				report.Retries = append(report.Retries, retry)
+			})
+		}
+		for _, handler := range handlers {
+			handler(tr, description, passed, err)
+		}
+	})
+}
+
+// fireUnhandled processes a trigger for which no behaviour is permitted in the source state;
+// result is nil if the trigger is disabled.
+func (sm *StateMachine[TState, TTrigger]) fireUnhandled(
+	ctx context.Context,
+	source TState,
+	tr TTrigger,
+	args any,
+	result *TriggerBehaviourResult[TState, TTrigger],
+	representation *StateRepresentation[TState, TTrigger],
+) (Transition[TState, TTrigger], error) {
+	// Check for ambiguous handlers (configuration error)
+	if result != nil && result.MultipleHandlersFound {
+		return Transition[TState, TTrigger]{}, &InvalidOperationError{
+			Message: fmt.Sprintf(
+				"multiple permitted transitions are configured from state '%v' for trigger '%v'; guards should be mutually exclusive",
+				source,
+				tr,
+			),
+		}
 	}
 
-	if result == nil || result.Handler == nil {
-		// Check for ambiguous handlers (configuration error)
-		if result != nil && result.MultipleHandlersFound {
-			return &InvalidOperationError{
-				Message: fmt.Sprintf(
-					"multiple permitted transitions are configured from state '%v' for trigger '%v'; guards should be mutually exclusive",
-					source,
-					tr,
-				),
+	ignored := ignoredTransition(source, tr, args)
+
+	// Ticks and linked triggers that nothing handles are dropped silently
+	if ignoresUnhandled(ctx) {
+		return sm.completeNonTransition(ignored), nil
+	}
+	if result != nil {
+		// A guard asking to retry later takes precedence over reporting the trigger as unhandled
+		for _, unmet := range result.UnmetGuardConditions {
+			if IsRetry(unmet) {
+				return Transition[TState, TTrigger]{}, unmet
 			}
 		}
-		return sm.handleUnhandledTrigger(ctx, source, tr, result)
+		// Exhaustive branches are declared to always match, so none matching is an error
+		if err := noBranchMatched(source, tr, result.UnmetGuardConditions); err != nil {
+			return Transition[TState, TTrigger]{}, err
+		}
+	}
+	if sm.unhandledTriggerHandler != nil {
+		dst, ok, err := sm.unhandledTriggerHandler(ctx, source, tr, args)
+		if err != nil {
+			return Transition[TState, TTrigger]{}, err
+		}
+		if ok {
+			return sm.executeTransition(ctx, source, dst, tr, args, representation)
+		}
+		return sm.completeNonTransition(ignored), nil
+	}
+	if err := sm.handleUnhandledTrigger(ctx, source, tr, result); err != nil {
+		return Transition[TState, TTrigger]{}, err
 	}
+	return sm.completeNonTransition(ignored), nil
+}
 
+// executeBehaviour processes a trigger with the behaviour result found for it.
+func (sm *StateMachine[TState, TTrigger]) executeBehaviour(
+	ctx context.Context,
+	source TState,
+	tr TTrigger,
+	args any,
+	result *TriggerBehaviourResult[TState, TTrigger],
+	representation *StateRepresentation[TState, TTrigger],
+) (Transition[TState, TTrigger], error) {
 	handler := result.Handler
 
 	// Handle different types of trigger behaviours
@@ -261,35 +816,101 @@ func (sm *StateMachine[TState, TTrigger]) internalFire(ctx context.Context, tr T
 		// If a trigger was found on a superstate that would cause unintended reentry, don't trigger.
 		// This can happen when a superstate defines a transition to the current substate.
 		if source == behaviour.Destination {
-			return nil
+			return sm.completeNonTransition(ignoredTransition(source, tr, args)), nil
+		}
+		transition, err := sm.executeTransition(ctx, source, behaviour.Destination, tr, args, representation)
+		if err == nil && result.Owner != nil {
+			sm.recordCoverage(result.Owner.UnderlyingState(), tr, behaviour.Destination)
 		}
-		return sm.executeTransition(ctx, source, behaviour.Destination, tr, args, representation)
+		return transition, err
 
 	case *ReentryTriggerBehaviour[TState, TTrigger]:
-		return sm.executeTransition(ctx, source, behaviour.Destination, tr, args, representation)
+		transition, err := sm.executeTransition(ctx, source, behaviour.Destination, tr, args, representation)
+		if err == nil && result.Owner != nil {
+			sm.recordCoverage(result.Owner.UnderlyingState(), tr, behaviour.Destination)
+		}
+		return transition, err
 
 	case *DynamicTriggerBehaviour[TState, TTrigger]:
-		destination, err := behaviour.GetDestinationState(ctx, args)
+		var destination TState
+		var err error
+		if behaviour.back {
+			destination, err = sm.backDestination()
+		} else {
+			destination, err = behaviour.GetDestinationState(ctx, args)
+		}
 		if err != nil {
-			return err
+			return Transition[TState, TTrigger]{}, err
+		}
+		transition := NewTransition(source, destination, tr, args)
+		transition.reentry = behaviour.reentry && representation.IsIncludedIn(destination)
+		return sm.performTransition(ctx, transition, representation)
+
+	case *InternalOrTransitionTriggerBehaviour[TState, TTrigger]:
+		destination, ok, err := behaviour.SelectDestination(ctx, args)
+		if err != nil {
+			return Transition[TState, TTrigger]{}, err
+		}
+		if ok {
+			return sm.executeTransition(ctx, source, destination, tr, args, representation)
 		}
-		return sm.executeTransition(ctx, source, destination, tr, args, representation)
+		return sm.executeInternal(ctx, source, tr, args, behaviour.Execute)
 
 	case *IgnoredTriggerBehaviour[TState, TTrigger]:
 		// Trigger is ignored, do nothing
-		return nil
+		return sm.completeNonTransition(ignoredTransition(source, tr, args)), nil
 
 	case *InternalTriggerBehaviour[TState, TTrigger]:
-		transition := NewTransition(source, source, tr, args)
-		// Internal transitions don't fire transition events
-		return behaviour.Execute(ctx, transition)
+		return sm.executeInternal(ctx, source, tr, args, behaviour.Execute)
 
 	default:
-		return &InvalidOperationError{Message: fmt.Sprintf("unknown trigger behaviour type: %T", handler)}
+		return Transition[TState, TTrigger]{}, &InvalidOperationError{
+			Message: fmt.Sprintf("unknown trigger behaviour type: %T", handler),
+		}
+	}
+}
+
+// executeInternal runs the action of an internal transition in the source state. Internal
+// transitions don't fire transition events.
+func (sm *StateMachine[TState, TTrigger]) executeInternal(
+	ctx context.Context,
+	source TState,
+	tr TTrigger,
+	args any,
+	execute func(ctx context.Context, t Transition[TState, TTrigger]) error,
+) (Transition[TState, TTrigger], error) {
+	transition := NewTransition(source, source, tr, args)
+	transition.Kind = TransitionInternal
+	transition.result = transitionResultFrom(ctx)
+	if sm.replayMode {
+		return transition, nil
 	}
+	if err := execute(ctx, transition); err != nil {
+		return Transition[TState, TTrigger]{}, sm.handleActionError(ctx, transition, PhaseInternal, err)
+	}
+	return sm.completeNonTransition(transition), nil
+}
+
+// ignoredTransition returns the transition reported when a trigger does not change state.
+func ignoredTransition[TState, TTrigger comparable](source TState, tr TTrigger, args any) Transition[TState, TTrigger] {
+	transition := NewTransition(source, source, tr, args)
+	transition.Kind = TransitionIgnored
+	return transition
+}
+
+// completeNonTransition raises OnTransitionCompleted for an internal or ignored transition
+// if SetEmitCompletedForNonTransitions is enabled, and returns the transition.
+func (sm *StateMachine[TState, TTrigger]) completeNonTransition(
+	transition Transition[TState, TTrigger],
+) Transition[TState, TTrigger] {
+	if sm.emitCompletedForNonTransitions && !sm.replayMode {
+		sm.onTransitionCompletedEvent.Invoke(transition)
+	}
+	return transition
 }
 
 // executeTransition handles the common transition logic for all transition types.
+// It returns the completed transition, whose destination is the final state after initial transitions.
 func (sm *StateMachine[TState, TTrigger]) executeTransition(
 	ctx context.Context,
 	src TState,
@@ -297,79 +918,155 @@ func (sm *StateMachine[TState, TTrigger]) executeTransition(
 	tr TTrigger,
 	args any,
 	sourceRepresentation *StateRepresentation[TState, TTrigger],
-) error {
-	transition := NewTransition(src, dst, tr, args)
+) (Transition[TState, TTrigger], error) {
+	return sm.performTransition(ctx, NewTransition(src, dst, tr, args), sourceRepresentation)
+}
+
+// performTransition is executeTransition for a transition already built, such as the reentry of a
+// superstate.
+func (sm *StateMachine[TState, TTrigger]) performTransition(
+	ctx context.Context,
+	transition Transition[TState, TTrigger],
+	sourceRepresentation *StateRepresentation[TState, TTrigger],
+) (Transition[TState, TTrigger], error) {
+	dst, tr, args := transition.Destination, transition.Trigger, transition.Args
+	transition.result = transitionResultFrom(ctx)
+
+	// In replay mode only the state changes
+	if sm.replayMode {
+		sm.recordDeparture(sourceRepresentation, dst, true)
+		sm.setState(dst)
+		viaInitial, err := sm.handleInitialTransitions(ctx, dst, tr, args)
+		if err != nil {
+			return Transition[TState, TTrigger]{}, err
+		}
+		return completedTransition(transition, sm.State(), viaInitial), nil
+	}
+
+	// Give OnTransitioning handlers a chance to veto before anything happens
+	for _, handler := range sm.transitioningHandlers {
+		if err := handler(ctx, transition); err != nil {
+			return Transition[TState, TTrigger]{}, err
+		}
+	}
 
 	// Execute exit actions
-	if err := sourceRepresentation.Exit(ctx, transition); err != nil {
-		return err
+	if err := sourceRepresentation.exit(ctx, transition, sm.anyExitHook()); err != nil {
+		return Transition[TState, TTrigger]{}, sm.handleActionError(ctx, transition, PhaseExit, err)
 	}
 
 	// Update state
-	sm.stateMutator(dst)
+	sm.recordDeparture(sourceRepresentation, dst, true)
+	sm.setState(dst)
 
 	// Fire transition event
-	sm.onTransitionedEvent.Invoke(transition)
+	sm.onTransitionedEvent.InvokeCtx(ctx, transition)
 
 	// Execute entry actions
 	destRepresentation := sm.getRepresentation(dst)
-	if err := destRepresentation.Enter(ctx, transition); err != nil {
-		return err
+	if err := sm.enterState(ctx, destRepresentation, transition); err != nil {
+		return Transition[TState, TTrigger]{}, err
 	}
 
 	// Handle initial transition if destination has one (recursively for nested substates)
 	// Only if state hasn't changed during entry actions (in immediate mode, nested fires can change state)
+	var viaInitial bool
 	if sm.State() == dst {
-		if err := sm.handleInitialTransitions(ctx, dst, tr, args); err != nil {
-			return err
+		var err error
+		if viaInitial, err = sm.handleInitialTransitions(ctx, dst, tr, args); err != nil {
+			return Transition[TState, TTrigger]{}, err
 		}
 	}
 
 	// Fire transition completed event
-	finalTransition := NewTransition(src, sm.State(), tr, args)
+	finalTransition := completedTransition(transition, sm.State(), viaInitial)
+	finalTransition.result = transition.result
 	sm.onTransitionCompletedEvent.Invoke(finalTransition)
 
-	return nil
+	if len(sm.terminalStateHandlers) > 0 && sm.getRepresentation(finalTransition.Destination).IsTerminal() {
+		for _, handler := range sm.terminalStateHandlers {
+			handler(ctx, finalTransition)
+		}
+	}
+
+	return finalTransition, nil
 }
 
-// handleInitialTransitions handles initial transitions recursively for nested substates.
+// completedTransition returns the transition reported once transition was taken and the machine
+// settled in final, recording whether it was a reentry and applied initial transitions.
+func completedTransition[TState, TTrigger comparable](
+	transition Transition[TState, TTrigger],
+	final TState,
+	viaInitial bool,
+) Transition[TState, TTrigger] {
+	completed := NewTransition(transition.Source, final, transition.Trigger, transition.Args)
+	completed.completed = true
+	completed.reentry = transition.IsReentry()
+	completed.viaInitial = viaInitial
+	return completed
+}
+
+// handleInitialTransitions handles initial transitions recursively for nested substates, and
+// reports whether any was applied.
 func (sm *StateMachine[TState, TTrigger]) handleInitialTransitions(
 	ctx context.Context,
 	dst TState,
 	tr TTrigger,
 	args any,
-) error {
+) (bool, error) {
 	currentState := dst
 	for {
 		currentRepresentation := sm.getRepresentation(currentState)
-		if !currentRepresentation.HasInitialTransition() {
+		initialTarget, ok, err := currentRepresentation.ResolveInitialTransition(ctx, args)
+		if err != nil {
+			return currentState != dst, sm.handleActionError(
+				ctx,
+				NewTransition(currentState, currentState, tr, args),
+				PhaseGuard,
+				err,
+			)
+		}
+		if !ok {
 			break
 		}
 
-		initialTarget := currentRepresentation.InitialTransitionTarget()
-
 		// Validate that initial target is a substate
 		initialTargetRepresentation := sm.getRepresentation(initialTarget)
 		if !initialTargetRepresentation.IsSubstateOf(currentState) {
-			return fmt.Errorf("initial transition target '%v' is not a substate of '%v'", initialTarget, currentState)
+			return currentState != dst, fmt.Errorf(
+				"initial transition target '%v' is not a substate of '%v'",
+				initialTarget,
+				currentState,
+			)
 		}
 
 		initialTransition := NewInitialTransition(currentState, initialTarget, tr, args)
+		initialTransition.result = transitionResultFrom(ctx)
+
+		if sm.replayMode {
+			sm.setState(initialTarget)
+			currentState = initialTarget
+			continue
+		}
 
 		// Fire transition event for initial transition
-		sm.onTransitionedEvent.Invoke(initialTransition)
+		sm.onTransitionedEvent.InvokeCtx(ctx, initialTransition)
 
 		// Update state to initial target
-		sm.stateMutator(initialTarget)
+		sm.setState(initialTarget)
 
 		// Execute entry actions for initial target
-		if err := initialTargetRepresentation.ExecuteEntryActions(ctx, initialTransition); err != nil {
-			return err
+		if err := sm.enterState(ctx, initialTargetRepresentation, initialTransition); err != nil {
+			return true, err
+		}
+
+		if sm.emitInitialTransitionEvents {
+			sm.onTransitionCompletedEvent.Invoke(initialTransition)
 		}
 
 		currentState = initialTarget
 	}
-	return nil
+	return currentState != dst, nil
 }
 
 // handleUnhandledTrigger handles a trigger that has no valid handler.
@@ -381,17 +1078,20 @@ func (sm *StateMachine[TState, TTrigger]) handleUnhandledTrigger(
 ) error {
 	var unmetGuards []error
 	if result != nil {
-		unmetGuards = result.UnmetGuardConditions
+		unmetGuards = sm.formatGuardReasons(tr, result.UnmetGuardConditions)
 	}
 
 	if sm.unhandledTriggerAction != nil {
 		sm.unhandledTriggerAction(state, tr, unmetGuards)
+		if sm.unhandledReturnsError {
+			return fmt.Errorf("%w: trigger '%v' in state '%v'", ErrTriggerUnhandled, tr, state)
+		}
 		return nil
 	}
 
 	// Get permitted triggers for the error message
 	representation := sm.getRepresentation(state)
-	permittedTriggers := representation.GetPermittedTriggers(ctx, nil)
+	permittedTriggers := sm.withoutDisabledTriggers(representation.GetPermittedTriggers(withoutGuardObserver(ctx), nil))
 
 	// Convert to any slice for the error
 	permitted := make([]any, len(permittedTriggers))
@@ -407,24 +1107,257 @@ func (sm *StateMachine[TState, TTrigger]) handleUnhandledTrigger(
 	}
 }
 
+// SetGuardReasonFormatter sets a function that rewrites the reason of each unmet guard reported for
+// an unhandled trigger, for example to localize it. It receives the trigger and the guard's error
+// message and returns the message to report. The rewritten errors are passed to OnUnhandledTrigger
+// and stored in InvalidTransitionError.UnmetGuards; they wrap the original errors, so IsGuardRejection
+// and errors.As keep working. By default reasons are reported unchanged.
+func (sm *StateMachine[TState, TTrigger]) SetGuardReasonFormatter(
+	format func(trigger TTrigger, description string) string,
+) {
+	sm.panicIfSealed("SetGuardReasonFormatter")
+	sm.guardReasonFormatter = format
+}
+
+// formatGuardReasons applies the guard reason formatter, if any, to unmet guard errors.
+func (sm *StateMachine[TState, TTrigger]) formatGuardReasons(tr TTrigger, unmet []error) []error {
+	if sm.guardReasonFormatter == nil || len(unmet) == 0 {
+		return unmet
+	}
+	formatted := make([]error, len(unmet))
+	for i, err := range unmet {
+		formatted[i] = &formattedGuardError{reason: sm.guardReasonFormatter(tr, err.Error()), err: err}
+	}
+	return formatted
+}
+
 // OnUnhandledTrigger registers a callback that will be called when a trigger is fired
-// but no valid transition exists.
+// but no valid transition exists. Fire then returns nil, unless SetUnhandledReturnsError is enabled.
 func (sm *StateMachine[TState, TTrigger]) OnUnhandledTrigger(
 	action func(state TState, trigger TTrigger, unmetGuards []error),
 ) {
 	sm.unhandledTriggerAction = action
 }
 
+// OnTerminalState registers a callback that will be called when a transition ends in a terminal state,
+// one that no trigger can leave (see StateRepresentation.IsTerminal). It runs after all entry actions,
+// initial transitions and OnTransitionCompleted callbacks, with the completed transition, so that
+// resources can be released once the machine has reached a final state.
+func (sm *StateMachine[TState, TTrigger]) OnTerminalState(
+	handler func(ctx context.Context, t Transition[TState, TTrigger]),
+) {
+	sm.terminalStateHandlers = append(sm.terminalStateHandlers, handler)
+}
+
+// UnhandledTriggerHandler decides how to handle a trigger that no state in the current hierarchy handles.
+// Returning (dst, true, nil) transitions to dst, (_, false, nil) ignores the trigger, and a non-nil
+// error is returned from Fire.
+type UnhandledTriggerHandler[TState, TTrigger comparable] func(
+	ctx context.Context,
+	state TState,
+	trigger TTrigger,
+	args any,
+) (TState, bool, error)
+
+// OnUnhandledTriggerHandler registers a handler that can route an unhandled trigger to a fallback state.
+// When the handler returns a destination, the machine transitions there exactly as for a regular Permit,
+// running exit and entry actions, initial transitions and transition events; transitioning to the
+// current state is a reentry. The handler takes precedence over OnUnhandledTrigger, which is not called
+// while a handler is registered. Passing nil removes the handler.
+func (sm *StateMachine[TState, TTrigger]) OnUnhandledTriggerHandler(handler UnhandledTriggerHandler[TState, TTrigger]) {
+	sm.unhandledTriggerHandler = handler
+}
+
+// OnError registers a handler that will be called when an entry, exit, internal, activation
+// or deactivation action, or a guard, returns an unexpected error. The handler is invoked
+// right before the error propagates to the caller. The error is still returned from Fire
+// unless the handler returns a non-nil replacement error.
+func (sm *StateMachine[TState, TTrigger]) OnError(handler ErrorHandler[TState, TTrigger]) {
+	sm.errorHandler = handler
+}
+
+// handleActionError reports an action error to the registered error handler, if any,
+// and returns the error that should be propagated.
+func (sm *StateMachine[TState, TTrigger]) handleActionError(
+	ctx context.Context,
+	transition Transition[TState, TTrigger],
+	phase ActionPhase,
+	err error,
+) error {
+	sm.logActionError(ctx, transition, phase, err)
+	if sm.errorHandler == nil {
+		return err
+	}
+	if replacement := sm.errorHandler(ctx, transition, phase, err); replacement != nil {
+		return replacement
+	}
+	return err
+}
+
+// OnTransitioning registers a handler that will be called before a transition starts,
+// after the trigger handler has been resolved but before any exit actions run.
+// If the handler returns an error, the transition is aborted: the state does not change,
+// no exit or entry actions run, and Fire returns the error.
+// Internal transitions and ignored triggers do not invoke OnTransitioning handlers.
+func (sm *StateMachine[TState, TTrigger]) OnTransitioning(handler TransitionAction[TState, TTrigger]) {
+	sm.transitioningHandlers = append(sm.transitioningHandlers, handler)
+}
+
+// OnGuardEvaluated registers a handler told about every guard condition evaluated while a trigger
+// is fired, with the guard's description, whether it passed and the error it returned. This covers
+// the guards of the current state and of the superstates the trigger falls through to, as well as
+// guarded initial transitions, in evaluation order. Guards evaluated by queries such as CanFire
+// are not reported.
+func (sm *StateMachine[TState, TTrigger]) OnGuardEvaluated(
+	handler func(trigger TTrigger, description string, passed bool, err error),
+) {
+	sm.guardEvaluatedHandlers = append(sm.guardEvaluatedHandlers, handler)
+}
+
+// OnGuardError registers a handler told about a guard returning an error other than a rejection
+// (see Reject) or a retry request while a trigger is fired, before OnError sees it. Such an error
+// still fails the fire, which returns it as usual.
+func (sm *StateMachine[TState, TTrigger]) OnGuardError(handler func(ctx context.Context, trigger TTrigger, err error)) {
+	sm.guardErrorHandlers = append(sm.guardErrorHandlers, handler)
+}
+
+// OnAnyEntry registers an action run whenever a state is entered, after that state's own entry actions.
+// It is invoked once per entered state, superstates first, including for reentry and initial
+// transitions, with the same transition the entry actions receive. An error stops the transition
+// like a failing entry action does.
+func (sm *StateMachine[TState, TTrigger]) OnAnyEntry(act TransitionAction[TState, TTrigger]) {
+	sm.anyEntryActions = append(sm.anyEntryActions, act)
+}
+
+// OnAnyExit registers an action run whenever a state is exited, after that state's own exit actions.
+// It is invoked once per exited state, innermost first, including for reentry, with the same
+// transition the exit actions receive. An error stops the transition like a failing exit action does.
+func (sm *StateMachine[TState, TTrigger]) OnAnyExit(act TransitionAction[TState, TTrigger]) {
+	sm.anyExitActions = append(sm.anyExitActions, act)
+}
+
+// anyEntryHook returns the OnAnyEntry actions as a single action, or nil if there are none.
+func (sm *StateMachine[TState, TTrigger]) anyEntryHook() TransitionAction[TState, TTrigger] {
+	return chainActions(sm.anyEntryActions)
+}
+
+// anyExitHook returns the OnAnyExit actions as a single action, or nil if there are none.
+func (sm *StateMachine[TState, TTrigger]) anyExitHook() TransitionAction[TState, TTrigger] {
+	return chainActions(sm.anyExitActions)
+}
+
+// chainActions returns an action running the given actions in order until one fails,
+// or nil if there are none.
+func chainActions[TState, TTrigger comparable](
+	actions []TransitionAction[TState, TTrigger],
+) TransitionAction[TState, TTrigger] {
+	if len(actions) == 0 {
+		return nil
+	}
+	return func(ctx context.Context, t Transition[TState, TTrigger]) error {
+		for _, act := range actions {
+			if err := act(ctx, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 // OnTransitioned registers a callback that will be called when a transition is completed.
 func (sm *StateMachine[TState, TTrigger]) OnTransitioned(action func(Transition[TState, TTrigger])) {
 	sm.onTransitionedEvent.Register(action)
 }
 
+// OnTransitionedWith registers a callback that is called like OnTransitioned, but only for
+// transitions caused by the given trigger. The handler receives the context the trigger was fired with.
+// Transitions made by GoTo are never reported, since they have no trigger.
+func (sm *StateMachine[TState, TTrigger]) OnTransitionedWith(
+	trigger TTrigger,
+	handler func(ctx context.Context, t Transition[TState, TTrigger]),
+) {
+	sm.onTransitionedEvent.RegisterCtx(func(ctx context.Context, t Transition[TState, TTrigger]) {
+		if t.Trigger == trigger && t.Kind != TransitionForced {
+			handler(ctx, t)
+		}
+	})
+}
+
 // OnTransitionCompleted registers a callback that will be called after all transition actions are executed.
+// By default it is not called for internal transitions and ignored triggers; see SetEmitCompletedForNonTransitions.
 func (sm *StateMachine[TState, TTrigger]) OnTransitionCompleted(action func(Transition[TState, TTrigger])) {
 	sm.onTransitionCompletedEvent.Register(action)
 }
 
+// OnTransitionTimed registers a callback told how long each transition took, from the start of
+// processing the trigger, before guards are evaluated, until all its actions and initial transitions
+// completed, as measured by the machine's Clock. It is called for the transitions OnTransitionCompleted
+// is called for, after processing; time spent on triggers fired immediately from actions is included.
+func (sm *StateMachine[TState, TTrigger]) OnTransitionTimed(
+	handler func(t Transition[TState, TTrigger], duration time.Duration),
+) {
+	sm.timedHandlers = append(sm.timedHandlers, handler)
+}
+
+// SetEmitCompletedForNonTransitions controls whether internal transitions and ignored triggers
+// (including unhandled triggers swallowed by OnUnhandledTrigger) invoke OnTransitionCompleted callbacks.
+// The reported transition has the current state as both source and destination, and its Kind
+// is TransitionInternal or TransitionIgnored. OnTransitioned is never invoked for them.
+// Disabled by default.
+func (sm *StateMachine[TState, TTrigger]) SetEmitCompletedForNonTransitions(emit bool) {
+	sm.panicIfSealed("SetEmitCompletedForNonTransitions")
+	sm.emitCompletedForNonTransitions = emit
+}
+
+// SetUnhandledReturnsError controls whether a fire whose trigger is passed to OnUnhandledTrigger
+// returns an error wrapping ErrTriggerUnhandled after the callback, rather than nil, so that callers
+// checking errors still see that nothing happened. Triggers resolved by OnUnhandledTriggerHandler are
+// not affected. Disabled by default.
+func (sm *StateMachine[TState, TTrigger]) SetUnhandledReturnsError(enable bool) {
+	sm.panicIfSealed("SetUnhandledReturnsError")
+	sm.unhandledReturnsError = enable
+}
+
+// SetEmitInitialTransitionEvents controls whether each initial-transition leg of a hierarchical entry
+// also invokes OnTransitionCompleted callbacks, right after the entry actions of its target, with
+// IsInitial returning true. OnTransitioned is always invoked for initial-transition legs; without
+// this option OnTransitionCompleted is only invoked once, for the combined transition to the final state.
+// Disabled by default.
+func (sm *StateMachine[TState, TTrigger]) SetEmitInitialTransitionEvents(emit bool) {
+	sm.panicIfSealed("SetEmitInitialTransitionEvents")
+	sm.emitInitialTransitionEvents = emit
+}
+
+// DefaultMaxImmediateDepth is the default bound on nested fires in FiringImmediate mode.
+const DefaultMaxImmediateDepth = 100
+
+// SetMaxImmediateDepth bounds how deeply triggers fired from within actions may nest in
+// FiringImmediate mode, where each such fire recurses synchronously. A fire that would exceed
+// the bound returns a MaxDepthExceededError instead of growing the stack until the process crashes.
+// A value of zero or less disables the limit. Defaults to DefaultMaxImmediateDepth.
+// FiringQueued mode does not recurse and is not affected.
+func (sm *StateMachine[TState, TTrigger]) SetMaxImmediateDepth(n int) {
+	sm.panicIfSealed("SetMaxImmediateDepth")
+	sm.maxImmediateDepth.Store(int32(n))
+}
+
+// SetReverseExitOrder controls whether the exit actions of a state run in reverse registration order,
+// so that teardown mirrors setup like deferred calls. This only affects the order within a state:
+// substates are always exited before their superstates. Disabled by default.
+// The setting is part of the shared configuration of states, so set it before calling Clone.
+func (sm *StateMachine[TState, TTrigger]) SetReverseExitOrder(reverse bool) {
+	sm.panicIfSealed("SetReverseExitOrder")
+	sm.reverseExitOrder.Store(reverse)
+}
+
+// SetPermitIdentityAsReentry controls whether Permit and PermitIf with a destination equal to
+// the configured state install a reentry behaviour, as PermitReentry does, instead of panicking.
+// Useful when configuration is generated from data. Disabled by default.
+func (sm *StateMachine[TState, TTrigger]) SetPermitIdentityAsReentry(enable bool) {
+	sm.panicIfSealed("SetPermitIdentityAsReentry")
+	sm.permitIdentityAsReentry = enable
+}
+
 // UnregisterAllTransitionedCallbacks removes all OnTransitioned callbacks.
 func (sm *StateMachine[TState, TTrigger]) UnregisterAllTransitionedCallbacks() {
 	sm.onTransitionedEvent.UnregisterAll()
@@ -435,11 +1368,22 @@ func (sm *StateMachine[TState, TTrigger]) UnregisterAllTransitionCompletedCallba
 	sm.onTransitionCompletedEvent.UnregisterAll()
 }
 
-// UnregisterAllCallbacks removes all registered callbacks (OnTransitioned and OnTransitionCompleted).
+// UnregisterAllCallbacks removes all registered callbacks
+// (OnTransitioning, OnTransitioned, OnTransitionCompleted, OnTerminalState, OnAnyEntry, OnAnyExit,
+// OnGuardEvaluated, OnGuardError, OnUnhandledTrigger, OnUnhandledTriggerHandler and OnError).
 func (sm *StateMachine[TState, TTrigger]) UnregisterAllCallbacks() {
 	sm.onTransitionedEvent.UnregisterAll()
 	sm.onTransitionCompletedEvent.UnregisterAll()
 	sm.unhandledTriggerAction = nil
+	sm.unhandledTriggerHandler = nil
+	sm.terminalStateHandlers = nil
+	sm.errorHandler = nil
+	sm.transitioningHandlers = nil
+	sm.anyEntryActions = nil
+	sm.anyExitActions = nil
+	sm.guardEvaluatedHandlers = nil
+	sm.guardErrorHandlers = nil
+	sm.timedHandlers = nil
 }
 
 // Activate activates the state machine.
@@ -448,12 +1392,14 @@ func (sm *StateMachine[TState, TTrigger]) Activate(ctx context.Context) error {
 		return nil
 	}
 
-	currentRepresentation := sm.getRepresentation(sm.State())
+	state := sm.State()
+	currentRepresentation := sm.getRepresentation(state)
 	if err := currentRepresentation.Activate(ctx); err != nil {
-		return err
+		return sm.handleActionError(ctx, NewTransition(state, state, *new(TTrigger), nil), PhaseActivate, err)
 	}
 
 	sm.isActive = true
+	sm.updateTickers()
 	return nil
 }
 
@@ -463,61 +1409,442 @@ func (sm *StateMachine[TState, TTrigger]) Deactivate(ctx context.Context) error
 		return nil
 	}
 
-	currentRepresentation := sm.getRepresentation(sm.State())
+	state := sm.State()
+	currentRepresentation := sm.getRepresentation(state)
 	if err := currentRepresentation.Deactivate(ctx); err != nil {
-		return err
+		return sm.handleActionError(ctx, NewTransition(state, state, *new(TTrigger), nil), PhaseDeactivate, err)
 	}
 
 	sm.isActive = false
+	sm.stopTickers()
 	return nil
 }
 
+// GoTo moves the state machine directly into the given state without firing a trigger,
+// for example to recover after a failure. Exit actions run for the states being left and
+// entry actions for the states being entered, as for a regular transition, after which
+// initial transitions of the target are applied. Going to the current state re-enters it.
+//
+// OnTransitioned and OnTransitionCompleted are raised with Kind set to TransitionForced and
+// a zero-value Trigger; OnTransitioning handlers are not consulted. The state must have been
+// configured, otherwise an ArgumentError is returned.
+//
+// GoTo is serialized with fires as FireSequence is, and like a fire it returns ErrShuttingDown
+// after Shutdown. It returns an InvalidOperationError in replay mode, since actions do not run
+// there, and when called from an action of a queued state machine.
+func (sm *StateMachine[TState, TTrigger]) GoTo(ctx context.Context, state TState) error {
+	if _, ok := sm.lookupRepresentation(state); !ok {
+		return &ArgumentError{
+			ParamName: "state",
+			Message:   fmt.Sprintf("state '%v' is not configured", state),
+		}
+	}
+	return sm.runForced(ctx, "GoTo", func(ctx context.Context) error {
+		return sm.goTo(ctx, state, true)
+	})
+}
+
+// runForced runs fn, which changes the state without firing a trigger, with the serialization and
+// checks of a fire. In FiringImmediate mode it waits for fires on other goroutines and nests when
+// called from an action. In FiringQueued mode it waits for the queue to be processed and processes
+// the triggers queued meanwhile afterwards; called from an action, it would wait for itself, so an
+// InvalidOperationError naming operation is returned instead, as in replay mode.
+func (sm *StateMachine[TState, TTrigger]) runForced(
+	ctx context.Context,
+	operation string,
+	fn func(ctx context.Context) error,
+) error {
+	if sm.replayMode {
+		return &InvalidOperationError{Message: operation + " cannot be called in replay mode"}
+	}
+	run := func(ctx context.Context) error {
+		sm.processing.Add(1)
+		defer sm.processing.Add(-1)
+		return fn(sm.withMachineValues(ctx))
+	}
+
+	if sm.firingMode != FiringQueued {
+		return sm.runImmediate(func() error { return run(ctx) })
+	}
+	if ctx.Value(drainingQueueKey{}) == any(sm) {
+		return &InvalidOperationError{
+			Message: operation + " cannot be called from an action of a queued state machine",
+		}
+	}
+	if err := sm.waitForQueue(ctx); err != nil {
+		return err
+	}
+	err := run(context.WithValue(ctx, drainingQueueKey{}, any(sm)))
+	_, queueErr := sm.processQueue()
+	return errors.Join(err, queueErr)
+}
+
+// goTo moves the state machine into state as described for GoTo. pushHistory tells whether the
+// state left is pushed onto the history enabled with EnableHistory.
+func (sm *StateMachine[TState, TTrigger]) goTo(ctx context.Context, state TState, pushHistory bool) error {
+	var tr TTrigger
+	src := sm.State()
+	transition := NewTransition(src, state, tr, nil)
+	transition.Kind = TransitionForced
+
+	sourceRepresentation := sm.getRepresentation(src)
+	if err := sourceRepresentation.exit(ctx, transition, sm.anyExitHook()); err != nil {
+		return sm.handleActionError(ctx, transition, PhaseExit, err)
+	}
+
+	sm.recordDeparture(sourceRepresentation, state, pushHistory)
+	sm.setState(state)
+	sm.onTransitionedEvent.InvokeCtx(ctx, transition)
+
+	if err := sm.getRepresentation(state).enter(ctx, transition, sm.anyEntryHook()); err != nil {
+		return sm.handleActionError(ctx, transition, PhaseEntry, err)
+	}
+
+	var viaInitial bool
+	if sm.State() == state {
+		var err error
+		if viaInitial, err = sm.handleInitialTransitions(ctx, state, tr, nil); err != nil {
+			return err
+		}
+	}
+
+	completed := completedTransition(transition, sm.State(), viaInitial)
+	completed.Kind = TransitionForced
+	sm.onTransitionCompletedEvent.Invoke(completed)
+	return nil
+}
+
+// Reactivate deactivates the state machine if it is active, then activates it again,
+// so that activation actions added since the last activation run.
+// The usual ordering applies: deactivation runs from the current state up to the root superstate,
+// activation from the root superstate down to the current state.
+// If deactivation fails, the machine stays active and activation is not attempted.
+func (sm *StateMachine[TState, TTrigger]) Reactivate(ctx context.Context) error {
+	if err := sm.Deactivate(ctx); err != nil {
+		return err
+	}
+	return sm.Activate(ctx)
+}
+
+// IsFiring returns true if the state machine is currently processing queued triggers.
+// Only FiringQueued machines track this; in FiringImmediate mode it always returns false.
+func (sm *StateMachine[TState, TTrigger]) IsFiring() bool {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+	return sm.firing
+}
+
 // IsInState returns true if the current state is the specified state or a substate of it.
 func (sm *StateMachine[TState, TTrigger]) IsInState(state TState) bool {
-	currentRepresentation := sm.getRepresentation(sm.State())
-	return currentRepresentation.IsIncludedIn(state)
+	return slices.Contains(sm.ActiveStates(), state)
+}
+
+// ActiveStates returns the states the state machine is currently in: the current state
+// followed by each of its superstates, innermost first.
+func (sm *StateMachine[TState, TTrigger]) ActiveStates() []TState {
+	var states []TState
+	for rep := sm.currentRepresentation(); rep != nil; rep = rep.Superstate() {
+		states = append(states, rep.UnderlyingState())
+	}
+	return states
 }
 
 // CanFire returns true if the specified trigger can be fired from the current state.
 func (sm *StateMachine[TState, TTrigger]) CanFire(ctx context.Context, trigger TTrigger, args any) bool {
-	return sm.getRepresentation(sm.State()).CanHandle(ctx, trigger, args)
+	trigger = sm.canonicalTrigger(trigger)
+	return !sm.IsTriggerDisabled(trigger) && sm.currentRepresentation().CanHandle(ctx, trigger, args)
+}
+
+// CanFireAny returns true if any trigger can be fired from the current state with the given args,
+// i.e. if GetPermittedTriggers would not be empty. It stops at the first permitted trigger.
+func (sm *StateMachine[TState, TTrigger]) CanFireAny(ctx context.Context, args any) bool {
+	if sm.hasDisabledTriggers() {
+		return len(sm.GetPermittedTriggers(ctx, args)) > 0
+	}
+	return sm.currentRepresentation().HasPermittedTrigger(ctx, args)
+}
+
+// HandlingState returns the state whose configuration would handle the specified trigger if it were
+// fired from the current state with the given args: the current state itself, or the superstate the
+// behaviour is inherited from. Guards are evaluated but no actions run. Returns false if the trigger
+// would not be handled, including when guards reject it or fail.
+func (sm *StateMachine[TState, TTrigger]) HandlingState(
+	ctx context.Context,
+	trigger TTrigger,
+	args any,
+) (TState, bool) {
+	trigger = sm.canonicalTrigger(trigger)
+	if sm.IsTriggerDisabled(trigger) {
+		var zero TState
+		return zero, false
+	}
+	result := sm.currentRepresentation().TryFindHandler(ctx, trigger, args)
+	if result == nil || result.Handler == nil || result.Owner == nil {
+		var zero TState
+		return zero, false
+	}
+	return result.Owner.UnderlyingState(), true
 }
 
 // GetPermittedTriggers returns the triggers that can be fired from the current state.
 func (sm *StateMachine[TState, TTrigger]) GetPermittedTriggers(ctx context.Context, args any) []TTrigger {
-	return sm.getRepresentation(sm.State()).GetPermittedTriggers(ctx, args)
+	return sm.withoutDisabledTriggers(sm.currentRepresentation().GetPermittedTriggers(ctx, args))
+}
+
+// Representation returns the representation of a configured state, with its behaviours and actions,
+// for tooling that needs more than GetInfo, and false if the state is not configured. Unlike
+// Configure, it does not configure missing states. The representation is shared with the machine
+// and its clones and must be treated as read-only; configure states with Configure.
+func (sm *StateMachine[TState, TTrigger]) Representation(state TState) (*StateRepresentation[TState, TTrigger], bool) {
+	return sm.lookupRepresentation(state)
+}
+
+// States returns all configured states in a deterministic order.
+// Numeric and string states are sorted by value, other types by their formatted representation.
+func (sm *StateMachine[TState, TTrigger]) States() []TState {
+	representations := sm.representations()
+	states := make([]TState, 0, len(representations))
+	for state := range representations {
+		states = append(states, state)
+	}
+	sortValues(states)
+	return states
+}
+
+// Triggers returns every trigger referenced by any configured state, in a deterministic order.
+func (sm *StateMachine[TState, TTrigger]) Triggers() []TTrigger {
+	seen := make(map[TTrigger]struct{})
+	var triggers []TTrigger
+	for _, rep := range sm.representations() {
+		for trigger := range rep.TriggerBehaviours() {
+			if _, ok := seen[trigger]; !ok {
+				seen[trigger] = struct{}{}
+				triggers = append(triggers, trigger)
+			}
+		}
+	}
+	sortValues(triggers)
+	return triggers
+}
+
+// Validate checks the configuration for triggers that can never be fired successfully because
+// a state has several unguarded behaviours for them (for example Permit(X, B) together with Ignore(X)),
+// which Fire would otherwise only report when the trigger is fired. A guarded behaviour next to an
+// unguarded one is legal and not reported. Returns nil if no problem was found, otherwise one
+// InvalidOperationError per offending state and trigger, joined with errors.Join.
+func (sm *StateMachine[TState, TTrigger]) Validate() error {
+	var errs []error
+	representations := sm.representations()
+	for _, state := range sm.States() {
+		behaviours := representations[state].TriggerBehaviours()
+
+		triggers := make([]TTrigger, 0, len(behaviours))
+		for trigger := range behaviours {
+			triggers = append(triggers, trigger)
+		}
+		sortValues(triggers)
+
+		for _, trigger := range triggers {
+			unguarded := 0
+			for _, behaviour := range behaviours[trigger] {
+				if behaviour.GetGuard().IsEmpty() {
+					unguarded++
+				}
+			}
+			if unguarded > 1 {
+				errs = append(errs, &InvalidOperationError{
+					Message: fmt.Sprintf(
+						"state '%v' has %d unguarded behaviours for trigger '%v'; at most one is allowed",
+						state, unguarded, trigger,
+					),
+				})
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// OutgoingTransitions returns the fixed transitions that leave the specified state, including
+// those inherited from its superstates. A trigger configured in a substate (with any behaviour)
+// overrides the transitions of its superstates for that trigger. Transitions are ordered by trigger.
+// Returns nil if the state has not been configured.
+func (sm *StateMachine[TState, TTrigger]) OutgoingTransitions(state TState) []FixedTransitionInfo {
+	representation, ok := sm.lookupRepresentation(state)
+	if !ok {
+		return nil
+	}
+
+	infos := make(map[any]*StateInfo)
+	for _, info := range sm.GetInfo().States {
+		infos[info.UnderlyingState] = info
+	}
+
+	var result []FixedTransitionInfo
+	overridden := make(map[TTrigger]bool)
+	for rep := representation; rep != nil; rep = rep.Superstate() {
+		if info, ok := infos[rep.UnderlyingState()]; ok {
+			for _, fixed := range info.FixedTransitions {
+				if trigger, ok := fixed.Trigger.UnderlyingTrigger.(TTrigger); ok && !overridden[trigger] {
+					result = append(result, fixed)
+				}
+			}
+		}
+		for trigger := range rep.TriggerBehaviours() {
+			overridden[trigger] = true
+		}
+	}
+
+	slices.SortStableFunc(result, func(a, b FixedTransitionInfo) int {
+		return compareValues(a.Trigger.UnderlyingTrigger, b.Trigger.UnderlyingTrigger)
+	})
+	return result
+}
+
+// TransitionMatrix returns, for every configured state, the triggers that transition out of it and
+// their destinations, including transitions inherited from superstates (a trigger configured in a
+// substate overrides its superstates). Reentry transitions map to the state itself; dynamic transitions
+// and InternalOrTransition triggers map to the zero state; use GetInfo to tell them apart from a fixed one.
+// Internal transitions and ignored triggers are omitted. When several guarded transitions share a
+// trigger, the first one configured is reported.
+func (sm *StateMachine[TState, TTrigger]) TransitionMatrix() map[TState]map[TTrigger]TState {
+	representations := sm.representations()
+	matrix := make(map[TState]map[TTrigger]TState, len(representations))
+	for state, representation := range representations {
+		row := make(map[TTrigger]TState)
+		overridden := make(map[TTrigger]bool)
+		for rep := representation; rep != nil; rep = rep.Superstate() {
+			for trigger, behaviours := range rep.TriggerBehaviours() {
+				if overridden[trigger] {
+					continue
+				}
+				overridden[trigger] = true
+				if dst, ok := transitionDestination(behaviours); ok {
+					row[trigger] = dst
+				}
+			}
+		}
+		matrix[state] = row
+	}
+	return matrix
+}
+
+// transitionDestination returns the destination of the first behaviour that leaves the state,
+// or the zero state for a dynamic transition. Returns false if none of the behaviours leaves the state.
+func transitionDestination[TState, TTrigger comparable](
+	behaviours []TriggerBehaviour[TState, TTrigger],
+) (TState, bool) {
+	var zero TState
+	for _, behaviour := range behaviours {
+		switch b := behaviour.(type) {
+		case *TransitioningTriggerBehaviour[TState, TTrigger]:
+			return b.Destination, true
+		case *ReentryTriggerBehaviour[TState, TTrigger]:
+			return b.Destination, true
+		case *DynamicTriggerBehaviour[TState, TTrigger], *InternalOrTransitionTriggerBehaviour[TState, TTrigger]:
+			return zero, true
+		}
+	}
+	return zero, false
 }
 
 // getRepresentation gets or creates the representation for a state.
 func (sm *StateMachine[TState, TTrigger]) getRepresentation(state TState) *StateRepresentation[TState, TTrigger] {
-	representation, exists := sm.stateRepresentations[state]
-	if !exists {
-		representation = NewStateRepresentation[TState, TTrigger](state)
-		sm.stateRepresentations[state] = representation
+	if representation, ok := sm.lookupRepresentation(state); ok {
+		return representation
+	}
+
+	sm.representationsMutex.Lock()
+	defer sm.representationsMutex.Unlock()
+	if representation, ok := sm.lookupRepresentation(state); ok {
+		return representation
+	}
+	representation := NewStateRepresentation[TState, TTrigger](state)
+	representation.configVersion = &sm.configVersion
+	representation.reverseExitOrder = &sm.reverseExitOrder
+	representation.processing = &sm.processing
+	representation.hasTicks = &sm.hasTicks
+	representation.sealed = &sm.sealed
+	representations := maps.Clone(sm.representations())
+	if representations == nil {
+		representations = make(map[TState]*StateRepresentation[TState, TTrigger])
 	}
+	representations[state] = representation
+	sm.stateRepresentations.Store(&representations)
+	sm.configVersion.Add(1)
 	return representation
 }
 
+// currentRepresentation returns the representation of the current state for queries. If the state
+// was never configured, an empty representation is returned without registering it, so that queries
+// do not hide an UnconfiguredStateError from a later Fire.
+func (sm *StateMachine[TState, TTrigger]) currentRepresentation() *StateRepresentation[TState, TTrigger] {
+	state := sm.State()
+	if representation, ok := sm.lookupRepresentation(state); ok {
+		return representation
+	}
+	return NewStateRepresentation[TState, TTrigger](state)
+}
+
+// lookupRepresentation returns the representation for a state without creating it.
+func (sm *StateMachine[TState, TTrigger]) lookupRepresentation(
+	state TState,
+) (*StateRepresentation[TState, TTrigger], bool) {
+	representation, ok := sm.representations()[state]
+	return representation, ok
+}
+
+// representations returns the state representations. The map is never modified, so it is safe to
+// iterate while other goroutines fire triggers, but callers must not modify it either.
+func (sm *StateMachine[TState, TTrigger]) representations() map[TState]*StateRepresentation[TState, TTrigger] {
+	if representations := sm.stateRepresentations.Load(); representations != nil {
+		return *representations
+	}
+	return nil
+}
+
 // GetInfo returns information about the state machine configuration for introspection.
+// The result is cached until the configuration changes, so callers must treat it as read-only.
+// States are ordered by state value, and each state's fixed, dynamic and ignored transitions are
+// ordered by trigger; values that are not numbers or strings are ordered by their formatted form.
 func (sm *StateMachine[TState, TTrigger]) GetInfo() *StateMachineInfo {
+	sm.infoMutex.Lock()
+	defer sm.infoMutex.Unlock()
+
+	version := sm.configVersion.Load()
+	if sm.infoCache != nil && sm.infoCacheVersion == version {
+		return sm.infoCache
+	}
+
+	sm.infoCache = sm.buildInfo()
+	sm.infoCacheVersion = version
+	return sm.infoCache
+}
+
+// buildInfo builds the StateMachineInfo from the current state representations.
+func (sm *StateMachine[TState, TTrigger]) buildInfo() *StateMachineInfo {
 	// Build state info map first
 	stateInfos := make(map[TState]*StateInfo)
+	representations := sm.representations()
 
 	// Create StateInfo for each state
-	for state, rep := range sm.stateRepresentations {
+	for state, rep := range representations {
 		stateInfos[state] = sm.createStateInfo(rep)
 	}
 
 	// Add relationships (substates, superstates, transitions)
-	for state, rep := range sm.stateRepresentations {
+	for state, rep := range representations {
 		sm.addStateRelationships(stateInfos[state], rep, stateInfos)
 	}
 
-	// Convert to slice
+	// Convert to slice, ordered so that callers see the same layout on every call
 	states := make([]*StateInfo, 0, len(stateInfos))
 	for _, info := range stateInfos {
+		info.FixedTransitions = sortedByTrigger(info.FixedTransitions)
+		info.DynamicTransitions = sortedByTrigger(info.DynamicTransitions)
+		info.IgnoredTriggers = sortedByTrigger(info.IgnoredTriggers)
 		states = append(states, info)
 	}
+	states = sortedStateInfos(states)
 
 	// Find initial state info
 	var initialStateInfo *StateInfo
@@ -581,6 +1908,8 @@ func (sm *StateMachine[TState, TTrigger]) createStateInfo(rep *StateRepresentati
 		ActivateActions:   activateActions,
 		DeactivateActions: deactivateActions,
 		ExitActions:       exitActions,
+		Tags:              maps.Clone(rep.Tags()),
+		IsFinal:           rep.IsFinal(),
 	}
 }
 
@@ -639,10 +1968,24 @@ func (sm *StateMachine[TState, TTrigger]) addStateRelationships(
 							IsInternalTransition: true,
 						},
 						DestinationState: destInfo,
+						InternalAction:   b.ActionDescription,
 					})
 				}
 			case *DynamicTriggerBehaviour[TState, TTrigger]:
 				info.DynamicTransitions = append(info.DynamicTransitions, b.TransitionInfo)
+			case *InternalOrTransitionTriggerBehaviour[TState, TTrigger]:
+				if destInfo, ok := stateInfos[rep.UnderlyingState()]; ok {
+					info.FixedTransitions = append(info.FixedTransitions, FixedTransitionInfo{
+						transitionInfoBase: transitionInfoBase{
+							Trigger:              NewTriggerInfo(trigger),
+							GuardConditions:      convertGuardConditions(behaviour.GetGuard().Conditions),
+							IsInternalTransition: true,
+						},
+						DestinationState: destInfo,
+						InternalAction:   b.ActionDescription,
+					})
+				}
+				info.DynamicTransitions = append(info.DynamicTransitions, b.TransitionInfo)
 			}
 		}
 	}
@@ -2,6 +2,8 @@ package stateless_test
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"testing"
 
 	"github.com/atlekbai/stateless"
@@ -201,3 +203,362 @@ func TestOnExit_TypedArgument(t *testing.T) {
 		t.Errorf("expected 'Charlie', got '%s'", receivedArgs.Assignee)
 	}
 }
+
+func TestOnEntryFirst_RunsBeforeEarlierEntryActions(t *testing.T) {
+	record := []string{}
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			record = append(record, "feature1")
+			return nil
+		}).
+		OnEntryFirst(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			record = append(record, "setup")
+			return nil
+		}).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			record = append(record, "feature2")
+			return nil
+		}).
+		OnEntryFirst(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			record = append(record, "outer setup")
+			return nil
+		})
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"outer setup", "setup", "feature1", "feature2"}
+	if len(record) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %v", len(expected), len(record), record)
+	}
+	for i := range expected {
+		if record[i] != expected[i] {
+			t.Errorf("expected %s at index %d, got %s", expected[i], i, record[i])
+		}
+	}
+}
+
+func TestStateNode_Descriptions(t *testing.T) {
+	noop := func(ctx context.Context, tr stateless.Transition[State, Trigger]) error { return nil }
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		PermitIf(TriggerX, StateB, func(_ context.Context, _ any) error { return nil }, "is ready").
+		InternalTransition(TriggerY, noop, "refresh").
+		OnEntry(noop, "open door").
+		OnExit(noop, "close door")
+	sm.Configure(StateB).
+		OnEntry(noop)
+
+	var stateA, stateB *stateless.StateInfo
+	for _, info := range sm.GetInfo().States {
+		switch info.UnderlyingState {
+		case StateA:
+			stateA = info
+		case StateB:
+			stateB = info
+		}
+	}
+
+	if got := stateA.EntryActions[0].Description(); got != "open door" {
+		t.Errorf("expected entry action described as 'open door', got %q", got)
+	}
+	if got := stateA.ExitActions[0].Description(); got != "close door" {
+		t.Errorf("expected exit action described as 'close door', got %q", got)
+	}
+	if got := stateB.EntryActions[0].Description(); got != stateless.DefaultFunctionDescription {
+		t.Errorf("expected undescribed entry action to use the default, got %q", got)
+	}
+	for _, fixed := range stateA.FixedTransitions {
+		switch fixed.Trigger.UnderlyingTrigger {
+		case TriggerX:
+			if got := fixed.GuardConditions[0].Description(); got != "is ready" {
+				t.Errorf("expected guard described as 'is ready', got %q", got)
+			}
+		case TriggerY:
+			if got := fixed.InternalAction.Description(); got != "refresh" {
+				t.Errorf("expected internal action described as 'refresh', got %q", got)
+			}
+		}
+	}
+}
+
+func TestOnEntryFromState(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+
+	var record []string
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB).
+		Permit(TriggerY, StateB)
+	sm.Configure(StateB).
+		OnEntryFromState(StateA, func(_ context.Context, tr stateless.Transition[State, Trigger]) error {
+			record = append(record, "FromA:"+tr.Trigger.String())
+			return nil
+		}).
+		OnEntryFromState(StateC, func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			record = append(record, "FromC")
+			return nil
+		}).
+		Permit(TriggerX, StateA)
+
+	for _, tr := range []Trigger{TriggerX, TriggerX, TriggerY} {
+		if err := sm.Fire(tr, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expected := []string{"FromA:TriggerX", "FromA:TriggerY"}
+	if len(record) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, record)
+	}
+	for i := range expected {
+		if record[i] != expected[i] {
+			t.Errorf("expected %s at index %d, got %s", expected[i], i, record[i])
+		}
+	}
+}
+
+func TestOnEntryFromState_InitialTransitionSourceIsSuperstate(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+
+	var record []string
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		InitialTransition(StateC)
+	sm.Configure(StateC).
+		SubstateOf(StateB).
+		OnEntryFromState(StateA, func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			record = append(record, "FromA")
+			return nil
+		}).
+		OnEntryFromState(StateB, func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			record = append(record, "FromB")
+			return nil
+		})
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sm.State() != StateC {
+		t.Fatalf("expected StateC, got %v", sm.State())
+	}
+	if len(record) != 1 || record[0] != "FromB" {
+		t.Errorf("expected [FromB], got %v", record)
+	}
+}
+
+func TestSetReverseExitOrder(t *testing.T) {
+	for _, tt := range []struct {
+		reverse  bool
+		expected []string
+	}{
+		{false, []string{"ExitB1", "ExitB2", "ExitA"}},
+		{true, []string{"ExitB2", "ExitB1", "ExitA"}},
+	} {
+		sm := stateless.NewStateMachine[State, Trigger](StateB)
+		sm.SetReverseExitOrder(tt.reverse)
+
+		var record []string
+		exit := func(name string) stateless.TransitionAction[State, Trigger] {
+			return func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+				record = append(record, name)
+				return nil
+			}
+		}
+		sm.Configure(StateA).
+			OnExit(exit("ExitA"))
+		sm.Configure(StateB).
+			SubstateOf(StateA).
+			OnExit(exit("ExitB1")).
+			OnExit(exit("ExitB2")).
+			Permit(TriggerX, StateC)
+
+		if err := sm.Fire(TriggerX, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(record) != len(tt.expected) {
+			t.Fatalf("reverse=%v: expected %v, got %v", tt.reverse, tt.expected, record)
+		}
+		for i := range tt.expected {
+			if record[i] != tt.expected[i] {
+				t.Errorf("reverse=%v: expected %v, got %v", tt.reverse, tt.expected, record)
+				break
+			}
+		}
+	}
+}
+
+func TestOnAnyEntryAndExit(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+
+	var record []string
+	action := func(name string) stateless.TransitionAction[State, Trigger] {
+		return func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			record = append(record, name)
+			return nil
+		}
+	}
+	sm.Configure(StateA).
+		OnExit(action("ExitA")).
+		Permit(TriggerX, StateC)
+	sm.Configure(StateB).
+		OnEntry(action("EntryB"))
+	sm.Configure(StateC).
+		SubstateOf(StateB).
+		OnEntry(action("EntryC"))
+	sm.OnAnyEntry(action("AnyEntry"))
+	sm.OnAnyExit(action("AnyExit"))
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"ExitA", "AnyExit", "EntryB", "AnyEntry", "EntryC", "AnyEntry"}
+	if !slices.Equal(record, expected) {
+		t.Errorf("expected %v, got %v", expected, record)
+	}
+}
+
+func TestOnAnyEntry_ErrorStopsTransition(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).Permit(TriggerX, StateC)
+
+	entryErr := errors.New("entry failed")
+	sm.OnAnyEntry(func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+		return entryErr
+	})
+
+	var phase stateless.ActionPhase
+	sm.OnError(func(
+		_ context.Context,
+		_ stateless.Transition[State, Trigger],
+		p stateless.ActionPhase,
+		err error,
+	) error {
+		phase = p
+		return err
+	})
+
+	if err := sm.Fire(TriggerX, nil); !errors.Is(err, entryErr) {
+		t.Fatalf("expected entry error, got %v", err)
+	}
+	if phase != stateless.PhaseEntry {
+		t.Errorf("expected OnError to see PhaseEntry, got %v", phase)
+	}
+}
+
+func TestOnExitDetailed(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateC)
+
+	var exited []State
+	exit := func(_ context.Context, tr stateless.Transition[State, Trigger], exitingState State) error {
+		if tr.Source != StateC || tr.Destination != StateD {
+			t.Errorf("expected transition StateC -> StateD, got %v -> %v", tr.Source, tr.Destination)
+		}
+		exited = append(exited, exitingState)
+		return nil
+	}
+	sm.Configure(StateA).OnExitDetailed(exit)
+	sm.Configure(StateB).SubstateOf(StateA).OnExitDetailed(exit)
+	sm.Configure(StateC).
+		SubstateOf(StateB).
+		OnExitDetailed(exit).
+		Permit(TriggerX, StateD)
+	sm.Configure(StateD)
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []State{StateC, StateB, StateA}
+	if !slices.Equal(exited, expected) {
+		t.Errorf("expected %v, got %v", expected, exited)
+	}
+}
+
+func TestOnEntryFailure(t *testing.T) {
+	entryErr := errors.New("entry failed")
+	var failureEntered bool
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		OnEntryFailure(StateC).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error { return entryErr }).
+		OnExit(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			t.Error("expected exit actions of the failed state not to run")
+			return nil
+		})
+	sm.Configure(StateC).OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+		failureEntered = tr.Source == StateB && tr.Trigger == TriggerX
+		return nil
+	})
+
+	err := sm.Fire(TriggerX, nil)
+
+	var failed *stateless.EntryFailedError
+	if !errors.As(err, &failed) {
+		t.Fatalf("expected EntryFailedError, got %v", err)
+	}
+	if !errors.Is(err, entryErr) {
+		t.Errorf("expected error to wrap the entry error, got %v", err)
+	}
+	if failed.State != StateB || failed.FailureState != StateC {
+		t.Errorf("expected failure from StateB to StateC, got %v to %v", failed.State, failed.FailureState)
+	}
+	if sm.State() != StateC {
+		t.Errorf("expected StateC, got %v", sm.State())
+	}
+	if !failureEntered {
+		t.Error("expected the failure state to be entered from StateB")
+	}
+}
+
+func TestOnEntryFailure_RejectionDoesNotCompensate(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		OnEntryFailure(StateC).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			return stateless.Reject("not now")
+		})
+	sm.Configure(StateC)
+
+	err := sm.Fire(TriggerX, nil)
+
+	if !stateless.IsGuardRejection(err) {
+		t.Errorf("expected guard rejection, got %v", err)
+	}
+	if sm.State() != StateB {
+		t.Errorf("expected StateB, got %v", sm.State())
+	}
+}
+
+func TestOnEntryFailure_FailureStateEntryFails(t *testing.T) {
+	entryErr := errors.New("entry failed")
+	failureErr := errors.New("failure entry failed")
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		OnEntryFailure(StateC).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error { return entryErr })
+	sm.Configure(StateC).
+		OnEntryFailure(StateB).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error { return failureErr })
+
+	err := sm.Fire(TriggerX, nil)
+
+	if !errors.Is(err, entryErr) || !errors.Is(err, failureErr) {
+		t.Errorf("expected both entry errors, got %v", err)
+	}
+	if sm.State() != StateC {
+		t.Errorf("expected StateC, got %v", sm.State())
+	}
+}
@@ -0,0 +1,300 @@
+package stateless_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/atlekbai/stateless"
+)
+
+func TestOnError_EntryActionError(t *testing.T) {
+	entryErr := errors.New("entry failed")
+
+	var gotPhase stateless.ActionPhase
+	var gotTransition stateless.Transition[State, Trigger]
+	var gotErr error
+	calls := 0
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			return entryErr
+		})
+
+	sm.OnError(func(
+		ctx context.Context,
+		tr stateless.Transition[State, Trigger],
+		phase stateless.ActionPhase,
+		err error,
+	) error {
+		calls++
+		gotPhase = phase
+		gotTransition = tr
+		gotErr = err
+		return nil
+	})
+
+	err := sm.Fire(TriggerX, nil)
+	if !errors.Is(err, entryErr) {
+		t.Fatalf("expected entry error to propagate, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected error handler to be called once, got %d", calls)
+	}
+	if gotPhase != stateless.PhaseEntry {
+		t.Errorf("expected phase Entry, got %v", gotPhase)
+	}
+	if gotTransition.Source != StateA || gotTransition.Destination != StateB || gotTransition.Trigger != TriggerX {
+		t.Errorf("unexpected transition: %+v", gotTransition)
+	}
+	if !errors.Is(gotErr, entryErr) {
+		t.Errorf("expected handler to receive entry error, got %v", gotErr)
+	}
+}
+
+func TestOnError_ExitAndInternalPhases(t *testing.T) {
+	exitErr := errors.New("exit failed")
+	internalErr := errors.New("internal failed")
+
+	var phases []stateless.ActionPhase
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB).
+		InternalTransition(TriggerY, func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			return internalErr
+		}).
+		OnExit(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			return exitErr
+		})
+
+	sm.OnError(func(
+		ctx context.Context,
+		tr stateless.Transition[State, Trigger],
+		phase stateless.ActionPhase,
+		err error,
+	) error {
+		phases = append(phases, phase)
+		return nil
+	})
+
+	if err := sm.Fire(TriggerY, nil); !errors.Is(err, internalErr) {
+		t.Errorf("expected internal error, got %v", err)
+	}
+	if err := sm.Fire(TriggerX, nil); !errors.Is(err, exitErr) {
+		t.Errorf("expected exit error, got %v", err)
+	}
+
+	expected := []stateless.ActionPhase{stateless.PhaseInternal, stateless.PhaseExit}
+	if len(phases) != len(expected) {
+		t.Fatalf("expected %d phases, got %d: %v", len(expected), len(phases), phases)
+	}
+	for i := range expected {
+		if phases[i] != expected[i] {
+			t.Errorf("expected %v at index %d, got %v", expected[i], i, phases[i])
+		}
+	}
+}
+
+func TestOnError_GuardErrorButNotRejection(t *testing.T) {
+	guardErr := errors.New("database unavailable")
+	calls := 0
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		PermitIf(TriggerX, StateB, func(_ context.Context, _ any) error { return guardErr }).
+		PermitIf(TriggerY, StateC, func(_ context.Context, _ any) error { return stateless.Reject("not allowed") })
+
+	sm.OnError(func(
+		ctx context.Context,
+		tr stateless.Transition[State, Trigger],
+		phase stateless.ActionPhase,
+		err error,
+	) error {
+		calls++
+		if phase != stateless.PhaseGuard {
+			t.Errorf("expected phase Guard, got %v", phase)
+		}
+		return nil
+	})
+
+	if err := sm.Fire(TriggerX, nil); !errors.Is(err, guardErr) {
+		t.Errorf("expected guard error, got %v", err)
+	}
+	if err := sm.Fire(TriggerY, nil); err == nil {
+		t.Error("expected error for rejected guard")
+	}
+	if calls != 1 {
+		t.Errorf("expected error handler to be called once (rejections are not errors), got %d", calls)
+	}
+}
+
+func TestOnError_ReplacementError(t *testing.T) {
+	activateErr := errors.New("activate failed")
+	replacement := errors.New("replaced")
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		OnActivate(func(ctx context.Context) error { return activateErr })
+
+	sm.OnError(func(
+		ctx context.Context,
+		tr stateless.Transition[State, Trigger],
+		phase stateless.ActionPhase,
+		err error,
+	) error {
+		if phase != stateless.PhaseActivate {
+			t.Errorf("expected phase Activate, got %v", phase)
+		}
+		return replacement
+	})
+
+	if err := sm.Activate(context.Background()); !errors.Is(err, replacement) {
+		t.Errorf("expected replacement error, got %v", err)
+	}
+}
+
+func TestRetry_GuardRequestsRetry(t *testing.T) {
+	ready := false
+	handlerCalls := 0
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		PermitIf(TriggerX, StateB, func(_ context.Context, _ any) error {
+			if !ready {
+				return stateless.Retry("not ready")
+			}
+			return nil
+		})
+	sm.OnError(func(
+		ctx context.Context,
+		tr stateless.Transition[State, Trigger],
+		phase stateless.ActionPhase,
+		err error,
+	) error {
+		handlerCalls++
+		return nil
+	})
+
+	err := sm.Fire(TriggerX, nil)
+	var retryErr *stateless.RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected RetryError, got %v", err)
+	}
+	if retryErr.Reason != "not ready" {
+		t.Errorf("expected reason 'not ready', got %q", retryErr.Reason)
+	}
+	var invalidTransitionErr *stateless.InvalidTransitionError
+	if errors.As(err, &invalidTransitionErr) {
+		t.Error("expected retry not to be reported as an invalid transition")
+	}
+	if handlerCalls != 0 {
+		t.Errorf("expected retry not to be treated as an unexpected error, got %d OnError calls", handlerCalls)
+	}
+	if sm.State() != StateA {
+		t.Errorf("expected StateA, got %v", sm.State())
+	}
+
+	ready = true
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error on retry: %v", err)
+	}
+	if sm.State() != StateB {
+		t.Errorf("expected StateB, got %v", sm.State())
+	}
+}
+
+func TestRetry_KeepsGuardWrapping(t *testing.T) {
+	errBusy := errors.New("busy")
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		PermitIf(TriggerX, StateB, func(_ context.Context, _ any) error {
+			return fmt.Errorf("%w: %w", errBusy, stateless.Retry("later"))
+		})
+
+	err := sm.Fire(TriggerX, nil)
+	if !errors.Is(err, errBusy) {
+		t.Errorf("expected the guard's error, got %v", err)
+	}
+	if !stateless.IsRetry(err) {
+		t.Errorf("expected a RetryError, got %v", err)
+	}
+}
+
+func TestRetry_OtherPermittedBehaviourWins(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		PermitIf(TriggerX, StateB, func(_ context.Context, _ any) error { return stateless.Retry("later") }).
+		PermitIf(TriggerX, StateC, func(_ context.Context, _ any) error { return nil })
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateC {
+		t.Errorf("expected StateC, got %v", sm.State())
+	}
+}
+
+func TestAsInvalidTransition(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerY, StateB).
+		PermitIf(TriggerX, StateB, func(_ context.Context, _ any) error {
+			return stateless.Reject("closed")
+		})
+
+	err := sm.Fire(TriggerX, nil)
+
+	trigger, state, ok := stateless.AsInvalidTransition[State, Trigger](fmt.Errorf("wrapped: %w", err))
+	if !ok {
+		t.Fatalf("expected InvalidTransitionError, got %v", err)
+	}
+	if trigger != TriggerX || state != StateA {
+		t.Errorf("expected TriggerX from StateA, got %v from %v", trigger, state)
+	}
+	if !strings.Contains(err.Error(), "Permitted triggers: TriggerY.") {
+		t.Errorf("expected error message to list permitted triggers, got %q", err.Error())
+	}
+
+	if _, _, ok := stateless.AsInvalidTransition[int, Trigger](err); ok {
+		t.Error("expected false for a mismatched state type")
+	}
+	if _, _, ok := stateless.AsInvalidTransition[State, Trigger](errors.New("other")); ok {
+		t.Error("expected false for an unrelated error")
+	}
+}
+
+func TestSetGuardReasonFormatter(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		PermitIf(TriggerX, StateB, func(_ context.Context, _ any) error {
+			return stateless.Reject("door closed")
+		})
+	sm.SetGuardReasonFormatter(func(trigger Trigger, description string) string {
+		return fmt.Sprintf("%v: la porte est fermée (%s)", trigger, description)
+	})
+
+	err := sm.Fire(TriggerX, nil)
+
+	var invalid *stateless.InvalidTransitionError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected InvalidTransitionError, got %v", err)
+	}
+	if len(invalid.UnmetGuards) != 1 {
+		t.Fatalf("expected 1 unmet guard, got %v", invalid.UnmetGuards)
+	}
+	guard := invalid.UnmetGuards[0]
+	if guard.Error() != "TriggerX: la porte est fermée (door closed)" {
+		t.Errorf("expected formatted reason, got %q", guard.Error())
+	}
+	if !stateless.IsGuardRejection(guard) {
+		t.Error("expected the formatted reason to wrap the guard rejection")
+	}
+	if !strings.Contains(err.Error(), "la porte est fermée") {
+		t.Errorf("expected error message to use the formatted reason, got %q", err.Error())
+	}
+}
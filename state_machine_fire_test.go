@@ -3,6 +3,8 @@ package stateless_test
 import (
 	"context"
 	"errors"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -87,6 +89,81 @@ func TestConcurrentFire(t *testing.T) {
 	// Just ensure no panics occurred
 }
 
+func TestConcurrentFire_ImmediateSerializes(t *testing.T) {
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringImmediate)
+
+	var mutex sync.Mutex
+	active, overlaps, entries := 0, 0, 0
+	sm.Configure(StateA).
+		PermitReentry(TriggerX).
+		OnEntry(func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			mutex.Lock()
+			active++
+			if active > 1 {
+				overlaps++
+			}
+			mutex.Unlock()
+
+			time.Sleep(time.Millisecond)
+			entries++
+
+			mutex.Lock()
+			active--
+			mutex.Unlock()
+			return nil
+		})
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sm.Fire(TriggerX, nil); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if overlaps != 0 {
+		t.Errorf("expected fires from several goroutines not to overlap, got %d overlaps", overlaps)
+	}
+	if entries != 8 {
+		t.Errorf("expected 8 entries, got %d", entries)
+	}
+}
+
+func TestConcurrentFire_ImmediateGoroutineFromActionWaits(t *testing.T) {
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringImmediate)
+
+	done := make(chan error, 1)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		Permit(TriggerY, StateC).
+		OnEntry(func(ctx context.Context, _ stateless.Transition[State, Trigger]) error {
+			// A trigger fired from another goroutine does not nest, even with the context of the action
+			go func() { done <- sm.FireCtx(ctx, TriggerY, nil) }()
+			time.Sleep(20 * time.Millisecond)
+			select {
+			case err := <-done:
+				t.Error("expected the fire from another goroutine to wait for the fire in progress")
+				done <- err
+			default:
+			}
+			return nil
+		})
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateC {
+		t.Errorf("expected StateC, got %v", sm.State())
+	}
+}
+
 func TestImmediateEntryAProcessedBeforeEnterB(t *testing.T) {
 	record := []string{}
 	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringImmediate)
@@ -233,3 +310,763 @@ func TestImmediateFiringOnEntryEndsUpInCorrectState(t *testing.T) {
 		t.Errorf("expected StateC, got %v", sm.State())
 	}
 }
+
+func TestFireResult_ReturnsFinalTransition(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).InitialTransition(StateC)
+	sm.Configure(StateC).SubstateOf(StateB)
+
+	transition, err := sm.FireResult(context.Background(), TriggerX, "args")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if transition.Source != StateA {
+		t.Errorf("expected source StateA, got %v", transition.Source)
+	}
+	if transition.Destination != StateC {
+		t.Errorf("expected destination StateC after initial transition, got %v", transition.Destination)
+	}
+	if transition.Trigger != TriggerX || transition.Args != "args" {
+		t.Errorf("unexpected trigger or args: %+v", transition)
+	}
+	if transition.Kind != stateless.TransitionExternal {
+		t.Errorf("expected external transition, got %v", transition.Kind)
+	}
+}
+
+func TestFireResult_ReportsInternalAndIgnored(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		InternalTransition(TriggerX, func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			return nil
+		}).
+		Ignore(TriggerY).
+		PermitReentry(TriggerZ)
+
+	internal, err := sm.FireResult(context.Background(), TriggerX, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if internal.Kind != stateless.TransitionInternal {
+		t.Errorf("expected internal transition, got %v", internal.Kind)
+	}
+
+	ignored, err := sm.FireResult(context.Background(), TriggerY, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ignored.Kind != stateless.TransitionIgnored {
+		t.Errorf("expected ignored transition, got %v", ignored.Kind)
+	}
+
+	reentry, err := sm.FireResult(context.Background(), TriggerZ, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reentry.Kind != stateless.TransitionExternal || !reentry.IsReentry() {
+		t.Errorf("expected external reentry transition, got %+v", reentry)
+	}
+}
+
+func TestFireResult_ReportsReentryAndInitialTransition(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB).
+		PermitReentry(TriggerY).
+		InitialTransition(StateC)
+	sm.Configure(StateB).
+		Permit(TriggerX, StateA).
+		Permit(TriggerZ, StateD)
+	sm.Configure(StateC).SubstateOf(StateA)
+	sm.Configure(StateD).
+		Permit(TriggerX, StateB).
+		OnEntry(func(ctx context.Context, _ stateless.Transition[State, Trigger]) error {
+			return sm.FireCtx(ctx, TriggerX, nil)
+		})
+
+	// The reentry of StateA applies its initial transition, so the final state is StateC
+	reentry, err := sm.FireResult(context.Background(), TriggerY, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reentry.Destination != StateC || !reentry.IsReentry() || !reentry.HasInitialTransition() {
+		t.Errorf("expected a reentry of StateA settling in StateC, got %+v", reentry)
+	}
+
+	left, err := sm.FireResult(context.Background(), TriggerX, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if left.Destination != StateB || left.IsReentry() || left.HasInitialTransition() {
+		t.Errorf("expected a plain transition into StateB, got %+v", left)
+	}
+
+	// Entering StateD fires back into StateB, which does not make the transition a reentry
+	nested, err := sm.FireResult(context.Background(), TriggerZ, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nested.Source != StateB || nested.Destination != StateB || nested.IsReentry() {
+		t.Errorf("expected a transition from StateB back to StateB that is not a reentry, got %+v", nested)
+	}
+
+	entered, err := sm.FireResult(context.Background(), TriggerX, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entered.Destination != StateC || entered.IsReentry() || !entered.HasInitialTransition() {
+		t.Errorf("expected a transition into StateC through an initial transition, got %+v", entered)
+	}
+}
+
+func TestFireResult_ReturnsError(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA)
+
+	transition, err := sm.FireResult(context.Background(), TriggerX, nil)
+
+	var invalidTransitionErr *stateless.InvalidTransitionError
+	if !errors.As(err, &invalidTransitionErr) {
+		t.Fatalf("expected InvalidTransitionError, got %v", err)
+	}
+	if transition != (stateless.Transition[State, Trigger]{}) {
+		t.Errorf("expected zero transition on error, got %+v", transition)
+	}
+}
+
+func TestFire_UnconfiguredState(t *testing.T) {
+	state := StateC
+	sm := stateless.NewStateMachineWithExternalStorage[State, Trigger](
+		func() State { return state },
+		func(s State) { state = s },
+	)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+
+	err := sm.Fire(TriggerX, nil)
+
+	var unconfiguredErr *stateless.UnconfiguredStateError
+	if !errors.As(err, &unconfiguredErr) {
+		t.Fatalf("expected UnconfiguredStateError, got %v", err)
+	}
+	if unconfiguredErr.State != StateC {
+		t.Errorf("expected state StateC, got %v", unconfiguredErr.State)
+	}
+	if state != StateC {
+		t.Errorf("expected state to remain StateC, got %v", state)
+	}
+}
+
+func TestIsFiring(t *testing.T) {
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued)
+
+	var firingInEntry bool
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			firingInEntry = sm.IsFiring()
+			return nil
+		})
+
+	if sm.IsFiring() {
+		t.Error("expected machine not to be firing before Fire")
+	}
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !firingInEntry {
+		t.Error("expected machine to be firing inside entry action")
+	}
+	if sm.IsFiring() {
+		t.Error("expected machine not to be firing after Fire returns")
+	}
+}
+
+func TestFireByName(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateB)
+	sm.Configure(StateA).Permit(TriggerY, StateC)
+	sm.Configure(StateB).
+		SubstateOf(StateA).
+		Permit(TriggerX, StateA)
+
+	// Inherited from the superstate
+	if err := sm.FireByName(context.Background(), "TriggerY", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateC {
+		t.Errorf("expected StateC, got %v", sm.State())
+	}
+
+	err := sm.FireByName(context.Background(), "TriggerX", nil)
+	var argErr *stateless.ArgumentError
+	if !errors.As(err, &argErr) {
+		t.Errorf("expected ArgumentError for trigger not configured in StateC, got %v", err)
+	}
+}
+
+type labelledTrigger struct {
+	id    int
+	label string
+}
+
+func (l labelledTrigger) String() string { return l.label }
+
+func TestFireByName_Ambiguous(t *testing.T) {
+	first := labelledTrigger{id: 1, label: "go"}
+	second := labelledTrigger{id: 2, label: "go"}
+
+	sm := stateless.NewStateMachine[State, labelledTrigger](StateA)
+	sm.Configure(StateA).
+		Permit(first, StateB).
+		Permit(second, StateC)
+
+	err := sm.FireByName(context.Background(), "go", nil)
+	var argErr *stateless.ArgumentError
+	if !errors.As(err, &argErr) {
+		t.Fatalf("expected ArgumentError for ambiguous name, got %v", err)
+	}
+	if sm.State() != StateA {
+		t.Errorf("expected state to be unchanged, got %v", sm.State())
+	}
+}
+
+// recordingQueue is a TriggerQueue that remembers every pushed trigger.
+type recordingQueue struct {
+	events []stateless.QueuedEvent[Trigger]
+	pushed []Trigger
+}
+
+func (q *recordingQueue) Push(event stateless.QueuedEvent[Trigger]) {
+	q.pushed = append(q.pushed, event.Trigger)
+	q.events = append(q.events, event)
+}
+
+func (q *recordingQueue) Pop() (stateless.QueuedEvent[Trigger], bool) {
+	if len(q.events) == 0 {
+		return stateless.QueuedEvent[Trigger]{}, false
+	}
+	event := q.events[0]
+	q.events = q.events[1:]
+	return event, true
+}
+
+func (q *recordingQueue) Len() int {
+	return len(q.events)
+}
+
+func (q *recordingQueue) Events() []stateless.QueuedEvent[Trigger] {
+	return slices.Clone(q.events)
+}
+
+func TestFiringQueued_CustomTriggerQueue(t *testing.T) {
+	queue := &recordingQueue{}
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued, queue)
+
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			if queue.Len() != 0 {
+				t.Errorf("expected empty queue while processing, got %d", queue.Len())
+			}
+			sm.Fire(TriggerY, nil)
+			if queue.Len() != 1 {
+				t.Errorf("expected TriggerY to be queued, got %d events", queue.Len())
+			}
+			return nil
+		}).
+		Permit(TriggerY, StateC)
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sm.State() != StateC {
+		t.Errorf("expected StateC, got %v", sm.State())
+	}
+	expected := []Trigger{TriggerX, TriggerY}
+	if len(queue.pushed) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, queue.pushed)
+	}
+	for i := range expected {
+		if queue.pushed[i] != expected[i] {
+			t.Errorf("expected %v at index %d, got %v", expected[i], i, queue.pushed[i])
+		}
+	}
+}
+
+func TestSetMaxImmediateDepth(t *testing.T) {
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringImmediate)
+	sm.SetMaxImmediateDepth(10)
+
+	entries := 0
+	sm.Configure(StateA).
+		PermitReentry(TriggerX).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			entries++
+			return sm.FireCtx(ctx, TriggerX, nil)
+		})
+
+	err := sm.Fire(TriggerX, nil)
+
+	var depthErr *stateless.MaxDepthExceededError
+	if !errors.As(err, &depthErr) {
+		t.Fatalf("expected MaxDepthExceededError, got %v", err)
+	}
+	if depthErr.MaxDepth != 10 {
+		t.Errorf("expected max depth 10, got %d", depthErr.MaxDepth)
+	}
+	if entries != 10 {
+		t.Errorf("expected 10 entries, got %d", entries)
+	}
+
+	// The depth is released once the outermost fire returns
+	sm.SetMaxImmediateDepth(1)
+	if err := sm.Fire(TriggerX, nil); !errors.As(err, &depthErr) {
+		t.Fatalf("expected MaxDepthExceededError, got %v", err)
+	}
+	if entries != 11 {
+		t.Errorf("expected 11 entries, got %d", entries)
+	}
+}
+
+func TestConcurrentQueriesDuringFire(t *testing.T) {
+	const steps = 200
+
+	// Only the initial state is configured: each fire routes to a new state whose representation
+	// is created while firing
+	sm := stateless.NewStateMachineWithMode[int, int](0, stateless.FiringQueued)
+	sm.Configure(0)
+	sm.OnUnhandledTriggerHandler(func(_ context.Context, state, _ int, _ any) (int, bool, error) {
+		return state + 1, true, nil
+	})
+
+	ctx := context.Background()
+	done := make(chan struct{})
+	var started, wg sync.WaitGroup
+	for range 4 {
+		started.Add(1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			started.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				sm.CanFire(ctx, 1, nil)
+				sm.GetPermittedTriggers(ctx, nil)
+				sm.IsInState(0)
+				sm.IsFiring()
+				sm.States()
+				sm.TransitionMatrix()
+				_ = sm.GetInfo().States
+			}
+		}()
+	}
+	started.Wait()
+
+	for range steps {
+		if err := sm.Fire(1, nil); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	if sm.State() != steps {
+		t.Errorf("expected state %d, got %d", steps, sm.State())
+	}
+}
+
+func TestFire_UnconfiguredStateAfterQuery(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+
+	if sm.CanFire(context.Background(), TriggerX, nil) {
+		t.Error("expected CanFire to be false in an unconfigured state")
+	}
+
+	var unconfiguredErr *stateless.UnconfiguredStateError
+	if err := sm.Fire(TriggerX, nil); !errors.As(err, &unconfiguredErr) {
+		t.Fatalf("expected UnconfiguredStateError, got %v", err)
+	}
+}
+
+func TestAliasTriggers(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.AliasTriggers(TriggerX, TriggerY, TriggerZ)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).Permit(TriggerX, StateA)
+
+	var fired []Trigger
+	sm.OnTransitioned(func(tr stateless.Transition[State, Trigger]) {
+		fired = append(fired, tr.Trigger)
+	})
+
+	if !sm.CanFire(context.Background(), TriggerY, nil) {
+		t.Error("expected alias TriggerY to be fireable")
+	}
+	if err := sm.Fire(TriggerY, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := sm.Fire(TriggerZ, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateA {
+		t.Errorf("expected StateA, got %v", sm.State())
+	}
+	if !slices.Equal(fired, []Trigger{TriggerX, TriggerX}) {
+		t.Errorf("expected transitions to report the canonical trigger, got %v", fired)
+	}
+}
+
+func TestAliasTriggers_PanicsOnChains(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.AliasTriggers(TriggerX, TriggerY)
+
+	assertPanics(t, "alias of itself", func() { sm.AliasTriggers(TriggerZ, TriggerZ) })
+	assertPanics(t, "alias as canonical", func() { sm.AliasTriggers(TriggerY, TriggerZ) })
+	assertPanics(t, "canonical as alias", func() { sm.AliasTriggers(TriggerZ, TriggerX) })
+}
+
+func TestFireAndWait_WaitsForQueueToDrain(t *testing.T) {
+	release := make(chan struct{})
+	entered := make(chan struct{})
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			close(entered)
+			<-release
+			return nil
+		}).
+		Permit(TriggerY, StateC)
+	sm.Configure(StateC)
+
+	fired := make(chan error, 1)
+	go func() { fired <- sm.Fire(TriggerX, nil) }()
+	<-entered
+
+	waited := make(chan error, 1)
+	go func() { waited <- sm.FireAndWait(context.Background(), TriggerY, nil) }()
+
+	// Unlike Fire, FireAndWait must not return while TriggerX is still being processed
+	select {
+	case err := <-waited:
+		t.Fatalf("expected FireAndWait to block, returned %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+
+	if err := <-waited; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateC {
+		t.Errorf("expected StateC once FireAndWait returned, got %v", sm.State())
+	}
+	if err := <-fired; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFireAndWait_ReturnsProcessingError(t *testing.T) {
+	release := make(chan struct{})
+	entered := make(chan struct{})
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+		close(entered)
+		<-release
+		return nil
+	})
+
+	go func() { _ = sm.Fire(TriggerX, nil) }()
+	<-entered
+
+	waited := make(chan error, 1)
+	go func() { waited <- sm.FireAndWait(context.Background(), TriggerZ, nil) }()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	var invalid *stateless.InvalidTransitionError
+	if err := <-waited; !errors.As(err, &invalid) {
+		t.Errorf("expected InvalidTransitionError, got %v", err)
+	}
+}
+
+func TestFireAndWait_FromActionFails(t *testing.T) {
+	var nestedErr error
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			nestedErr = sm.FireAndWait(ctx, TriggerY, nil)
+			return nil
+		}).
+		Permit(TriggerY, StateC)
+	sm.Configure(StateC)
+
+	if err := sm.FireAndWait(context.Background(), TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var invalid *stateless.InvalidOperationError
+	if !errors.As(nestedErr, &invalid) {
+		t.Errorf("expected InvalidOperationError from the nested call, got %v", nestedErr)
+	}
+	if sm.State() != StateB {
+		t.Errorf("expected StateB, got %v", sm.State())
+	}
+}
+
+func TestFireWithReport(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateB)
+	sm.AliasTriggers(TriggerX, TriggerZ)
+	sm.Configure(StateA).Permit(TriggerX, StateC)
+	sm.Configure(StateB).
+		SubstateOf(StateA).
+		PermitIf(TriggerY, StateD, func(ctx context.Context, args any) error {
+			return stateless.Retry("busy")
+		})
+	sm.Configure(StateC).
+		PermitIf(TriggerY, StateD, func(ctx context.Context, args any) error {
+			return stateless.Retry("busy")
+		}).
+		PermitIf(TriggerY, StateA, func(ctx context.Context, args any) error { return nil })
+	sm.Configure(StateD)
+	sm.OnUnhandledTrigger(func(state State, trigger Trigger, unmetGuards []error) {})
+
+	report, err := sm.FireWithReport(context.Background(), TriggerZ, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Transition.Destination != StateC || !report.Handled || report.HandlingState != StateA {
+		t.Errorf("expected TriggerX inherited from StateA to reach StateC, got %+v", report)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "alias") {
+		t.Errorf("expected an alias warning, got %v", report.Warnings)
+	}
+
+	report, err = sm.FireWithReport(context.Background(), TriggerY, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.HandlingState != StateC || len(report.Retries) != 1 || report.Retries[0].Reason != "busy" {
+		t.Errorf("expected the retry signal of the guard that did not handle the trigger, got %+v", report)
+	}
+
+	report, err = sm.FireWithReport(context.Background(), TriggerY, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Handled || len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "not handled") {
+		t.Errorf("expected an unhandled warning, got %+v", report)
+	}
+}
+
+func TestFireForValue(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	nextID := 0
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB).
+		InternalTransition(TriggerY, func(_ context.Context, tr stateless.Transition[State, Trigger]) error {
+			tr.SetResult("internal")
+			return nil
+		})
+	sm.Configure(StateB).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			nextID++
+			tr.SetResult(nextID)
+			// A trigger fired from an action does not set the value of the one being fired
+			return sm.FireCtx(ctx, TriggerY, nil)
+		}).
+		InternalTransition(TriggerY, func(_ context.Context, tr stateless.Transition[State, Trigger]) error {
+			tr.SetResult("nested")
+			return nil
+		}).
+		Permit(TriggerZ, StateA)
+
+	value, err := sm.FireForValue(context.Background(), TriggerX, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value != 1 {
+		t.Errorf("expected the value set on entry, got %v", value)
+	}
+
+	if value, err := sm.FireForValue(context.Background(), TriggerZ, nil); err != nil || value != nil {
+		t.Errorf("expected nil without a value set, got %v, %v", value, err)
+	}
+	if value, err := sm.FireForValue(context.Background(), TriggerY, nil); err != nil || value != "internal" {
+		t.Errorf("expected the value set by the internal action, got %v, %v", value, err)
+	}
+
+	// Transitions of triggers fired otherwise ignore SetResult
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := sm.FireForValue(context.Background(), TriggerX, nil); err == nil {
+		t.Error("expected an error for an unhandled trigger")
+	}
+}
+
+func TestFireOnly(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		Permit(TriggerY, StateC).
+		PermitIf(TriggerZ, StateA, func(_ context.Context, args any) error {
+			if args != "back" {
+				return stateless.Reject("not going back")
+			}
+			return nil
+		})
+	sm.Configure(StateC)
+
+	if err := sm.FireOnly(context.Background(), nil); err != nil || sm.State() != StateB {
+		t.Fatalf("expected the only trigger to move to StateB, got %v, %v", sm.State(), err)
+	}
+
+	var opErr *stateless.InvalidOperationError
+	err := sm.FireOnly(context.Background(), "back")
+	if !errors.As(err, &opErr) || !strings.Contains(err.Error(), "[TriggerY TriggerZ]") {
+		t.Errorf("expected an error listing both permitted triggers, got %v", err)
+	}
+	if sm.State() != StateB {
+		t.Errorf("expected nothing to be fired, got %v", sm.State())
+	}
+
+	if err := sm.FireOnly(context.Background(), nil); err != nil || sm.State() != StateC {
+		t.Fatalf("expected the guard to leave only TriggerY, got %v, %v", sm.State(), err)
+	}
+	if err := sm.FireOnly(context.Background(), nil); !errors.As(err, &opErr) {
+		t.Errorf("expected an error without permitted triggers, got %v", err)
+	}
+}
+
+func TestShutdown_StopsQueuedDrain(t *testing.T) {
+	queue := &recordingQueue{}
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued, queue)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		OnEntry(func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			sm.Fire(TriggerY, nil)
+			sm.Fire(TriggerZ, nil)
+			sm.Shutdown()
+			return nil
+		}).
+		Permit(TriggerY, StateC)
+	sm.Configure(StateC).Permit(TriggerZ, StateD)
+
+	if err := sm.Fire(TriggerX, nil); !errors.Is(err, stateless.ErrShuttingDown) {
+		t.Fatalf("expected ErrShuttingDown, got %v", err)
+	}
+	if sm.State() != StateB {
+		t.Errorf("expected the trigger in progress to complete and no other, got %v", sm.State())
+	}
+	if queue.Len() != 2 {
+		t.Errorf("expected the remaining events to stay queued, got %d", queue.Len())
+	}
+
+	if err := sm.FireAndWait(context.Background(), TriggerY, nil); !errors.Is(err, stateless.ErrShuttingDown) {
+		t.Errorf("expected ErrShuttingDown after shutdown, got %v", err)
+	}
+	if sm.State() != StateB || queue.Len() != 3 {
+		t.Errorf("expected the trigger to be queued without being processed, got %v with %d queued",
+			sm.State(), queue.Len())
+	}
+}
+
+func TestShutdown_Immediate(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		OnEntry(func(ctx context.Context, _ stateless.Transition[State, Trigger]) error {
+			sm.Shutdown()
+			return sm.FireCtx(ctx, TriggerY, nil)
+		}).
+		Permit(TriggerY, StateC)
+	sm.Configure(StateC).Permit(TriggerZ, StateD)
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("expected nested fires to complete, got %v", err)
+	}
+	if sm.State() != StateC {
+		t.Errorf("expected StateC, got %v", sm.State())
+	}
+	if err := sm.Fire(TriggerZ, nil); !errors.Is(err, stateless.ErrShuttingDown) || sm.State() != StateC {
+		t.Errorf("expected ErrShuttingDown without a transition, got %v in %v", err, sm.State())
+	}
+}
+
+type correlationKey struct{}
+
+func TestWithValue(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	var seen []any
+	record := func(ctx context.Context) {
+		seen = append(seen, ctx.Value(correlationKey{}))
+	}
+	sm.Configure(StateA).
+		PermitIf(TriggerX, StateB, func(ctx context.Context, _ any) error {
+			record(ctx)
+			return nil
+		})
+	sm.Configure(StateB).
+		OnEntry(func(ctx context.Context, _ stateless.Transition[State, Trigger]) error {
+			record(ctx)
+			return nil
+		}).
+		Permit(TriggerY, StateA)
+	sm.OnTransitionedWith(TriggerX, func(ctx context.Context, _ stateless.Transition[State, Trigger]) {
+		record(ctx)
+	})
+	sm.WithValue(correlationKey{}, "order-1")
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []any{"order-1", "order-1", "order-1"}; !slices.Equal(seen, expected) {
+		t.Errorf("expected the value in guards, actions and events, got %v", seen)
+	}
+
+	// The fire's context takes precedence
+	if err := sm.Fire(TriggerY, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	seen = nil
+	ctx := context.WithValue(context.Background(), correlationKey{}, "override")
+	if err := sm.FireCtx(ctx, TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []any{"override", "override", "override"}; !slices.Equal(seen, expected) {
+		t.Errorf("expected the fire's value, got %v", seen)
+	}
+
+	seen = nil
+	if err := sm.Clone(StateA).Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []any{nil, nil}; !slices.Equal(seen, expected) {
+		t.Errorf("expected clones not to carry the value, got %v", seen)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a non-comparable key")
+		}
+	}()
+	sm.WithValue([]string{"key"}, "value")
+}
+
+func BenchmarkFire_Toggle(b *testing.B) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).Permit(TriggerX, StateA)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		_ = sm.Fire(TriggerX, nil)
+	}
+}
@@ -2,6 +2,8 @@ package stateless_test
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/atlekbai/stateless"
@@ -153,3 +155,37 @@ func TestIgnoreIfFalseTriggerMustNotBeIgnored(t *testing.T) {
 		t.Errorf("expected StateC, got %v", sm.State())
 	}
 }
+
+func TestValidate_OverlappingUnguardedBehaviours(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB).
+		Ignore(TriggerX).
+		Permit(TriggerY, StateB).
+		IgnoreIf(TriggerY, func(_ context.Context, _ any) error { return nil })
+	sm.Configure(StateB).
+		Permit(TriggerZ, StateA)
+
+	err := sm.Validate()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	var opErr *stateless.InvalidOperationError
+	if !errors.As(err, &opErr) {
+		t.Fatalf("expected InvalidOperationError, got %T", err)
+	}
+	if !strings.Contains(err.Error(), "'TriggerX'") || strings.Contains(err.Error(), "'TriggerY'") {
+		t.Errorf("expected only TriggerX to be reported, got %q", err.Error())
+	}
+}
+
+func TestValidate_ValidConfiguration(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB).
+		IgnoreIf(TriggerX, func(_ context.Context, _ any) error { return stateless.Reject("never") })
+
+	if err := sm.Validate(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
@@ -2,6 +2,8 @@ package stateless_test
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"testing"
 
 	"github.com/atlekbai/stateless"
@@ -386,3 +388,316 @@ func TestPermitDynamicIf_Permits_Reentry_When_GuardCondition_Met(t *testing.T) {
 		t.Errorf("expected StateA, got %v", sm.State())
 	}
 }
+
+func TestPermitDynamicReentry_RunsExitAndEntry(t *testing.T) {
+	record := []string{}
+
+	sm := stateless.NewStateMachine[State, Trigger](StateB)
+	sm.Configure(StateA).
+		PermitDynamicReentry(TriggerX, func(_ context.Context, args any) (State, error) {
+			if args == "leave" {
+				return StateC, nil
+			}
+			return StateB, nil
+		})
+	sm.Configure(StateB).
+		SubstateOf(StateA).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			record = append(record, "EnterB")
+			return nil
+		}).
+		OnExit(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			record = append(record, "ExitB")
+			return nil
+		})
+	sm.Configure(StateC)
+
+	// Selector returns the current state: a true reentry
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateB {
+		t.Errorf("expected StateB, got %v", sm.State())
+	}
+
+	expected := []string{"ExitB", "EnterB"}
+	if len(record) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %v", len(expected), len(record), record)
+	}
+	for i := range expected {
+		if record[i] != expected[i] {
+			t.Errorf("expected %s at index %d, got %s", expected[i], i, record[i])
+		}
+	}
+
+	// Selector returns another state: a regular transition
+	if err := sm.Fire(TriggerX, "leave"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateC {
+		t.Errorf("expected StateC, got %v", sm.State())
+	}
+}
+
+func TestPermitDynamicReentry_ReentersSuperstate(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		reentry  bool
+		expected []string
+	}{
+		{"PermitDynamic", false, []string{"ExitB", "EnterB"}},
+		{"PermitDynamicReentry", true, []string{"ExitB", "ExitA", "EnterA", "EnterB"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			record := []string{}
+			recorder := func(event string) stateless.TransitionAction[State, Trigger] {
+				return func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+					record = append(record, event)
+					return nil
+				}
+			}
+			selector := func(_ context.Context, _ any) (State, error) { return StateA, nil }
+
+			sm := stateless.NewStateMachine[State, Trigger](StateB)
+			superstate := sm.Configure(StateA).
+				InitialTransition(StateB).
+				OnEntry(recorder("EnterA")).
+				OnExit(recorder("ExitA"))
+			if tt.reentry {
+				superstate.PermitDynamicReentry(TriggerX, selector)
+			} else {
+				superstate.PermitDynamic(TriggerX, selector)
+			}
+			sm.Configure(StateB).
+				SubstateOf(StateA).
+				OnEntry(recorder("EnterB")).
+				OnExit(recorder("ExitB"))
+
+			transition, err := sm.FireResult(context.Background(), TriggerX, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(record, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, record)
+			}
+			if transition.IsReentry() != tt.reentry {
+				t.Errorf("expected IsReentry to be %v, got %+v", tt.reentry, transition)
+			}
+		})
+	}
+}
+func TestPermitDefault_Precedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		trigger  Trigger
+		expected State
+	}{
+		{name: "explicit local permit wins", trigger: TriggerX, expected: StateD},
+		{name: "inherited superstate permit wins over local default", trigger: TriggerY, expected: StateC},
+		{name: "unconfigured trigger uses local default", trigger: TriggerZ, expected: StateA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := stateless.NewStateMachine[State, Trigger](StateB)
+			sm.Configure(StateA).
+				Permit(TriggerY, StateC)
+			sm.Configure(StateB).
+				SubstateOf(StateA).
+				Permit(TriggerX, StateD).
+				PermitDefault(StateA)
+			sm.Configure(StateC)
+			sm.Configure(StateD)
+
+			if err := sm.Fire(tt.trigger, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sm.State() != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, sm.State())
+			}
+		})
+	}
+}
+
+func TestPermitDefault_IgnoreAndRejectedGuardsWin(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Ignore(TriggerX).
+		PermitIf(TriggerY, StateB, func(_ context.Context, _ any) error { return stateless.Reject("closed") }).
+		PermitDefault(StateD)
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateA {
+		t.Errorf("expected ignored trigger to leave StateA, got %v", sm.State())
+	}
+
+	err := sm.Fire(TriggerY, nil)
+	var invalidTransitionErr *stateless.InvalidTransitionError
+	if !errors.As(err, &invalidTransitionErr) {
+		t.Fatalf("expected InvalidTransitionError for rejected guard, got %v", err)
+	}
+	if !sm.CanFire(context.Background(), TriggerZ, nil) {
+		t.Error("expected CanFire to report the default transition")
+	}
+}
+
+func TestPermitDefault_InheritedFromSuperstate(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateB)
+	sm.Configure(StateA).PermitDefault(StateD)
+	sm.Configure(StateB).SubstateOf(StateA)
+	sm.Configure(StateD)
+
+	if err := sm.Fire(TriggerZ, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateD {
+		t.Errorf("expected StateD, got %v", sm.State())
+	}
+}
+
+func TestPermitIdentity_PanicsByDefault(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected panic for identity transition")
+		}
+	}()
+	sm.Configure(StateA).Permit(TriggerX, StateA)
+}
+
+func TestSetPermitIdentityAsReentry(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.SetPermitIdentityAsReentry(true)
+
+	var record []string
+	sm.Configure(StateA).
+		Permit(TriggerX, StateA).
+		PermitIf(TriggerY, StateA, func(_ context.Context, _ any) error { return errors.New("closed") }).
+		OnEntry(func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			record = append(record, "entry")
+			return nil
+		}).
+		OnExit(func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			record = append(record, "exit")
+			return nil
+		})
+
+	var reentry bool
+	sm.OnTransitioned(func(tr stateless.Transition[State, Trigger]) { reentry = tr.IsReentry() })
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reentry {
+		t.Error("expected transition to be a reentry")
+	}
+	expected := []string{"exit", "entry"}
+	if len(record) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, record)
+	}
+	for i := range expected {
+		if record[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, record)
+		}
+	}
+
+	if err := sm.Fire(TriggerY, nil); err == nil {
+		t.Error("expected guarded reentry to be rejected")
+	}
+}
+
+func TestPermitDynamic_SelectorReceivesContextAndCanAbort(t *testing.T) {
+	type ctxKey struct{}
+	selectorErr := errors.New("no destination")
+	var seen any
+	exited := false
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		PermitDynamic(TriggerX, func(ctx context.Context, args any) (State, error) {
+			seen = ctx.Value(ctxKey{})
+			if args == nil {
+				return StateA, selectorErr
+			}
+			return StateB, nil
+		}).
+		OnExit(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			exited = true
+			return nil
+		})
+	sm.Configure(StateB)
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "trace")
+	if err := sm.FireCtx(ctx, TriggerX, nil); !errors.Is(err, selectorErr) {
+		t.Fatalf("expected selector error, got %v", err)
+	}
+	if seen != "trace" {
+		t.Errorf("expected selector to receive the fire context, got %v", seen)
+	}
+	if exited || sm.State() != StateA {
+		t.Errorf("expected the fire to abort in StateA without exiting, got %v (exited: %v)", sm.State(), exited)
+	}
+
+	if err := sm.FireCtx(ctx, TriggerX, "go"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateB {
+		t.Errorf("expected StateB, got %v", sm.State())
+	}
+}
+
+func TestPermitIfExhaustive(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).PermitIfExhaustive(TriggerX,
+		stateless.Branch[State]{
+			Guard: func(ctx context.Context, args any) error {
+				if args.(int) < 0 {
+					return nil
+				}
+				return stateless.Reject("not negative")
+			},
+			Dest:        StateB,
+			Description: "negative",
+		},
+		stateless.Branch[State]{
+			Guard: func(ctx context.Context, args any) error {
+				if args.(int) > 0 {
+					return nil
+				}
+				return stateless.Reject("not positive")
+			},
+			Dest:        StateC,
+			Description: "positive",
+		},
+	)
+	sm.Configure(StateB)
+	sm.Configure(StateC)
+
+	err := sm.Fire(TriggerX, 0)
+	var noMatch *stateless.NoBranchMatchedError
+	if !errors.As(err, &noMatch) {
+		t.Fatalf("expected NoBranchMatchedError, got %v", err)
+	}
+	if len(noMatch.Branches) != 2 ||
+		noMatch.Branches[0].Destination != StateB || noMatch.Branches[0].Description != "negative" ||
+		noMatch.Branches[1].Destination != StateC || noMatch.Branches[1].Description != "positive" {
+		t.Errorf("expected both branches to be reported, got %+v", noMatch.Branches)
+	}
+	expected := "no branch of trigger 'TriggerX' matched in state 'StateA'; evaluated branches: " +
+		"to 'StateB' when negative: not negative; to 'StateC' when positive: not positive"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+	if !stateless.IsGuardRejection(noMatch.Branches[0].Reason) {
+		t.Errorf("expected the guard's rejection as reason, got %v", noMatch.Branches[0].Reason)
+	}
+
+	if err := sm.Fire(TriggerX, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateC {
+		t.Errorf("expected StateC, got %v", sm.State())
+	}
+}
@@ -0,0 +1,110 @@
+package stateless
+
+import (
+	"context"
+	"errors"
+)
+
+// Event is a trigger together with its arguments.
+type Event[TTrigger comparable] struct {
+	// Trigger is the trigger to fire.
+	Trigger TTrigger `json:"trigger"`
+
+	// Args contains the arguments passed with the trigger.
+	Args any `json:"args,omitempty"`
+}
+
+// FireSequence fires the given events in order as one batch. If any event fails, the machine is
+// moved back to the state it was in before the sequence started, as with GoTo: exit and entry
+// actions run, the move is reported with Kind set to TransitionForced, and it is recorded as the
+// previous state and in the history. The error is returned.
+//
+// Only the state position is rolled back: side effects of actions that already ran cannot be undone.
+// No other fire is processed while the sequence runs. In FiringImmediate mode, fires from other
+// goroutines wait for it, and a sequence fired from an action nests. In FiringQueued mode, the
+// sequence waits for the queue to be processed, and triggers fired while it runs, including from its
+// actions, are queued and processed after it; FireSequence cannot be called from an action of a
+// queued state machine and returns an InvalidOperationError.
+func (sm *StateMachine[TState, TTrigger]) FireSequence(ctx context.Context, events []Event[TTrigger]) error {
+	if sm.firingMode == FiringQueued {
+		return sm.fireSequenceQueued(ctx, events)
+	}
+
+	return sm.runImmediate(func() error {
+		return sm.fireSequence(ctx, events, sm.FireCtx)
+	})
+}
+
+// fireSequenceQueued runs FireSequence in FiringQueued mode, holding the firing flag so that the
+// events of the sequence are processed without others in between, then processes the queue.
+func (sm *StateMachine[TState, TTrigger]) fireSequenceQueued(ctx context.Context, events []Event[TTrigger]) error {
+	if ctx.Value(drainingQueueKey{}) == any(sm) {
+		return &InvalidOperationError{
+			Message: "FireSequence cannot be called from an action of a queued state machine",
+		}
+	}
+	if err := sm.waitForQueue(ctx); err != nil {
+		return err
+	}
+
+	ctx = context.WithValue(ctx, drainingQueueKey{}, any(sm))
+	err := sm.fireSequence(ctx, events, func(ctx context.Context, tr TTrigger, args any) error {
+		_, err := sm.processTrigger(ctx, tr, args)
+		return err
+	})
+	_, queueErr := sm.processQueue()
+	return errors.Join(err, queueErr)
+}
+
+// waitForQueue waits until no trigger is being processed in FiringQueued mode and sets the firing
+// flag, which the caller must clear with stopFiring or processQueue. It returns ErrShuttingDown
+// once Shutdown was called, and ctx.Err() if ctx is done first.
+func (sm *StateMachine[TState, TTrigger]) waitForQueue(ctx context.Context) error {
+	for {
+		sm.mutex.Lock()
+		if sm.shuttingDown.Load() {
+			sm.mutex.Unlock()
+			return ErrShuttingDown
+		}
+		if !sm.firing {
+			sm.firing = true
+			sm.mutex.Unlock()
+			return nil
+		}
+		done := make(chan error, 1)
+		sm.queueWaiters = append(sm.queueWaiters, done)
+		sm.mutex.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// fireSequence fires events with fire and rolls the state back on the first error. The caller
+// must keep other fires from being processed meanwhile.
+func (sm *StateMachine[TState, TTrigger]) fireSequence(
+	ctx context.Context,
+	events []Event[TTrigger],
+	fire func(ctx context.Context, tr TTrigger, args any) error,
+) error {
+	start := sm.State()
+
+	for _, event := range events {
+		err := fire(ctx, event.Trigger, event.Args)
+		if err == nil {
+			continue
+		}
+		if sm.State() == start {
+			return err
+		}
+		if rollbackErr := sm.goTo(ctx, start, true); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+package stateless_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/atlekbai/stateless"
+)
+
+func TestFireSequence_FiresAllEvents(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).Permit(TriggerY, StateC)
+
+	err := sm.FireSequence(context.Background(), []stateless.Event[Trigger]{
+		{Trigger: TriggerX},
+		{Trigger: TriggerY},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateC {
+		t.Errorf("expected StateC, got %v", sm.State())
+	}
+}
+
+func TestFireSequence_RollsBackOnError(t *testing.T) {
+	record := []string{}
+	entryErr := errors.New("entry failed")
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			record = append(record, "EnterA")
+			return nil
+		})
+	sm.Configure(StateB).
+		Permit(TriggerY, StateC).
+		OnExit(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			record = append(record, "ExitB")
+			return nil
+		})
+	sm.Configure(StateC).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			record = append(record, "EnterC")
+			return entryErr
+		}).
+		OnExit(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			record = append(record, "ExitC")
+			return nil
+		})
+
+	err := sm.FireSequence(context.Background(), []stateless.Event[Trigger]{
+		{Trigger: TriggerX},
+		{Trigger: TriggerY},
+		{Trigger: TriggerZ},
+	})
+	if !errors.Is(err, entryErr) {
+		t.Fatalf("expected entry error, got %v", err)
+	}
+	if sm.State() != StateA {
+		t.Errorf("expected state to be rolled back to StateA, got %v", sm.State())
+	}
+
+	expected := []string{"ExitB", "EnterC", "ExitC", "EnterA"}
+	if len(record) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %v", len(expected), len(record), record)
+	}
+	for i := range expected {
+		if record[i] != expected[i] {
+			t.Errorf("expected %s at index %d, got %s", expected[i], i, record[i])
+		}
+	}
+}
+
+func TestFireSequence_FirstEventFailsLeavesStateUntouched(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+
+	err := sm.FireSequence(context.Background(), []stateless.Event[Trigger]{{Trigger: TriggerY}})
+
+	var invalidTransitionErr *stateless.InvalidTransitionError
+	if !errors.As(err, &invalidTransitionErr) {
+		t.Fatalf("expected InvalidTransitionError, got %v", err)
+	}
+	if sm.State() != StateA {
+		t.Errorf("expected StateA, got %v", sm.State())
+	}
+}
+
+func TestFireSequence_RollbackIsForced(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.EnableHistory(5)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).Permit(TriggerY, StateC)
+
+	var transitions []stateless.Transition[State, Trigger]
+	sm.OnTransitioned(func(tr stateless.Transition[State, Trigger]) {
+		transitions = append(transitions, tr)
+	})
+
+	err := sm.FireSequence(context.Background(), []stateless.Event[Trigger]{
+		{Trigger: TriggerX},
+		{Trigger: TriggerZ},
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if len(transitions) != 2 {
+		t.Fatalf("expected 2 transitions, got %v", transitions)
+	}
+	rollback := transitions[1]
+	if rollback.Kind != stateless.TransitionForced || rollback.Source != StateB || rollback.Destination != StateA {
+		t.Errorf("expected a forced transition from StateB to StateA, got %+v", rollback)
+	}
+	if previous, ok := sm.PreviousState(); !ok || previous != StateB {
+		t.Errorf("expected previous state StateB, got %v, %v", previous, ok)
+	}
+	if sm.HistoryLength() != 2 {
+		t.Errorf("expected 2 states in the history, got %d", sm.HistoryLength())
+	}
+}
+
+func TestFireSequence_QueuedDefersOtherFires(t *testing.T) {
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		Permit(TriggerY, StateC).
+		Permit(TriggerZ, StateD).
+		OnEntry(func(ctx context.Context, _ stateless.Transition[State, Trigger]) error {
+			return sm.FireCtx(ctx, TriggerZ, nil)
+		})
+
+	var states []State
+	sm.OnTransitioned(func(tr stateless.Transition[State, Trigger]) {
+		states = append(states, tr.Destination)
+	})
+
+	err := sm.FireSequence(context.Background(), []stateless.Event[Trigger]{
+		{Trigger: TriggerX},
+		{Trigger: TriggerY},
+	})
+
+	// TriggerZ is only processed after the sequence, and is not permitted in StateC
+	var invalidTransitionErr *stateless.InvalidTransitionError
+	if !errors.As(err, &invalidTransitionErr) {
+		t.Fatalf("expected InvalidTransitionError, got %v", err)
+	}
+	if !slices.Equal(states, []State{StateB, StateC}) {
+		t.Errorf("expected transitions to StateB and StateC, got %v", states)
+	}
+}
+
+func TestFireSequence_QueuedFromActionFails(t *testing.T) {
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued)
+
+	var sequenceErr error
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		Permit(TriggerY, StateC).
+		OnEntry(func(ctx context.Context, _ stateless.Transition[State, Trigger]) error {
+			sequenceErr = sm.FireSequence(ctx, []stateless.Event[Trigger]{{Trigger: TriggerY}})
+			return nil
+		})
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var invalidOperationErr *stateless.InvalidOperationError
+	if !errors.As(sequenceErr, &invalidOperationErr) {
+		t.Errorf("expected InvalidOperationError, got %v", sequenceErr)
+	}
+	if sm.State() != StateB {
+		t.Errorf("expected StateB, got %v", sm.State())
+	}
+}
@@ -2,6 +2,8 @@ package stateless_test
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"testing"
 
 	"github.com/atlekbai/stateless"
@@ -52,6 +54,28 @@ func TestIsInState_WithSubstates(t *testing.T) {
 	}
 }
 
+func TestActiveStates(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateD)
+	sm.Configure(StateB)
+	sm.Configure(StateC).SubstateOf(StateB)
+	sm.Configure(StateD).SubstateOf(StateC)
+
+	active := sm.ActiveStates()
+	expected := []State{StateD, StateC, StateB}
+	if !slices.Equal(active, expected) {
+		t.Errorf("expected %v, got %v", expected, active)
+	}
+}
+
+func TestActiveStates_UnconfiguredState(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+
+	active := sm.ActiveStates()
+	if !slices.Equal(active, []State{StateA}) {
+		t.Errorf("expected [StateA], got %v", active)
+	}
+}
+
 func TestSubstateTransition_OverridesSuperstate(t *testing.T) {
 	sm := stateless.NewStateMachine[State, Trigger](StateA)
 
@@ -308,3 +332,299 @@ func TestWhenTransitioningWithinSameSuperstate(t *testing.T) {
 		}
 	}
 }
+
+func TestThreeLevelHierarchy_OnlyStatesBelowCommonAncestorAreExitedAndEntered(t *testing.T) {
+	testCases := []struct {
+		name     string
+		from     string
+		to       string
+		expected []string
+	}{
+		{
+			name:     "siblings under same parent",
+			from:     "Leaf1",
+			to:       "Leaf2",
+			expected: []string{"Exit Leaf1", "Enter Leaf2"},
+		},
+		{
+			name:     "cousins under same root",
+			from:     "Leaf1",
+			to:       "Leaf3",
+			expected: []string{"Exit Leaf1", "Exit Mid1", "Enter Mid2", "Enter Leaf3"},
+		},
+		{
+			name:     "leaving the hierarchy",
+			from:     "Leaf1",
+			to:       "Outside",
+			expected: []string{"Exit Leaf1", "Exit Mid1", "Exit Root", "Enter Outside"},
+		},
+		{
+			name:     "entering the hierarchy",
+			from:     "Outside",
+			to:       "Leaf3",
+			expected: []string{"Exit Outside", "Enter Root", "Enter Mid2", "Enter Leaf3"},
+		},
+		{
+			name:     "leaf to uncle",
+			from:     "Leaf1",
+			to:       "Mid2",
+			expected: []string{"Exit Leaf1", "Exit Mid1", "Enter Mid2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			record := []string{}
+			sm := stateless.NewStateMachine[string, Trigger](tc.from)
+
+			// Root > Mid1 > {Leaf1, Leaf2}, Root > Mid2 > Leaf3, Outside
+			for _, s := range []string{"Root", "Mid1", "Mid2", "Leaf1", "Leaf2", "Leaf3", "Outside"} {
+				sm.Configure(s).
+					OnEntry(func(ctx context.Context, tr stateless.Transition[string, Trigger]) error {
+						record = append(record, "Enter "+s)
+						return nil
+					}).
+					OnExit(func(ctx context.Context, tr stateless.Transition[string, Trigger]) error {
+						record = append(record, "Exit "+s)
+						return nil
+					})
+			}
+			sm.Configure("Mid1").SubstateOf("Root")
+			sm.Configure("Mid2").SubstateOf("Root")
+			sm.Configure("Leaf1").SubstateOf("Mid1")
+			sm.Configure("Leaf2").SubstateOf("Mid1")
+			sm.Configure("Leaf3").SubstateOf("Mid2")
+			sm.Configure(tc.from).Permit(TriggerX, tc.to)
+
+			if err := sm.Fire(TriggerX, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(record) != len(tc.expected) {
+				t.Fatalf("expected %d events, got %d: %v", len(tc.expected), len(record), record)
+			}
+			for i := range tc.expected {
+				if record[i] != tc.expected[i] {
+					t.Errorf("expected %s at index %d, got %s", tc.expected[i], i, record[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSubstateOf_SuperstateConfiguredLater(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateB)
+
+	// Reference StateC as superstate before it is configured
+	sm.Configure(StateB).SubstateOf(StateC)
+	sm.Configure(StateC).Permit(TriggerX, StateA)
+
+	if !sm.IsInState(StateC) {
+		t.Error("expected StateB to be in superstate StateC")
+	}
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("expected superstate transition to be inherited, got %v", err)
+	}
+	if sm.State() != StateA {
+		t.Errorf("expected StateA, got %v", sm.State())
+	}
+}
+
+func TestSubstateOf_CircularRelationshipPanics(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).SubstateOf(StateB)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected panic for circular superstate relationship")
+		}
+	}()
+	sm.Configure(StateB).SubstateOf(StateA)
+}
+
+func TestOutgoingTransitions(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateC).
+		Permit(TriggerY, StateD)
+	sm.Configure(StateB).
+		SubstateOf(StateA).
+		Permit(TriggerX, StateD).
+		Permit(TriggerZ, StateC)
+	sm.Configure(StateC)
+	sm.Configure(StateD)
+
+	transitions := sm.OutgoingTransitions(StateB)
+
+	expected := []struct {
+		trigger     Trigger
+		destination State
+	}{
+		{TriggerX, StateD},
+		{TriggerY, StateD},
+		{TriggerZ, StateC},
+	}
+	if len(transitions) != len(expected) {
+		t.Fatalf("expected %d transitions, got %d: %+v", len(expected), len(transitions), transitions)
+	}
+	for i, want := range expected {
+		got := transitions[i]
+		if got.Trigger.UnderlyingTrigger != want.trigger || got.DestinationState.UnderlyingState != want.destination {
+			t.Errorf("expected %v -> %v at index %d, got %v -> %v",
+				want.trigger, want.destination, i, got.Trigger.UnderlyingTrigger, got.DestinationState.UnderlyingState)
+		}
+	}
+
+	if got := sm.OutgoingTransitions(StateA); len(got) != 2 {
+		t.Errorf("expected 2 transitions for StateA, got %d", len(got))
+	}
+	if got := sm.OutgoingTransitions(State(99)); got != nil {
+		t.Errorf("expected nil for unconfigured state, got %+v", got)
+	}
+}
+
+func TestHandlingState(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateB)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateC).
+		Permit(TriggerY, StateC)
+	sm.Configure(StateB).
+		SubstateOf(StateA).
+		Permit(TriggerY, StateD).
+		PermitIf(TriggerZ, StateD, func(_ context.Context, _ any) error { return stateless.Reject("closed") })
+
+	ctx := context.Background()
+	tests := []struct {
+		trigger  Trigger
+		expected State
+		handled  bool
+	}{
+		{TriggerX, StateA, true},
+		{TriggerY, StateB, true},
+		{TriggerZ, State(0), false},
+	}
+	for _, tt := range tests {
+		state, ok := sm.HandlingState(ctx, tt.trigger, nil)
+		if ok != tt.handled || state != tt.expected {
+			t.Errorf("%v: expected (%v, %v), got (%v, %v)", tt.trigger, tt.expected, tt.handled, state, ok)
+		}
+	}
+}
+
+func TestTransitionMatrix(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB).
+		Permit(TriggerY, StateC).
+		PermitReentry(TriggerZ)
+	sm.Configure(StateB).
+		SubstateOf(StateA).
+		Ignore(TriggerX).
+		PermitDynamic(TriggerY, func(_ context.Context, _ any) (State, error) { return StateD, nil })
+	sm.Configure(StateC).
+		InternalTransition(TriggerX, func(_ context.Context, _ stateless.Transition[State, Trigger]) error { return nil })
+
+	matrix := sm.TransitionMatrix()
+
+	expected := map[State]map[Trigger]State{
+		StateA: {TriggerX: StateB, TriggerY: StateC, TriggerZ: StateA},
+		StateB: {TriggerY: State(0), TriggerZ: StateA},
+		StateC: {},
+	}
+	if len(matrix) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, matrix)
+	}
+	for state, row := range expected {
+		if len(matrix[state]) != len(row) {
+			t.Errorf("%v: expected %v, got %v", state, row, matrix[state])
+			continue
+		}
+		for trigger, dst := range row {
+			if got, ok := matrix[state][trigger]; !ok || got != dst {
+				t.Errorf("%v --%v-->: expected %v, got %v", state, trigger, dst, got)
+			}
+		}
+	}
+}
+
+func TestOnGuardEvaluated(t *testing.T) {
+	type evaluation struct {
+		trigger     Trigger
+		description string
+		passed      bool
+	}
+
+	sm := stateless.NewStateMachine[State, Trigger](StateB)
+	sm.Configure(StateA).
+		PermitIf(TriggerX, StateC, func(_ context.Context, _ any) error { return nil }, "superstate open")
+	sm.Configure(StateB).
+		SubstateOf(StateA).
+		PermitIf(TriggerX, StateD, func(_ context.Context, _ any) error {
+			return stateless.Reject("closed")
+		}, "substate open")
+
+	var evaluations []evaluation
+	var rejection error
+	sm.OnGuardEvaluated(func(trigger Trigger, description string, passed bool, err error) {
+		evaluations = append(evaluations, evaluation{trigger, description, passed})
+		if !passed {
+			rejection = err
+		}
+	})
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateC {
+		t.Errorf("expected fallthrough to the superstate transition to StateC, got %v", sm.State())
+	}
+
+	expected := []evaluation{
+		{TriggerX, "substate open", false},
+		{TriggerX, "superstate open", true},
+	}
+	if !slices.Equal(evaluations, expected) {
+		t.Errorf("expected %v, got %v", expected, evaluations)
+	}
+	if !stateless.IsGuardRejection(rejection) {
+		t.Errorf("expected the rejection to be reported, got %v", rejection)
+	}
+}
+
+func TestOnGuardError(t *testing.T) {
+	broken := errors.New("database unavailable")
+	sm := stateless.NewStateMachine[State, Trigger](StateB)
+	sm.Configure(StateA).Permit(TriggerX, StateC)
+	sm.Configure(StateB).
+		SubstateOf(StateA).
+		PermitIf(TriggerX, StateD, func(_ context.Context, _ any) error { return broken }, "database check").
+		PermitIf(TriggerY, StateD, func(_ context.Context, _ any) error {
+			return stateless.Reject("closed")
+		}, "closed")
+
+	type guardError struct {
+		trigger Trigger
+		err     error
+	}
+	var reported []guardError
+	sm.OnGuardError(func(_ context.Context, trigger Trigger, err error) {
+		reported = append(reported, guardError{trigger, err})
+	})
+
+	if err := sm.Fire(TriggerX, nil); !errors.Is(err, broken) {
+		t.Fatalf("expected the guard error from Fire, got %v", err)
+	}
+	if sm.State() != StateB {
+		t.Errorf("expected the guard error not to fall through to the superstate, got %v", sm.State())
+	}
+	if len(reported) != 1 || reported[0].trigger != TriggerX || !errors.Is(reported[0].err, broken) {
+		t.Errorf("expected the guard error to be reported for TriggerX, got %v", reported)
+	}
+
+	if err := sm.Fire(TriggerY, nil); err == nil {
+		t.Fatal("expected the rejected trigger to be unhandled")
+	}
+	if len(reported) != 1 {
+		t.Errorf("expected rejections not to be reported, got %v", reported)
+	}
+}
@@ -2,7 +2,11 @@ package stateless_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/atlekbai/stateless"
@@ -157,6 +161,46 @@ func TestGetPermittedTriggers(t *testing.T) {
 	}
 }
 
+// States and Triggers tests
+
+func TestStates_ReturnsConfiguredStatesSorted(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateD).Permit(TriggerX, StateA)
+	sm.Configure(StateB).Permit(TriggerY, StateD)
+	sm.Configure(StateA).Permit(TriggerZ, StateB)
+
+	states := sm.States()
+	expected := []State{StateA, StateB, StateD}
+	if len(states) != len(expected) {
+		t.Fatalf("expected %d states, got %d: %v", len(expected), len(states), states)
+	}
+	for i := range expected {
+		if states[i] != expected[i] {
+			t.Errorf("expected %v at index %d, got %v", expected[i], i, states[i])
+		}
+	}
+}
+
+func TestTriggers_ReturnsUnionOfTriggersSorted(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerZ, StateB).
+		Ignore(TriggerX)
+	sm.Configure(StateB).
+		Permit(TriggerX, StateA)
+
+	triggers := sm.Triggers()
+	expected := []Trigger{TriggerX, TriggerZ}
+	if len(triggers) != len(expected) {
+		t.Fatalf("expected %d triggers, got %d: %v", len(expected), len(triggers), triggers)
+	}
+	for i := range expected {
+		if triggers[i] != expected[i] {
+			t.Errorf("expected %v at index %d, got %v", expected[i], i, triggers[i])
+		}
+	}
+}
+
 // GetInfo test
 
 func TestGetInfo(t *testing.T) {
@@ -199,6 +243,122 @@ func TestGetInfo_ShouldReturnEntryAction(t *testing.T) {
 	}
 }
 
+func TestGetInfo_IsCachedUntilConfigurationChanges(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).Permit(TriggerY, StateA)
+
+	first := sm.GetInfo()
+	second := sm.GetInfo()
+	if first != second {
+		t.Error("expected GetInfo to return cached info when configuration is unchanged")
+	}
+
+	// Firing does not change configuration
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.GetInfo() != first {
+		t.Error("expected GetInfo to remain cached after firing a trigger")
+	}
+}
+
+func TestGetInfo_InvalidatedByLateConfigure(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB)
+
+	before := sm.GetInfo()
+	if len(before.States) != 2 {
+		t.Fatalf("expected 2 states, got %d", len(before.States))
+	}
+
+	sm.Configure(StateC).Permit(TriggerY, StateA)
+
+	after := sm.GetInfo()
+	if after == before {
+		t.Fatal("expected GetInfo to rebuild info after configuration changed")
+	}
+	if len(after.States) != 3 {
+		t.Errorf("expected 3 states, got %d", len(after.States))
+	}
+
+	// Mutating an existing state must also invalidate the cache
+	sm.Configure(StateA).OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error { return nil })
+
+	latest := sm.GetInfo()
+	if latest == after {
+		t.Fatal("expected GetInfo to rebuild info after adding an entry action")
+	}
+	for _, info := range latest.States {
+		if info.UnderlyingState == StateA && len(info.EntryActions) != 1 {
+			t.Errorf("expected 1 entry action on StateA, got %d", len(info.EntryActions))
+		}
+	}
+}
+
+func TestGetInfo_IsSorted(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateD).Permit(TriggerZ, StateA).Permit(TriggerX, StateB).Permit(TriggerY, StateC)
+	sm.Configure(StateC).Ignore(TriggerZ).Ignore(TriggerX)
+	sm.Configure(StateB).
+		PermitDynamic(TriggerY, func(ctx context.Context, args any) (State, error) { return StateA, nil }).
+		PermitDynamic(TriggerX, func(ctx context.Context, args any) (State, error) { return StateC, nil })
+	sm.Configure(StateA)
+
+	info := sm.GetInfo()
+
+	var states []State
+	for _, s := range info.States {
+		states = append(states, s.UnderlyingState.(State))
+	}
+	if !slices.Equal(states, []State{StateA, StateB, StateC, StateD}) {
+		t.Errorf("expected states in order A, B, C, D, got %v", states)
+	}
+
+	var fixed, dynamic, ignored []any
+	for _, tr := range info.States[3].FixedTransitions {
+		fixed = append(fixed, tr.Trigger.UnderlyingTrigger)
+	}
+	for _, tr := range info.States[1].DynamicTransitions {
+		dynamic = append(dynamic, tr.Trigger.UnderlyingTrigger)
+	}
+	for _, tr := range info.States[2].IgnoredTriggers {
+		ignored = append(ignored, tr.Trigger.UnderlyingTrigger)
+	}
+	if !slices.Equal(fixed, []any{TriggerX, TriggerY, TriggerZ}) {
+		t.Errorf("expected fixed transitions ordered by trigger, got %v", fixed)
+	}
+	if !slices.Equal(dynamic, []any{TriggerX, TriggerY}) {
+		t.Errorf("expected dynamic transitions ordered by trigger, got %v", dynamic)
+	}
+	if !slices.Equal(ignored, []any{TriggerX, TriggerZ}) {
+		t.Errorf("expected ignored triggers ordered by trigger, got %v", ignored)
+	}
+}
+
+func TestGetInfo_IsSortedForComparableStructs(t *testing.T) {
+	type point struct{ X, Y int }
+	sm := stateless.NewStateMachine[point, string](point{2, 0})
+	sm.Configure(point{2, 0}).Permit("b", point{0, 1}).Permit("a", point{1, 0})
+	sm.Configure(point{1, 0})
+	sm.Configure(point{0, 1})
+
+	info := sm.GetInfo()
+
+	var states []point
+	for _, s := range info.States {
+		states = append(states, s.UnderlyingState.(point))
+	}
+	if !slices.Equal(states, []point{{0, 1}, {1, 0}, {2, 0}}) {
+		t.Errorf("expected states ordered by formatted value, got %v", states)
+	}
+	fixed := info.States[2].FixedTransitions
+	if len(fixed) != 2 || fixed[0].Trigger.UnderlyingTrigger != "a" || fixed[1].Trigger.UnderlyingTrigger != "b" {
+		t.Errorf("expected transitions ordered a, b, got %v", fixed)
+	}
+}
+
 // String representation test
 
 func TestStateMachine_String(t *testing.T) {
@@ -217,6 +377,7 @@ func TestOnUnhandledTrigger(t *testing.T) {
 	var unhandledGuards []error
 
 	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA)
 	sm.OnUnhandledTrigger(func(state State, trigger Trigger, unmetGuards []error) {
 		unhandledState = state
 		unhandledTrigger = trigger
@@ -239,6 +400,37 @@ func TestOnUnhandledTrigger(t *testing.T) {
 	}
 }
 
+func TestSetUnhandledReturnsError(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Ignore(TriggerY)
+	called := 0
+	sm.OnUnhandledTrigger(func(_ State, _ Trigger, _ []error) { called++ })
+	sm.SetUnhandledReturnsError(true)
+
+	err := sm.Fire(TriggerX, nil)
+	if !errors.Is(err, stateless.ErrTriggerUnhandled) {
+		t.Fatalf("expected ErrTriggerUnhandled, got %v", err)
+	}
+	if called != 1 {
+		t.Errorf("expected OnUnhandledTrigger to be called before returning the error, got %d calls", called)
+	}
+	if !strings.Contains(err.Error(), "TriggerX") || !strings.Contains(err.Error(), "StateA") {
+		t.Errorf("expected the error to name the trigger and state, got %q", err)
+	}
+
+	if err := sm.Fire(TriggerY, nil); err != nil {
+		t.Errorf("expected ignored triggers to succeed, got %v", err)
+	}
+	if err := sm.Clone(StateA).Fire(TriggerX, nil); err == nil || errors.Is(err, stateless.ErrTriggerUnhandled) {
+		t.Errorf("expected a clone without OnUnhandledTrigger to report an invalid transition, got %v", err)
+	}
+
+	sm.SetUnhandledReturnsError(false)
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Errorf("expected nil once disabled, got %v", err)
+	}
+}
+
 // External storage tests
 
 func TestExternalStorage(t *testing.T) {
@@ -467,6 +659,35 @@ func TestCanFire_WithGuard(t *testing.T) {
 	}
 }
 
+func TestCanFireAny(t *testing.T) {
+	open := func(_ context.Context, args any) error {
+		if args != "open" {
+			return stateless.Reject("closed")
+		}
+		return nil
+	}
+	sm := stateless.NewStateMachine[State, Trigger](StateB)
+	sm.Configure(StateA).PermitIf(TriggerX, StateC, open)
+	sm.Configure(StateB).
+		SubstateOf(StateA).
+		PermitIf(TriggerY, StateC, open)
+	sm.Configure(StateC)
+
+	if sm.CanFireAny(context.Background(), "closed") {
+		t.Error("expected CanFireAny to be false when all guards fail")
+	}
+	if !sm.CanFireAny(context.Background(), "open") {
+		t.Error("expected CanFireAny to be true when guards pass")
+	}
+
+	if err := sm.FireCtx(context.Background(), TriggerY, "open"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.CanFireAny(context.Background(), "open") {
+		t.Error("expected CanFireAny to be false in a state without transitions")
+	}
+}
+
 // Guard tests
 
 func TestPermitIf_GuardPasses(t *testing.T) {
@@ -522,3 +743,552 @@ func TestMultipleGuards(t *testing.T) {
 		t.Errorf("expected StateB, got %v", sm.State())
 	}
 }
+
+func TestClone(t *testing.T) {
+	entered := 0
+
+	template := stateless.NewStateMachine[State, Trigger](StateA)
+	template.Configure(StateA).Permit(TriggerX, StateB)
+	template.Configure(StateB).
+		Permit(TriggerY, StateA).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			entered++
+			return nil
+		})
+
+	transitioned := 0
+	template.OnTransitioned(func(tr stateless.Transition[State, Trigger]) { transitioned++ })
+
+	clone := template.Clone(StateB)
+	if clone.State() != StateB {
+		t.Fatalf("expected clone to start in StateB, got %v", clone.State())
+	}
+
+	if err := clone.Fire(TriggerY, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clone.State() != StateA {
+		t.Errorf("expected clone in StateA, got %v", clone.State())
+	}
+	if template.State() != StateA {
+		t.Errorf("expected template to be unaffected, got %v", template.State())
+	}
+	if transitioned != 0 {
+		t.Errorf("expected template subscribers not to be shared, got %d calls", transitioned)
+	}
+
+	if err := clone.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entered != 1 {
+		t.Errorf("expected shared entry action to run once, got %d", entered)
+	}
+	if template.State() != StateA {
+		t.Errorf("expected template to remain in StateA, got %v", template.State())
+	}
+}
+
+func TestClone_ConcurrentInstances(t *testing.T) {
+	template := stateless.NewStateMachine[State, Trigger](StateA)
+	template.Configure(StateA).Permit(TriggerX, StateB)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clone := template.Clone(StateA)
+			if err := clone.Fire(TriggerX, nil); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestReactivate(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+
+	actualOrdering := []string{}
+
+	sm.Configure(StateA).
+		SubstateOf(StateC).
+		OnActivate(func(ctx context.Context) error { actualOrdering = append(actualOrdering, "ActivatedA"); return nil }).
+		OnDeactivate(func(ctx context.Context) error { actualOrdering = append(actualOrdering, "DeactivatedA"); return nil })
+
+	if err := sm.Activate(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Added after activation, so it only runs on reactivation
+	sm.Configure(StateC).
+		OnActivate(func(ctx context.Context) error { actualOrdering = append(actualOrdering, "ActivatedC"); return nil })
+
+	if err := sm.Reactivate(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedOrdering := []string{"ActivatedA", "DeactivatedA", "ActivatedC", "ActivatedA"}
+	if len(expectedOrdering) != len(actualOrdering) {
+		t.Fatalf("expected %d events, got %d: %v", len(expectedOrdering), len(actualOrdering), actualOrdering)
+	}
+	for i := range expectedOrdering {
+		if expectedOrdering[i] != actualOrdering[i] {
+			t.Errorf("expected %s at index %d, got %s", expectedOrdering[i], i, actualOrdering[i])
+		}
+	}
+}
+
+func TestReactivate_WhenInactiveOnlyActivates(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+
+	actualOrdering := []string{}
+
+	sm.Configure(StateA).
+		OnActivate(func(ctx context.Context) error { actualOrdering = append(actualOrdering, "ActivatedA"); return nil }).
+		OnDeactivate(func(ctx context.Context) error { actualOrdering = append(actualOrdering, "DeactivatedA"); return nil })
+
+	if err := sm.Reactivate(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(actualOrdering) != 1 || actualOrdering[0] != "ActivatedA" {
+		t.Errorf("expected only activation, got %v", actualOrdering)
+	}
+}
+
+func TestStateMachineInfo_MarshalJSON(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		PermitIf(TriggerX, StateB, func(_ context.Context, _ any) error { return nil }, "ready").
+		InternalTransition(TriggerZ, func(_ context.Context, _ stateless.Transition[State, Trigger]) error { return nil })
+	sm.Configure(StateB).
+		PermitDynamic(TriggerY, func(_ context.Context, _ any) (State, error) { return StateA, nil },
+			stateless.DynamicStateInfo{DestinationState: "StateA", Criterion: "always"})
+	sm.Configure(StateC).
+		SubstateOf(StateB).
+		Ignore(TriggerX)
+
+	data, err := json.Marshal(sm.GetInfo())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"stateType":"stateless_test.State","triggerType":"stateless_test.Trigger","initialState":"StateA",` +
+		`"states":[` +
+		`{"name":"StateA","substates":[],"entryActions":[],"exitActions":[],"transitions":[` +
+		`{"trigger":"TriggerX","destination":"StateB","guards":["ready"],"isInternal":false},` +
+		`{"trigger":"TriggerZ","destination":"StateA","guards":[],"isInternal":true}],` +
+		`"dynamicTransitions":[],"ignoredTriggers":[]},` +
+		`{"name":"StateB","substates":["StateC"],"entryActions":[],"exitActions":[],"transitions":[],` +
+		`"dynamicTransitions":[{"trigger":"TriggerY","selector":"Function","guards":[],` +
+		`"possibleDestinations":[{"destination":"StateA","criterion":"always"}]}],"ignoredTriggers":[]},` +
+		`{"name":"StateC","superstate":"StateB","substates":[],"entryActions":[],"exitActions":[],"transitions":[],` +
+		`"dynamicTransitions":[],"ignoredTriggers":[{"trigger":"TriggerX","guards":[]}]}]}`
+	if string(data) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+	}
+}
+
+func TestOnUnhandledTriggerHandler(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+
+	var record []string
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB).
+		OnExit(func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			record = append(record, "ExitA")
+			return nil
+		})
+	sm.Configure(StateD).
+		OnEntry(func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			record = append(record, "EnterD")
+			return nil
+		})
+
+	legacyCalled := false
+	sm.OnUnhandledTrigger(func(_ State, _ Trigger, _ []error) { legacyCalled = true })
+
+	failure := errors.New("routing failed")
+	sm.OnUnhandledTriggerHandler(func(_ context.Context, state State, trigger Trigger, args any) (State, bool, error) {
+		switch trigger {
+		case TriggerY:
+			return StateD, true, nil
+		case TriggerZ:
+			if args != nil {
+				return state, false, failure
+			}
+		}
+		return state, false, nil
+	})
+
+	// Ignored
+	if err := sm.Fire(TriggerZ, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateA {
+		t.Fatalf("expected StateA, got %v", sm.State())
+	}
+
+	// Error propagates
+	if err := sm.Fire(TriggerZ, "fail"); !errors.Is(err, failure) {
+		t.Fatalf("expected routing error, got %v", err)
+	}
+
+	// Routed to a fallback state
+	if err := sm.Fire(TriggerY, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateD {
+		t.Errorf("expected StateD, got %v", sm.State())
+	}
+	if len(record) != 2 || record[0] != "ExitA" || record[1] != "EnterD" {
+		t.Errorf("expected [ExitA EnterD], got %v", record)
+	}
+	if legacyCalled {
+		t.Error("expected OnUnhandledTrigger not to be called while a handler is registered")
+	}
+}
+
+func TestDisableTrigger(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB).
+		Permit(TriggerY, StateC)
+	sm.Configure(StateB)
+
+	var unhandled []Trigger
+	sm.OnUnhandledTrigger(func(_ State, trigger Trigger, _ []error) {
+		unhandled = append(unhandled, trigger)
+	})
+
+	sm.DisableTrigger(TriggerX)
+	if !sm.IsTriggerDisabled(TriggerX) {
+		t.Error("expected TriggerX to be disabled")
+	}
+	if sm.CanFire(context.Background(), TriggerX, nil) {
+		t.Error("expected CanFire to be false for a disabled trigger")
+	}
+	if permitted := sm.GetPermittedTriggers(context.Background(), nil); !slices.Equal(permitted, []Trigger{TriggerY}) {
+		t.Errorf("expected only TriggerY to be permitted, got %v", permitted)
+	}
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateA || !slices.Equal(unhandled, []Trigger{TriggerX}) {
+		t.Errorf("expected disabled trigger to be unhandled in StateA, got %v and %v", sm.State(), unhandled)
+	}
+
+	sm.EnableTrigger(TriggerX)
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateB {
+		t.Errorf("expected StateB after enabling the trigger, got %v", sm.State())
+	}
+}
+
+func TestConfigure_PanicsWhileFiring(t *testing.T) {
+	for _, mode := range []stateless.FiringMode{stateless.FiringImmediate, stateless.FiringQueued} {
+		sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, mode)
+		sm.Configure(StateA).Permit(TriggerX, StateB)
+		sm.Configure(StateB).OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			sm.Configure(StateD)
+			return nil
+		})
+		assertPanics(t, "Configure from an entry action", func() { _ = sm.Fire(TriggerX, nil) })
+
+		sm = stateless.NewStateMachineWithMode[State, Trigger](StateA, mode)
+		nodeC := sm.Configure(StateC)
+		sm.Configure(StateA).Permit(TriggerY, StateC)
+		nodeC.OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			nodeC.Permit(TriggerZ, StateA)
+			return nil
+		})
+		assertPanics(t, "StateNode mutation from an entry action", func() { _ = sm.Fire(TriggerY, nil) })
+
+		// Configuring is allowed again once the trigger has been processed
+		sm.Configure(StateD)
+	}
+}
+
+func TestGetDetailedPermittedTriggers(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateB)
+	sm.Configure(StateA).Permit(TriggerZ, StateD)
+	sm.Configure(StateB).
+		SubstateOf(StateA).
+		PermitIf(TriggerX, StateC, func(ctx context.Context, args any) error { return nil }, "is ready").
+		PermitIf(TriggerX, StateD, func(ctx context.Context, args any) error {
+			return stateless.Reject("never")
+		}, "never").
+		PermitDynamic(TriggerY, func(ctx context.Context, args any) (State, error) { return StateC, nil })
+	sm.Configure(StateC)
+	sm.Configure(StateD)
+
+	details := sm.GetDetailedPermittedTriggers(context.Background(), nil)
+
+	if len(details) != 3 {
+		t.Fatalf("expected 3 permitted triggers, got %+v", details)
+	}
+	x, y, z := details[0], details[1], details[2]
+	if x.Trigger != TriggerX || x.DestinationState != StateC || !x.DestinationKnown ||
+		!slices.Equal(x.SatisfiedGuards, []string{"is ready"}) {
+		t.Errorf("unexpected details for TriggerX: %+v", x)
+	}
+	if y.Trigger != TriggerY || y.DestinationKnown || len(y.SatisfiedGuards) != 0 {
+		t.Errorf("unexpected details for dynamic TriggerY: %+v", y)
+	}
+	if z.Trigger != TriggerZ || z.DestinationState != StateD || !z.DestinationKnown {
+		t.Errorf("unexpected details for inherited TriggerZ: %+v", z)
+	}
+}
+
+func TestNewStateMachineResumed(t *testing.T) {
+	sm := stateless.NewStateMachineResumed[State, Trigger](StateA, StateB)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).Permit(TriggerY, StateC)
+	sm.Configure(StateC)
+
+	if sm.State() != StateB {
+		t.Errorf("expected to resume in StateB, got %v", sm.State())
+	}
+	if initial := sm.GetInfo().InitialState; initial == nil || initial.UnderlyingState != StateA {
+		t.Errorf("expected StateA as initial state, got %v", initial)
+	}
+	if err := sm.Fire(TriggerY, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateC {
+		t.Errorf("expected StateC, got %v", sm.State())
+	}
+
+	unconfigured := stateless.NewStateMachineResumed[State, Trigger](StateA, StateD)
+	unconfigured.Configure(StateA).Permit(TriggerX, StateB)
+	var notConfigured *stateless.UnconfiguredStateError
+	if err := unconfigured.Fire(TriggerX, nil); !errors.As(err, &notConfigured) {
+		t.Errorf("expected UnconfiguredStateError, got %v", err)
+	}
+}
+
+func TestPathTo(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB).
+		PermitDynamic(TriggerZ, func(_ context.Context, _ any) (State, error) {
+			return StateD, nil
+		}, stateless.DynamicStateInfo{DestinationState: "StateD"})
+	sm.Configure(StateB).
+		PermitIf(TriggerY, StateC, func(_ context.Context, _ any) error {
+			return stateless.Reject("never at runtime")
+		})
+	sm.Configure(StateC).Permit(TriggerX, StateA)
+	sm.Configure(StateD).SubstateOf(StateC)
+
+	tests := []struct {
+		from, to State
+		want     []Trigger
+	}{
+		{StateA, StateC, []Trigger{TriggerX, TriggerY}},
+		{StateA, StateD, []Trigger{TriggerZ}},
+		{StateD, StateB, []Trigger{TriggerX, TriggerX}},
+		{StateB, StateD, []Trigger{TriggerY, TriggerX, TriggerZ}},
+		{StateC, StateC, []Trigger{}},
+	}
+	for _, tt := range tests {
+		got, ok := sm.PathTo(tt.from, tt.to)
+		if !ok || !slices.Equal(got, tt.want) {
+			t.Errorf("PathTo(%v, %v) = %v, %v; want %v", tt.from, tt.to, got, ok, tt.want)
+		}
+	}
+
+	oneWay := stateless.NewStateMachine[State, Trigger](StateA)
+	oneWay.Configure(StateA).Permit(TriggerX, StateB)
+	if path, ok := oneWay.PathTo(StateB, StateA); ok {
+		t.Errorf("expected StateA to be unreachable from StateB, got %v", path)
+	}
+}
+
+func TestMarkFinal(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).MarkFinal().Permit(TriggerY, StateA)
+
+	for _, state := range sm.GetInfo().States {
+		if state.IsFinal != (state.UnderlyingState == StateB) {
+			t.Errorf("expected only StateB to be final, got IsFinal=%v for %v", state.IsFinal, state.UnderlyingState)
+		}
+	}
+
+	data, err := json.Marshal(sm.GetInfo())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Count(string(data), `"isFinal":true`) != 1 {
+		t.Errorf("expected one final state in JSON, got %s", data)
+	}
+
+	// A final state keeps its transitions
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := sm.Fire(TriggerY, nil); err != nil || sm.State() != StateA {
+		t.Errorf("expected to leave the final state, got %v, %v", sm.State(), err)
+	}
+}
+
+func TestRepresentation(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+
+	rep, ok := sm.Representation(StateA)
+	if !ok || rep.UnderlyingState() != StateA {
+		t.Fatalf("expected the representation of StateA, got %v, %v", rep, ok)
+	}
+	if behaviours := rep.TriggerBehaviours()[TriggerX]; len(behaviours) != 1 {
+		t.Errorf("expected one behaviour for TriggerX, got %v", behaviours)
+	}
+
+	if rep, ok := sm.Representation(StateC); ok || rep != nil {
+		t.Errorf("expected no representation for an unconfigured state, got %v", rep)
+	}
+	if states := sm.States(); slices.Contains(states, StateC) {
+		t.Errorf("expected the lookup not to configure StateC, got %v", states)
+	}
+}
+
+func TestEnumeratePaths(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB).
+		PermitDynamic(TriggerY, func(_ context.Context, _ any) (State, error) {
+			return StateC, nil
+		}, stateless.DynamicStateInfo{DestinationState: "StateC"})
+	sm.Configure(StateB).Permit(TriggerX, StateA).Permit(TriggerY, StateC)
+	sm.Configure(StateC).InitialTransition(StateD)
+	sm.Configure(StateD).SubstateOf(StateC)
+
+	type edge = stateless.TransitionEdge[State, Trigger]
+	ab := edge{Source: StateA, Trigger: TriggerX, Destination: StateB}
+	ad := edge{Source: StateA, Trigger: TriggerY, Destination: StateD}
+	ba := edge{Source: StateB, Trigger: TriggerX, Destination: StateA}
+	bd := edge{Source: StateB, Trigger: TriggerY, Destination: StateD}
+
+	tests := []struct {
+		maxDepth int
+		want     [][]edge
+	}{
+		{0, nil},
+		{1, [][]edge{{ab}, {ad}}},
+		{3, [][]edge{{ab, ba}, {ab, bd}, {ad}}},
+	}
+	for _, tt := range tests {
+		got := sm.EnumeratePaths(tt.maxDepth)
+		if !slices.EqualFunc(got, tt.want, slices.Equal) {
+			t.Errorf("EnumeratePaths(%d) = %v; want %v", tt.maxDepth, got, tt.want)
+		}
+	}
+}
+
+func TestIsolatedStates(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB)
+	sm.Configure(StateC)
+	sm.Configure(StateD)
+
+	if got, want := sm.IsolatedStates(), []State{StateC, StateD}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// Possible destinations of dynamic transitions and hierarchy count as references
+	sm.Configure(StateB).PermitDynamic(TriggerY, func(_ context.Context, _ any) (State, error) {
+		return StateC, nil
+	}, stateless.DynamicStateInfo{DestinationState: "StateC"})
+	sm.Configure(StateD).SubstateOf(StateB)
+	if got := sm.IsolatedStates(); len(got) != 0 {
+		t.Errorf("expected no isolated states, got %v", got)
+	}
+
+	// The initial state is never isolated
+	if got := stateless.NewStateMachine[State, Trigger](StateA).IsolatedStates(); len(got) != 0 {
+		t.Errorf("expected an unconfigured initial state not to be isolated, got %v", got)
+	}
+}
+
+func TestCheckDeterminism(t *testing.T) {
+	atLeast := func(n int) stateless.GuardFunc {
+		return func(_ context.Context, args any) error {
+			if args.(int) < n {
+				return stateless.Reject("too small")
+			}
+			return nil
+		}
+	}
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		PermitIf(TriggerX, StateB, atLeast(0), "non-negative").
+		PermitIf(TriggerX, StateC, atLeast(10), "at least 10").
+		PermitIf(TriggerY, StateB, atLeast(5), "at least 5")
+	sm.Configure(StateB).
+		PermitIf(TriggerY, StateC, atLeast(100), "at least 100").
+		Permit(TriggerY, StateD)
+
+	issues := sm.CheckDeterminism(context.Background(), []any{1, 20, 200})
+
+	want := []stateless.DeterminismIssue[State, Trigger]{
+		{State: StateA, Trigger: TriggerX, Args: 20, Guards: []string{"non-negative", "at least 10"}},
+		{State: StateA, Trigger: TriggerX, Args: 200, Guards: []string{"non-negative", "at least 10"}},
+		{State: StateB, Trigger: TriggerY, Args: 200, Guards: []string{"at least 100", "unguarded"}},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d issues, got %+v", len(want), issues)
+	}
+	for i := range want {
+		got := issues[i]
+		if got.State != want[i].State || got.Trigger != want[i].Trigger || got.Args != want[i].Args ||
+			!slices.Equal(got.Guards, want[i].Guards) {
+			t.Errorf("issue %d: expected %+v, got %+v", i, want[i], got)
+		}
+	}
+}
+
+type doorHandler struct{}
+
+func (*doorHandler) OnOpen(context.Context, stateless.Transition[State, Trigger]) error { return nil }
+
+func (doorHandler) IsClosed(context.Context, any) error { return nil }
+
+func checkLocked(context.Context, any) error { return nil }
+
+func TestCreateInvocationInfo_Names(t *testing.T) {
+	handler := &doorHandler{}
+	tests := []struct {
+		name        string
+		fn          any
+		description string
+	}{
+		{"pointer method value", handler.OnOpen, "OnOpen"},
+		{"value method value", doorHandler{}.IsClosed, "IsClosed"},
+		{"method expression", (*doorHandler).OnOpen, "OnOpen"},
+		{"named function", checkLocked, "checkLocked"},
+		{"closure", func(context.Context, any) error { return nil }, stateless.DefaultFunctionDescription},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := stateless.CreateInvocationInfo(tt.fn, "")
+			if got := info.Description(); got != tt.description {
+				t.Errorf("expected %q, got %q (method name %q)", tt.description, got, info.MethodName)
+			}
+		})
+	}
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).PermitIf(TriggerX, StateB, handler.IsClosed)
+	sm.Configure(StateB).OnEntry(handler.OnOpen)
+	for _, state := range sm.GetInfo().States {
+		if state.UnderlyingState == StateB && state.EntryActions[0].Description() != "OnOpen" {
+			t.Errorf("expected the entry action to be described as OnOpen, got %q", state.EntryActions[0].Description())
+		}
+	}
+}
@@ -314,3 +314,113 @@ func TestInitialTransition_TransitionEventsOrdering(t *testing.T) {
 		}
 	}
 }
+
+func TestSetEmitInitialTransitionEvents(t *testing.T) {
+	expectedOrdering := []string{
+		"OnTransitionedStateAStateB",
+		"OnTransitionedStateBStateC",
+		"OnEntryC",
+		"OnTransitionCompletedStateBStateC initial",
+		"OnTransitionCompletedStateAStateC",
+	}
+	actualOrdering := []string{}
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.SetEmitInitialTransitionEvents(true)
+
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).InitialTransition(StateC)
+	sm.Configure(StateC).
+		SubstateOf(StateB).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			actualOrdering = append(actualOrdering, "OnEntryC")
+			return nil
+		})
+
+	sm.OnTransitioned(func(t stateless.Transition[State, Trigger]) {
+		actualOrdering = append(actualOrdering, "OnTransitioned"+t.Source.String()+t.Destination.String())
+	})
+	sm.OnTransitionCompleted(func(t stateless.Transition[State, Trigger]) {
+		event := "OnTransitionCompleted" + t.Source.String() + t.Destination.String()
+		if t.IsInitial() {
+			event += " initial"
+		}
+		actualOrdering = append(actualOrdering, event)
+	})
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(expectedOrdering) != len(actualOrdering) {
+		t.Fatalf("expected %d events, got %d: %v", len(expectedOrdering), len(actualOrdering), actualOrdering)
+	}
+	for i := range expectedOrdering {
+		if expectedOrdering[i] != actualOrdering[i] {
+			t.Errorf("expected '%s' at index %d, got '%s'", expectedOrdering[i], i, actualOrdering[i])
+		}
+	}
+}
+
+func TestInitialTransitionIf(t *testing.T) {
+	isSmall := func(_ context.Context, args any) error {
+		if n, ok := args.(int); ok && n < 10 {
+			return nil
+		}
+		return stateless.Reject("not small")
+	}
+	isMedium := func(_ context.Context, args any) error {
+		if n, ok := args.(int); ok && n < 100 {
+			return nil
+		}
+		return stateless.Reject("not medium")
+	}
+
+	tests := []struct {
+		args     int
+		expected State
+	}{
+		{5, StateC},
+		{50, StateD},
+		{500, StateB},
+	}
+	for _, tt := range tests {
+		sm := stateless.NewStateMachine[State, Trigger](StateA)
+		sm.Configure(StateA).
+			Permit(TriggerX, StateB)
+		sm.Configure(StateB).
+			InitialTransitionIf(StateC, isSmall).
+			InitialTransitionIf(StateD, isMedium)
+		sm.Configure(StateC).
+			SubstateOf(StateB)
+		sm.Configure(StateD).
+			SubstateOf(StateB)
+
+		if err := sm.Fire(TriggerX, tt.args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if sm.State() != tt.expected {
+			t.Errorf("args %d: expected %v, got %v", tt.args, tt.expected, sm.State())
+		}
+	}
+}
+
+func TestInitialTransitionIf_FallsBackToUnguarded(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		InitialTransitionIf(StateC, func(_ context.Context, _ any) error { return stateless.Reject("never") }).
+		InitialTransition(StateD)
+	sm.Configure(StateC).
+		SubstateOf(StateB)
+	sm.Configure(StateD).
+		SubstateOf(StateB)
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateD {
+		t.Errorf("expected StateD, got %v", sm.State())
+	}
+}
@@ -2,6 +2,8 @@ package stateless_test
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"testing"
 
 	"github.com/atlekbai/stateless"
@@ -308,3 +310,68 @@ func TestInternalTransitionIf_ShouldExecuteOnlyFirstMatchingAction(t *testing.T)
 		t.Error("first action should have been executed")
 	}
 }
+
+func TestInternalOrTransition(t *testing.T) {
+	var record []string
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		OnExit(func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			record = append(record, "ExitA")
+			return nil
+		}).
+		InternalOrTransition(TriggerX,
+			func(_ context.Context, args any) (State, bool, error) {
+				if n, ok := args.(int); ok && n > 10 {
+					return StateB, true, nil
+				}
+				return StateA, false, nil
+			},
+			func(_ context.Context, tr stateless.Transition[State, Trigger]) error {
+				record = append(record, "Internal")
+				return nil
+			})
+	sm.Configure(StateB).
+		OnEntry(func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			record = append(record, "EntryB")
+			return nil
+		})
+
+	transition, err := sm.FireResult(context.Background(), TriggerX, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateA || transition.Kind != stateless.TransitionInternal {
+		t.Errorf("expected internal transition in StateA, got %v in %v", transition.Kind, sm.State())
+	}
+
+	transition, err = sm.FireResult(context.Background(), TriggerX, 50)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateB || transition.Kind != stateless.TransitionExternal {
+		t.Errorf("expected external transition to StateB, got %v in %v", transition.Kind, sm.State())
+	}
+
+	expected := []string{"Internal", "ExitA", "EntryB"}
+	if !slices.Equal(record, expected) {
+		t.Errorf("expected %v, got %v", expected, record)
+	}
+}
+
+func TestInternalOrTransition_SelectorError(t *testing.T) {
+	selectorErr := errors.New("selector failed")
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		InternalOrTransition(TriggerX,
+			func(_ context.Context, _ any) (State, bool, error) {
+				return StateA, false, selectorErr
+			},
+			func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+				t.Error("internal action should not run when the selector fails")
+				return nil
+			})
+
+	if err := sm.Fire(TriggerX, nil); !errors.Is(err, selectorErr) {
+		t.Fatalf("expected selector error, got %v", err)
+	}
+}
@@ -2,7 +2,11 @@ package stateless_test
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/atlekbai/stateless"
 )
@@ -29,6 +33,42 @@ func TestOnTransitioned(t *testing.T) {
 	}
 }
 
+func TestOnTransitionedWith(t *testing.T) {
+	type ctxKey struct{}
+
+	var transitions []stateless.Transition[State, Trigger]
+	var values []any
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).Permit(TriggerY, StateC)
+	sm.Configure(StateC).Permit(TriggerY, StateA)
+
+	sm.OnTransitionedWith(TriggerY, func(ctx context.Context, transition stateless.Transition[State, Trigger]) {
+		transitions = append(transitions, transition)
+		values = append(values, ctx.Value(ctxKey{}))
+	})
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	for _, tr := range []Trigger{TriggerX, TriggerY, TriggerY} {
+		if err := sm.FireCtx(ctx, tr, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(transitions) != 2 {
+		t.Fatalf("expected 2 transitions for TriggerY, got %d", len(transitions))
+	}
+	if transitions[0].Source != StateB || transitions[1].Source != StateC {
+		t.Errorf("expected transitions from StateB and StateC, got %v and %v",
+			transitions[0].Source, transitions[1].Source)
+	}
+	for _, v := range values {
+		if v != "value" {
+			t.Errorf("expected handler to receive the fire context, got value %v", v)
+		}
+	}
+}
+
 func TestOnTransitionCompleted(t *testing.T) {
 	completedCount := 0
 	sm := stateless.NewStateMachine[State, Trigger](StateA)
@@ -163,3 +203,549 @@ func TestWhenTransitioning(t *testing.T) {
 		}
 	}
 }
+
+func TestOnTransitioning_CalledBeforeExit(t *testing.T) {
+	record := []string{}
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB).
+		OnExit(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			record = append(record, "ExitA")
+			return nil
+		})
+
+	sm.OnTransitioning(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+		if tr.Source != StateA || tr.Destination != StateB || tr.Trigger != TriggerX {
+			t.Errorf("unexpected transition: %+v", tr)
+		}
+		record = append(record, "Transitioning")
+		return nil
+	})
+	sm.OnTransitioned(func(tr stateless.Transition[State, Trigger]) {
+		record = append(record, "Transitioned")
+	})
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"Transitioning", "ExitA", "Transitioned"}
+	if len(record) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %v", len(expected), len(record), record)
+	}
+	for i := range expected {
+		if record[i] != expected[i] {
+			t.Errorf("expected %s at index %d, got %s", expected[i], i, record[i])
+		}
+	}
+}
+
+func TestOnTransitioning_VetoAbortsTransition(t *testing.T) {
+	vetoErr := errors.New("not authorized")
+	actionsRun := false
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB).
+		OnExit(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			actionsRun = true
+			return nil
+		})
+	sm.Configure(StateB).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			actionsRun = true
+			return nil
+		})
+
+	sm.OnTransitioning(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+		return vetoErr
+	})
+
+	err := sm.Fire(TriggerX, nil)
+	if !errors.Is(err, vetoErr) {
+		t.Fatalf("expected veto error, got %v", err)
+	}
+	if sm.State() != StateA {
+		t.Errorf("expected state to remain StateA, got %v", sm.State())
+	}
+	if actionsRun {
+		t.Error("expected no exit or entry actions to run after veto")
+	}
+}
+
+func TestSetEmitCompletedForNonTransitions(t *testing.T) {
+	for _, emit := range []bool{false, true} {
+		sm := stateless.NewStateMachine[State, Trigger](StateA)
+		sm.Configure(StateA).
+			InternalTransition(TriggerX, func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+				return nil
+			}).
+			Ignore(TriggerY)
+		sm.SetEmitCompletedForNonTransitions(emit)
+
+		var completed []stateless.Transition[State, Trigger]
+		transitioned := 0
+		sm.OnTransitionCompleted(func(tr stateless.Transition[State, Trigger]) {
+			completed = append(completed, tr)
+		})
+		sm.OnTransitioned(func(tr stateless.Transition[State, Trigger]) { transitioned++ })
+
+		if err := sm.Fire(TriggerX, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := sm.Fire(TriggerY, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if transitioned != 0 {
+			t.Errorf("emit=%v: expected OnTransitioned not to be called, got %d", emit, transitioned)
+		}
+		if !emit {
+			if len(completed) != 0 {
+				t.Errorf("expected no completed events by default, got %d", len(completed))
+			}
+			continue
+		}
+
+		if len(completed) != 2 {
+			t.Fatalf("expected 2 completed events, got %d", len(completed))
+		}
+		expected := []stateless.TransitionKind{stateless.TransitionInternal, stateless.TransitionIgnored}
+		for i, tr := range completed {
+			if tr.Kind != expected[i] {
+				t.Errorf("expected kind %v at index %d, got %v", expected[i], i, tr.Kind)
+			}
+			if tr.IsReentry() || tr.Source != StateA || tr.Destination != StateA {
+				t.Errorf("unexpected transition at index %d: %+v", i, tr)
+			}
+		}
+	}
+}
+
+func TestGoTo(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+
+	var record []string
+	action := func(name string) stateless.TransitionAction[State, Trigger] {
+		return func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			record = append(record, name)
+			return nil
+		}
+	}
+
+	sm.Configure(StateA).
+		OnEntry(action("EnterA")).
+		OnExit(action("ExitA"))
+	sm.Configure(StateB).
+		InitialTransition(StateC).
+		OnEntry(action("EnterB")).
+		OnExit(action("ExitB"))
+	sm.Configure(StateC).
+		SubstateOf(StateB).
+		OnEntry(action("EnterC"))
+
+	var transitioned, completed []stateless.Transition[State, Trigger]
+	sm.OnTransitioned(func(tr stateless.Transition[State, Trigger]) { transitioned = append(transitioned, tr) })
+	sm.OnTransitionCompleted(func(tr stateless.Transition[State, Trigger]) { completed = append(completed, tr) })
+
+	if err := sm.GoTo(context.Background(), StateB); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sm.State() != StateC {
+		t.Errorf("expected StateC after initial transition, got %v", sm.State())
+	}
+	expected := []string{"ExitA", "EnterB", "EnterC"}
+	if len(record) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, record)
+	}
+	for i := range expected {
+		if record[i] != expected[i] {
+			t.Errorf("expected %s at index %d, got %s", expected[i], i, record[i])
+		}
+	}
+
+	if len(transitioned) == 0 || transitioned[0].Kind != stateless.TransitionForced {
+		t.Errorf("expected forced OnTransitioned, got %v", transitioned)
+	}
+	if len(completed) != 1 || completed[0].Kind != stateless.TransitionForced || completed[0].Destination != StateC {
+		t.Errorf("expected one forced OnTransitionCompleted to StateC, got %v", completed)
+	}
+}
+
+func TestGoTo_UnconfiguredState(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA)
+
+	var argErr *stateless.ArgumentError
+	if err := sm.GoTo(context.Background(), StateD); !errors.As(err, &argErr) {
+		t.Fatalf("expected ArgumentError, got %v", err)
+	}
+	if sm.State() != StateA {
+		t.Errorf("expected StateA, got %v", sm.State())
+	}
+}
+
+func TestGoTo_SerializedWithFires(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+
+	var mutex sync.Mutex
+	active, overlaps, entries := 0, 0, 0
+	sm.Configure(StateA).
+		PermitReentry(TriggerX).
+		OnEntry(func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			mutex.Lock()
+			active++
+			if active > 1 {
+				overlaps++
+			}
+			mutex.Unlock()
+
+			time.Sleep(time.Millisecond)
+			entries++
+
+			mutex.Lock()
+			active--
+			mutex.Unlock()
+			return nil
+		})
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			if i%2 == 0 {
+				err = sm.GoTo(context.Background(), StateA)
+			} else {
+				err = sm.Fire(TriggerX, nil)
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if overlaps != 0 {
+		t.Errorf("expected GoTo not to overlap with fires, got %d overlaps", overlaps)
+	}
+	if entries != 8 {
+		t.Errorf("expected 8 entries, got %d", entries)
+	}
+}
+
+func TestGoTo_FromAction(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		OnEntry(func(ctx context.Context, _ stateless.Transition[State, Trigger]) error {
+			return sm.GoTo(ctx, StateC)
+		})
+	sm.Configure(StateC)
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateC {
+		t.Errorf("expected GoTo to nest in the fire and end in StateC, got %v", sm.State())
+	}
+}
+
+func TestGoTo_ConfigureFromActionPanics(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA)
+	sm.Configure(StateB).
+		OnEntry(func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected Configure to panic while GoTo is processing")
+				}
+			}()
+			sm.Configure(StateC)
+			return nil
+		})
+
+	if err := sm.GoTo(context.Background(), StateB); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGoTo_Queued(t *testing.T) {
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued)
+	var record []string
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		OnEntry(func(ctx context.Context, _ stateless.Transition[State, Trigger]) error {
+			record = append(record, "EnterB")
+			var invalid *stateless.InvalidOperationError
+			if err := sm.GoTo(ctx, StateA); !errors.As(err, &invalid) {
+				t.Errorf("expected InvalidOperationError from an action, got %v", err)
+			}
+			return nil
+		})
+	sm.Configure(StateC).
+		OnEntry(func(ctx context.Context, _ stateless.Transition[State, Trigger]) error {
+			record = append(record, "EnterC")
+			if err := sm.FireCtx(ctx, TriggerY, nil); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			record = append(record, "EnteredC")
+			return nil
+		}).
+		Permit(TriggerY, StateA)
+
+	if err := sm.GoTo(context.Background(), StateC); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateA {
+		t.Errorf("expected the trigger queued by GoTo to be processed after it, got %v", sm.State())
+	}
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"EnterC", "EnteredC", "EnterB"}; !slices.Equal(record, expected) {
+		t.Errorf("expected %v, got %v", expected, record)
+	}
+}
+
+func TestGoTo_ShutdownAndReplayMode(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA)
+	sm.Configure(StateB)
+
+	sm.SetReplayMode(true)
+	var invalid *stateless.InvalidOperationError
+	if err := sm.GoTo(context.Background(), StateB); !errors.As(err, &invalid) || sm.State() != StateA {
+		t.Errorf("expected InvalidOperationError in replay mode, got %v in %v", err, sm.State())
+	}
+	sm.SetReplayMode(false)
+
+	sm.Shutdown()
+	if err := sm.GoTo(context.Background(), StateB); !errors.Is(err, stateless.ErrShuttingDown) || sm.State() != StateA {
+		t.Errorf("expected ErrShuttingDown after Shutdown, got %v in %v", err, sm.State())
+	}
+
+	queued := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued)
+	queued.Configure(StateA)
+	queued.Configure(StateB)
+	queued.Shutdown()
+	if err := queued.GoTo(context.Background(), StateB); !errors.Is(err, stateless.ErrShuttingDown) {
+		t.Errorf("expected ErrShuttingDown after Shutdown in FiringQueued mode, got %v", err)
+	}
+}
+
+func TestOnTerminalState(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB).
+		Permit(TriggerY, StateD)
+	sm.Configure(StateB).
+		PermitDynamic(TriggerX, func(_ context.Context, _ any) (State, error) { return StateC, nil })
+	sm.Configure(StateC).
+		Ignore(TriggerX)
+	sm.Configure(StateD).
+		InternalTransition(TriggerX, func(_ context.Context, _ stateless.Transition[State, Trigger]) error { return nil })
+
+	var terminal []stateless.Transition[State, Trigger]
+	sm.OnTerminalState(func(_ context.Context, tr stateless.Transition[State, Trigger]) {
+		terminal = append(terminal, tr)
+	})
+
+	// StateB has a dynamic transition, so it is not terminal
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(terminal) != 0 {
+		t.Fatalf("expected no terminal callback in StateB, got %v", terminal)
+	}
+
+	// StateC only ignores triggers, so it is terminal
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(terminal) != 1 || terminal[0].Source != StateB || terminal[0].Destination != StateC {
+		t.Errorf("expected one terminal callback for StateB -> StateC, got %v", terminal)
+	}
+
+	// StateD has an internal transition, so it is not terminal
+	sm2 := sm.Clone(StateA)
+	called := false
+	sm2.OnTerminalState(func(_ context.Context, _ stateless.Transition[State, Trigger]) { called = true })
+	if err := sm2.Fire(TriggerY, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if called {
+		t.Error("expected no terminal callback in StateD")
+	}
+}
+
+func TestPreviousStateAndPermitBack(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		Permit(TriggerX, StateB).
+		PermitBack(TriggerZ)
+	sm.Configure(StateB).
+		InitialTransition(StateC).
+		PermitReentry(TriggerY)
+	sm.Configure(StateC).
+		SubstateOf(StateB)
+	sm.Configure(StateD).
+		PermitBack(TriggerZ)
+	sm.Configure(StateC).Permit(TriggerX, StateD)
+
+	if _, ok := sm.PreviousState(); ok {
+		t.Error("expected no previous state before the first transition")
+	}
+	var invalid *stateless.InvalidOperationError
+	if err := sm.Fire(TriggerZ, nil); !errors.As(err, &invalid) {
+		t.Errorf("expected InvalidOperationError going back without a previous state, got %v", err)
+	}
+
+	fire := func(tr Trigger) {
+		t.Helper()
+		if err := sm.Fire(tr, nil); err != nil {
+			t.Fatalf("unexpected error firing %v: %v", tr, err)
+		}
+	}
+
+	// The initial transition into StateC does not record StateB
+	fire(TriggerX)
+	if previous, ok := sm.PreviousState(); !ok || previous != StateA || sm.State() != StateC {
+		t.Errorf("expected StateC coming from StateA, got %v coming from %v", sm.State(), previous)
+	}
+
+	// Reentering the superstate leaves the previous state alone
+	fire(TriggerY)
+	if previous, _ := sm.PreviousState(); previous != StateA {
+		t.Errorf("expected the previous state to stay StateA after reentry, got %v", previous)
+	}
+
+	fire(TriggerX)
+	fire(TriggerZ)
+	if sm.State() != StateC {
+		t.Errorf("expected to go back to StateC, got %v", sm.State())
+	}
+	if previous, _ := sm.PreviousState(); previous != StateD {
+		t.Errorf("expected StateD as previous state, got %v", previous)
+	}
+}
+
+func TestHistoryBack(t *testing.T) {
+	var entered []State
+	record := func(_ context.Context, tr stateless.Transition[State, Trigger]) error {
+		entered = append(entered, tr.Destination)
+		return nil
+	}
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.EnableHistory(2)
+	sm.Configure(StateA).Permit(TriggerX, StateB).OnEntry(record)
+	sm.Configure(StateB).Permit(TriggerX, StateC).OnEntry(record)
+	sm.Configure(StateC).Permit(TriggerX, StateD).OnEntry(record)
+	sm.Configure(StateD).OnEntry(record)
+
+	for range 3 {
+		if err := sm.Fire(TriggerX, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n := sm.HistoryLength(); n != 2 {
+		t.Fatalf("expected the history to be bounded to 2 states, got %d", n)
+	}
+
+	ctx := context.Background()
+	for _, want := range []State{StateC, StateB} {
+		if err := sm.Back(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if sm.State() != want {
+			t.Errorf("expected to go back to %v, got %v", want, sm.State())
+		}
+	}
+	if n := sm.HistoryLength(); n != 0 {
+		t.Errorf("expected Back not to push onto the history, got %d states", n)
+	}
+	if !slices.Equal(entered, []State{StateB, StateC, StateD, StateC, StateB}) {
+		t.Errorf("expected entry actions to run when going back, got %v", entered)
+	}
+
+	var invalid *stateless.InvalidOperationError
+	if err := sm.Back(ctx); !errors.As(err, &invalid) {
+		t.Errorf("expected InvalidOperationError with an empty history, got %v", err)
+	}
+}
+
+func TestHistoryBack_SerializedWithFires(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.EnableHistory(100)
+
+	var mutex sync.Mutex
+	active, overlaps := 0, 0
+	enter := func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+		mutex.Lock()
+		active++
+		if active > 1 {
+			overlaps++
+		}
+		mutex.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mutex.Lock()
+		active--
+		mutex.Unlock()
+		return nil
+	}
+	sm.Configure(StateA).Permit(TriggerX, StateB).OnEntry(enter)
+	sm.Configure(StateB).Permit(TriggerX, StateA).OnEntry(enter)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i%2 == 0 {
+				var invalid *stateless.InvalidOperationError
+				if err := sm.Back(context.Background()); err != nil && !errors.As(err, &invalid) {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else if err := sm.Fire(TriggerX, nil); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if overlaps != 0 {
+		t.Errorf("expected Back not to overlap with fires, got %d overlaps", overlaps)
+	}
+}
+
+func TestHistoryBack_ShutdownAndReplayMode(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.EnableHistory(1)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB)
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+	sm.SetReplayMode(true)
+	var invalid *stateless.InvalidOperationError
+	if err := sm.Back(ctx); !errors.As(err, &invalid) || sm.State() != StateB {
+		t.Errorf("expected InvalidOperationError in replay mode, got %v in %v", err, sm.State())
+	}
+	sm.SetReplayMode(false)
+
+	sm.Shutdown()
+	if err := sm.Back(ctx); !errors.Is(err, stateless.ErrShuttingDown) || sm.State() != StateB {
+		t.Errorf("expected ErrShuttingDown after Shutdown, got %v in %v", err, sm.State())
+	}
+	if n := sm.HistoryLength(); n != 1 {
+		t.Errorf("expected rejected calls to keep the history, got %d states", n)
+	}
+}
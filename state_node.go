@@ -3,12 +3,17 @@ package stateless
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // StateNode provides a fluent interface for configuring state behaviour.
 type StateNode[TState, TTrigger comparable] struct {
 	representation *StateRepresentation[TState, TTrigger]
 	lookup         func(TState) *StateRepresentation[TState, TTrigger]
+
+	// identityAsReentry reports whether Permit to the configured state itself means reentry.
+	// Nil when the node was not created by a state machine.
+	identityAsReentry func() bool
 }
 
 // NewStateNode creates a new state configuration.
@@ -29,22 +34,51 @@ func (sn *StateNode[TState, TTrigger]) State() TState {
 
 // Permit configures the state to transition to the specified destination state
 // when the specified trigger is fired.
+// If the destination is the state itself, Permit panics unless the machine was configured
+// with SetPermitIdentityAsReentry, in which case it behaves like PermitReentry.
 func (sn *StateNode[TState, TTrigger]) Permit(tr TTrigger, dst TState) *StateNode[TState, TTrigger] {
-	sn.enforceNotIdentityTransition(dst)
-	sn.representation.AddTriggerBehaviour(
-		NewTransitioningTriggerBehaviour(tr, dst, EmptyTransitionGuard),
-	)
-	return sn
+	return sn.permit(tr, dst, EmptyTransitionGuard)
 }
 
 // PermitIf configures the state to transition to the specified destination state
 // when the specified trigger is fired, if the guard condition is met.
 // The guard returns nil if the condition is met, or an error describing why it failed.
-func (sn *StateNode[TState, TTrigger]) PermitIf(tr TTrigger, dst TState, gf GuardFunc) *StateNode[TState, TTrigger] {
+// An optional description labels the guard in graphs and introspection.
+// Identity destinations are handled as in Permit.
+func (sn *StateNode[TState, TTrigger]) PermitIf(
+	tr TTrigger,
+	dst TState,
+	gf GuardFunc,
+	description ...string,
+) *StateNode[TState, TTrigger] {
+	return sn.permit(tr, dst, newDescribedTransitionGuard(gf, optionalDescription(description)))
+}
+
+// permit adds a transitioning behaviour, or a reentry behaviour for an identity destination
+// when the machine permits identity transitions as reentry.
+func (sn *StateNode[TState, TTrigger]) permit(
+	tr TTrigger,
+	dst TState,
+	guard TransitionGuard,
+) *StateNode[TState, TTrigger] {
+	if dst == sn.representation.UnderlyingState() && sn.identityAsReentry != nil && sn.identityAsReentry() {
+		sn.representation.AddTriggerBehaviour(NewReentryTriggerBehaviour(tr, dst, guard))
+		return sn
+	}
 	sn.enforceNotIdentityTransition(dst)
-	sn.representation.AddTriggerBehaviour(
-		NewTransitioningTriggerBehaviour(tr, dst, NewTransitionGuard(gf)),
-	)
+	sn.representation.AddTriggerBehaviour(NewTransitioningTriggerBehaviour(tr, dst, guard))
+	return sn
+}
+
+// PermitDefault configures a catch-all transition to the specified destination state,
+// taken when a trigger is fired for which neither this state nor any of its superstates
+// configures a behaviour (Permit, Ignore, InternalTransition, and so on).
+// Any configured behaviour therefore wins over a default, including one inherited from a superstate;
+// a trigger whose configured guards all reject is reported as invalid rather than defaulted.
+// When several states in the hierarchy have a default, the innermost one is used.
+func (sn *StateNode[TState, TTrigger]) PermitDefault(dst TState) *StateNode[TState, TTrigger] {
+	sn.enforceNotIdentityTransition(dst)
+	sn.representation.SetDefaultTransition(dst)
 	return sn
 }
 
@@ -81,21 +115,55 @@ func (sn *StateNode[TState, TTrigger]) Ignore(tr TTrigger) *StateNode[TState, TT
 
 // IgnoreIf configures the state to ignore the specified trigger if the guard condition is met.
 // The guard returns nil if the condition is met, or an error describing why it failed.
-func (sn *StateNode[TState, TTrigger]) IgnoreIf(tr TTrigger, gf GuardFunc) *StateNode[TState, TTrigger] {
+// An optional description labels the guard in graphs and introspection.
+func (sn *StateNode[TState, TTrigger]) IgnoreIf(
+	tr TTrigger,
+	gf GuardFunc,
+	description ...string,
+) *StateNode[TState, TTrigger] {
 	sn.representation.AddTriggerBehaviour(
-		NewIgnoredTriggerBehaviour[TState](tr, NewTransitionGuard(gf)),
+		NewIgnoredTriggerBehaviour[TState](tr, newDescribedTransitionGuard(gf, optionalDescription(description))),
 	)
 	return sn
 }
 
 // PermitDynamic configures the state to transition to a dynamically determined destination state
-// when the specified trigger is fired. The destination selector receives the trigger arguments.
-// If you don't need args, use func(_ any) TState { return targetState }.
+// when the specified trigger is fired. The destination selector receives the context the trigger
+// was fired with and the trigger arguments; if it returns an error, the fire is aborted with that
+// error before any exit action runs and the state does not change.
 func (sn *StateNode[TState, TTrigger]) PermitDynamic(
 	tr TTrigger,
 	ss StateSelector[TState],
 	possibleDestinations ...DynamicStateInfo,
 ) *StateNode[TState, TTrigger] {
+	sn.representation.AddTriggerBehaviour(newDynamicBehaviour(tr, ss, possibleDestinations))
+	return sn
+}
+
+// PermitDynamicReentry configures the state to transition to a dynamically determined destination state
+// when the specified trigger is fired, where the selector may return the current state or one of its
+// superstates to reenter it: the current state and its superstates up to and including the returned
+// state are exited, then the returned state is entered again and its initial transition applied, even
+// when the behaviour is inherited from a superstate. When the selector returns a superstate of the
+// current state, PermitDynamic instead only exits the states below it and enters none. Any other state
+// results in a regular transition.
+func (sn *StateNode[TState, TTrigger]) PermitDynamicReentry(
+	tr TTrigger,
+	ss StateSelector[TState],
+	possibleDestinations ...DynamicStateInfo,
+) *StateNode[TState, TTrigger] {
+	behaviour := newDynamicBehaviour(tr, ss, possibleDestinations)
+	behaviour.reentry = true
+	sn.representation.AddTriggerBehaviour(behaviour)
+	return sn
+}
+
+// newDynamicBehaviour creates an unguarded dynamic trigger behaviour described by its selector.
+func newDynamicBehaviour[TState, TTrigger comparable](
+	tr TTrigger,
+	ss StateSelector[TState],
+	possibleDestinations []DynamicStateInfo,
+) *DynamicTriggerBehaviour[TState, TTrigger] {
 	info := DynamicTransitionInfo{
 		transitionInfoBase: transitionInfoBase{
 			Trigger:         NewTriggerInfo(tr),
@@ -104,10 +172,7 @@ func (sn *StateNode[TState, TTrigger]) PermitDynamic(
 		DestinationStateSelectorDescription: CreateInvocationInfo(ss, ""),
 		PossibleDestinationStates:           possibleDestinations,
 	}
-	sn.representation.AddTriggerBehaviour(
-		NewDynamicTriggerBehaviour(tr, ss, EmptyTransitionGuard, info),
-	)
-	return sn
+	return NewDynamicTriggerBehaviour(tr, ss, EmptyTransitionGuard, info)
 }
 
 // PermitDynamicIf configures the state to transition to a dynamically determined destination state
@@ -134,12 +199,29 @@ func (sn *StateNode[TState, TTrigger]) PermitDynamicIf(
 
 // InternalTransition configures an internal transition where the state is not exited
 // and re-entered, and entry/exit actions are not executed.
+// An optional description labels the action in introspection.
 func (sn *StateNode[TState, TTrigger]) InternalTransition(
 	tr TTrigger,
 	act TransitionAction[TState, TTrigger],
+	description ...string,
+) *StateNode[TState, TTrigger] {
+	behaviour := NewInternalTriggerBehaviour(tr, EmptyTransitionGuard, act)
+	behaviour.ActionDescription = CreateInvocationInfo(act, optionalDescription(description))
+	sn.representation.AddTriggerBehaviour(behaviour)
+	return sn
+}
+
+// InternalOrTransition configures a trigger that is handled as an internal transition unless the
+// selector picks a destination. When selector returns (dst, true, nil), a regular transition to dst
+// takes place, with exit and entry actions; when it returns false, act runs as an internal
+// transition and the state does not change. A selector error is returned by Fire.
+func (sn *StateNode[TState, TTrigger]) InternalOrTransition(
+	tr TTrigger,
+	selector func(ctx context.Context, args any) (TState, bool, error),
+	act TransitionAction[TState, TTrigger],
 ) *StateNode[TState, TTrigger] {
 	sn.representation.AddTriggerBehaviour(
-		NewInternalTriggerBehaviour(tr, EmptyTransitionGuard, act),
+		NewInternalOrTransitionTriggerBehaviour(tr, selector, act, EmptyTransitionGuard),
 	)
 	return sn
 }
@@ -160,6 +242,7 @@ func (sn *StateNode[TState, TTrigger]) InternalTransitionIf(
 
 // OnEntry configures an action to be executed when entering this state.
 // The action receives the transition information including source, destination, trigger, and args.
+// Entry actions run in registration order, after any actions registered with OnEntryFirst.
 // Use type assertion to access typed arguments:
 //
 //	OnEntry(func(ctx context.Context, t Transition[State, Trigger]) error {
@@ -168,18 +251,109 @@ func (sn *StateNode[TState, TTrigger]) InternalTransitionIf(
 //	    }
 //	    return nil
 //	})
-func (sn *StateNode[TState, TTrigger]) OnEntry(act TransitionAction[TState, TTrigger]) *StateNode[TState, TTrigger] {
+//
+// An optional description labels the action in graphs and introspection.
+func (sn *StateNode[TState, TTrigger]) OnEntry(
+	act TransitionAction[TState, TTrigger],
+	description ...string,
+) *StateNode[TState, TTrigger] {
+	sn.representation.AddEntryAction(
+		NewEntryActionBehaviour(act, CreateInvocationInfo(act, optionalDescription(description))),
+	)
+	return sn
+}
+
+// OnEntryFromState configures an action to be executed when entering this state from the specified
+// source state, whatever the trigger. It is an ordinary entry action that only runs the action
+// when Transition.Source equals source.
+//
+// When this state is entered through an initial transition, the transition's Source is the
+// superstate that declared the initial transition rather than the state the machine came from,
+// so the action only runs for that superstate. For example, after A --X--> B with B's initial
+// transition to C, an OnEntryFromState(B, ...) action on C runs and OnEntryFromState(A, ...) does not.
+func (sn *StateNode[TState, TTrigger]) OnEntryFromState(
+	source TState,
+	act TransitionAction[TState, TTrigger],
+) *StateNode[TState, TTrigger] {
 	sn.representation.AddEntryAction(
+		NewEntryActionBehaviour(func(ctx context.Context, t Transition[TState, TTrigger]) error {
+			if t.Source != source {
+				return nil
+			}
+			return act(ctx, t)
+		}, CreateInvocationInfo(act, "")),
+	)
+	return sn
+}
+
+// OnEntryFirst configures an action to be executed when entering this state,
+// before every entry action registered so far. Actions registered with OnEntryFirst
+// therefore run in reverse registration order, followed by the OnEntry actions in registration order.
+func (sn *StateNode[TState, TTrigger]) OnEntryFirst(
+	act TransitionAction[TState, TTrigger],
+) *StateNode[TState, TTrigger] {
+	sn.representation.PrependEntryAction(
 		NewEntryActionBehaviour(act, CreateInvocationInfo(act, "")),
 	)
 	return sn
 }
 
+// WithTag attaches a metadata value to this state under key, such as a color or a required
+// permission, replacing any previous value. Tags do not affect behaviour; they are reported in
+// StateInfo.Tags and can be used by graph exporters.
+func (sn *StateNode[TState, TTrigger]) WithTag(key, value string) *StateNode[TState, TTrigger] {
+	sn.representation.SetTag(key, value)
+	return sn
+}
+
+// MarkFinal marks this state as a final state of the machine, one in which its work is complete.
+// Like tags, this does not affect behaviour: the state keeps any transitions configured for it.
+// It is reported in StateInfo.IsFinal, and graph exporters draw a transition from the state to a
+// UML final state. Unlike IsTerminal, which is inferred from the configured transitions, it states
+// the intent explicitly.
+func (sn *StateNode[TState, TTrigger]) MarkFinal() *StateNode[TState, TTrigger] {
+	sn.representation.SetFinal()
+	return sn
+}
+
+// OnEntryFailure routes failed entries of this state to dst. If an entry action run while entering
+// this state returns an error other than a guard rejection, the state machine moves to dst, runs its
+// entry actions and initial transitions, and Fire returns an EntryFailedError wrapping the original
+// error. Exit actions of the failed state are not run, since it was never fully entered. If entering
+// dst fails as well, the machine stays in dst and no further failure state is followed.
+func (sn *StateNode[TState, TTrigger]) OnEntryFailure(dst TState) *StateNode[TState, TTrigger] {
+	sn.enforceNotIdentityTransition(dst)
+	sn.representation.SetEntryFailureState(dst)
+	return sn
+}
+
 // OnExit configures an action to be executed when exiting this state.
 // The action receives the transition information including source, destination, trigger, and args.
-func (sn *StateNode[TState, TTrigger]) OnExit(act TransitionAction[TState, TTrigger]) *StateNode[TState, TTrigger] {
+// An optional description labels the action in graphs and introspection.
+func (sn *StateNode[TState, TTrigger]) OnExit(
+	act TransitionAction[TState, TTrigger],
+	description ...string,
+) *StateNode[TState, TTrigger] {
 	sn.representation.AddExitAction(
-		NewExitActionBehaviour(act, CreateInvocationInfo(act, "")),
+		NewExitActionBehaviour(act, CreateInvocationInfo(act, optionalDescription(description))),
+	)
+	return sn
+}
+
+// OnExitDetailed configures an action to be executed when exiting this state, which also receives
+// the state being exited. When a transition leaves several levels of a hierarchy, the exit actions
+// of each level run with the same transition, whose Source is the innermost state and Destination
+// the final target; exitingState tells the levels apart. An optional description labels the action
+// in graphs and introspection.
+func (sn *StateNode[TState, TTrigger]) OnExitDetailed(
+	act func(ctx context.Context, t Transition[TState, TTrigger], exitingState TState) error,
+	description ...string,
+) *StateNode[TState, TTrigger] {
+	exitingState := sn.representation.UnderlyingState()
+	sn.representation.AddExitAction(
+		NewExitActionBehaviour(func(ctx context.Context, t Transition[TState, TTrigger]) error {
+			return act(ctx, t, exitingState)
+		}, CreateInvocationInfo(act, optionalDescription(description))),
 	)
 	return sn
 }
@@ -202,7 +376,34 @@ func (sn *StateNode[TState, TTrigger]) OnDeactivate(act func(ctx context.Context
 	return sn
 }
 
+// PermitTick configures the state machine to fire trigger every interval while it is in this state
+// or one of its substates and active. The timer starts when the state is entered after Activate, or
+// on Activate if it is the current state, and stops when the state is exited or on Deactivate; a
+// reentry keeps it running. No timer starts in replay mode (see SetReplayMode). Time is measured
+// with the machine's Clock.
+//
+// Ticks are fired from the clock's goroutine, so the machine should use FiringQueued. A tick that
+// no behaviour handles when it is processed is ignored, without invoking OnUnhandledTrigger;
+// other errors are discarded, although OnError handlers still see failing actions.
+// Combine it with guards to poll external conditions:
+//
+//	sm.Configure(Waiting).
+//	    PermitTick(Poll, time.Second).
+//	    PermitIf(Poll, Ready, resourceReady)
+func (sn *StateNode[TState, TTrigger]) PermitTick(
+	trigger TTrigger,
+	interval time.Duration,
+) *StateNode[TState, TTrigger] {
+	if interval <= 0 {
+		panic(fmt.Sprintf("tick interval must be positive: trigger '%v', interval %v", trigger, interval))
+	}
+	sn.representation.AddTick(trigger, interval)
+	return sn
+}
+
 // SubstateOf sets the superstate of this state.
+// The superstate does not need to be configured beforehand: its representation is created
+// on first reference and can be configured later. Circular relationships panic immediately.
 func (sn *StateNode[TState, TTrigger]) SubstateOf(superstate TState) *StateNode[TState, TTrigger] {
 	superstateRep := sn.lookup(superstate)
 	if superstateRep == nil {
@@ -236,6 +437,31 @@ func (sn *StateNode[TState, TTrigger]) InitialTransition(dst TState) *StateNode[
 	return sn
 }
 
+// InitialTransitionIf adds an initial transition to the specified substate that is only taken if the
+// guard condition is met. When the state is entered, guarded initial transitions are evaluated in the
+// order they were configured and the first one whose guard is met is taken; if none is, the unguarded
+// InitialTransition is used, if any. Guards receive the args of the trigger that entered the state.
+// An optional description labels the guard in graphs and introspection.
+func (sn *StateNode[TState, TTrigger]) InitialTransitionIf(
+	dst TState,
+	gf GuardFunc,
+	description ...string,
+) *StateNode[TState, TTrigger] {
+	if sn.representation.UnderlyingState() == dst {
+		panic(fmt.Sprintf("initial transition to self is not allowed: state '%v'", dst))
+	}
+	sn.representation.AddGuardedInitialTransition(dst, newDescribedTransitionGuard(gf, optionalDescription(description)))
+	return sn
+}
+
+// optionalDescription returns the first of the optional descriptions, or an empty string.
+func optionalDescription(description []string) string {
+	if len(description) == 0 {
+		return ""
+	}
+	return description[0]
+}
+
 // enforceNotIdentityTransition ensures that a transition is not to the same state.
 func (sn *StateNode[TState, TTrigger]) enforceNotIdentityTransition(dst TState) {
 	if sn.representation.UnderlyingState() == dst {
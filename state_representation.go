@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"sync/atomic"
+	"time"
 )
 
 // StateRepresentation models the behaviour of a state.
@@ -36,6 +38,45 @@ type StateRepresentation[TState, TTrigger comparable] struct {
 
 	// initialTransitionTarget is the target state for the initial transition.
 	initialTransitionTarget TState
+
+	// guardedInitialTransitions are the initial transitions configured with InitialTransitionIf, in order.
+	guardedInitialTransitions []GuardedInitialTransition[TState]
+
+	// ticks are the periodic triggers configured with PermitTick.
+	ticks []Tick[TTrigger]
+
+	// hasDefaultTransition indicates if this state has a catch-all transition configured.
+	hasDefaultTransition bool
+
+	// defaultDestination is the destination of the catch-all transition.
+	defaultDestination TState
+
+	// tags are the metadata attached with WithTag.
+	tags map[string]string
+
+	// isFinal indicates if this state was marked final with MarkFinal.
+	isFinal bool
+
+	// hasEntryFailureState indicates if this state routes failed entry actions to another state.
+	hasEntryFailureState bool
+
+	// entryFailureState is the state entered when an entry action of this state fails.
+	entryFailureState TState
+
+	// configVersion is shared with the owning state machine and bumped on every configuration change.
+	configVersion *atomic.Uint64
+
+	// reverseExitOrder is shared with the owning state machine; when set, exit actions run last to first.
+	reverseExitOrder *atomic.Bool
+
+	// processing is shared with the owning state machine and set while it processes a trigger.
+	processing *atomic.Int32
+
+	// hasTicks is shared with the owning state machine and set once any state configures a tick.
+	hasTicks *atomic.Bool
+
+	// sealed is shared with the owning state machine and set by Builder.Build.
+	sealed *bool
 }
 
 // NewStateRepresentation creates a new state representation.
@@ -59,6 +100,7 @@ func (sr *StateRepresentation[TState, TTrigger]) Superstate() *StateRepresentati
 // SetSuperstate sets the parent state.
 func (sr *StateRepresentation[TState, TTrigger]) SetSuperstate(superstate *StateRepresentation[TState, TTrigger]) {
 	sr.superstate = superstate
+	sr.markChanged()
 }
 
 // GetSubstates returns the substates of this state.
@@ -69,6 +111,7 @@ func (sr *StateRepresentation[TState, TTrigger]) GetSubstates() []*StateRepresen
 // AddSubstate adds a substate to this state.
 func (sr *StateRepresentation[TState, TTrigger]) AddSubstate(substate *StateRepresentation[TState, TTrigger]) {
 	sr.substates = append(sr.substates, substate)
+	sr.markChanged()
 }
 
 // IsSubstateOf returns true if this state is a substate of the given state.
@@ -121,6 +164,148 @@ func (sr *StateRepresentation[TState, TTrigger]) InitialTransitionTarget() TStat
 func (sr *StateRepresentation[TState, TTrigger]) SetInitialTransition(target TState) {
 	sr.hasInitialTransition = true
 	sr.initialTransitionTarget = target
+	sr.markChanged()
+}
+
+// GuardedInitialTransition is an initial transition that is only taken when its guard is met.
+type GuardedInitialTransition[TState comparable] struct {
+	// Target is the substate entered.
+	Target TState
+
+	// Guard decides whether the transition is taken.
+	Guard TransitionGuard
+}
+
+// GuardedInitialTransitions returns the guarded initial transitions in the order they were configured.
+func (sr *StateRepresentation[TState, TTrigger]) GuardedInitialTransitions() []GuardedInitialTransition[TState] {
+	return sr.guardedInitialTransitions
+}
+
+// AddGuardedInitialTransition adds an initial transition that is taken when its guard is met.
+func (sr *StateRepresentation[TState, TTrigger]) AddGuardedInitialTransition(target TState, guard TransitionGuard) {
+	sr.guardedInitialTransitions = append(sr.guardedInitialTransitions, GuardedInitialTransition[TState]{
+		Target: target,
+		Guard:  guard,
+	})
+	sr.markChanged()
+}
+
+// Tick is a trigger fired periodically while a state is active, configured with PermitTick.
+type Tick[TTrigger comparable] struct {
+	// Trigger is the trigger to fire.
+	Trigger TTrigger
+
+	// Interval is the time between two firings.
+	Interval time.Duration
+}
+
+// Ticks returns the periodic triggers of this state in the order they were configured.
+func (sr *StateRepresentation[TState, TTrigger]) Ticks() []Tick[TTrigger] {
+	return sr.ticks
+}
+
+// AddTick adds a trigger to be fired every interval while this state is active.
+func (sr *StateRepresentation[TState, TTrigger]) AddTick(trigger TTrigger, interval time.Duration) {
+	sr.ticks = append(sr.ticks, Tick[TTrigger]{Trigger: trigger, Interval: interval})
+	if sr.hasTicks != nil {
+		sr.hasTicks.Store(true)
+	}
+	sr.markChanged()
+}
+
+// ResolveInitialTransition returns the substate to enter when this state is entered: the target of
+// the first guarded initial transition whose guard is met, otherwise the unguarded initial transition.
+// Returns false if no initial transition applies. Guard rejections skip to the next candidate;
+// unexpected guard errors are returned.
+func (sr *StateRepresentation[TState, TTrigger]) ResolveInitialTransition(
+	ctx context.Context,
+	args any,
+) (TState, bool, error) {
+	for _, initial := range sr.guardedInitialTransitions {
+		err := initial.Guard.GuardConditionsMet(ctx, args)
+		if err == nil {
+			return initial.Target, true, nil
+		}
+		if !IsGuardRejection(err) && !IsRetry(err) {
+			var zero TState
+			return zero, false, err
+		}
+	}
+	return sr.initialTransitionTarget, sr.hasInitialTransition, nil
+}
+
+// IsTerminal returns true if no trigger can move the machine out of this state: neither this state
+// nor any of its superstates configures a transition, reentry, dynamic or internal transition, or
+// a default transition. Ignored triggers are allowed. A dynamic transition makes the state
+// non-terminal even if it declares no possible destinations, since it might transition.
+func (sr *StateRepresentation[TState, TTrigger]) IsTerminal() bool {
+	for rep := sr; rep != nil; rep = rep.superstate {
+		if rep.hasDefaultTransition {
+			return false
+		}
+		for _, behaviours := range rep.triggerBehaviours {
+			for _, behaviour := range behaviours {
+				if _, ok := behaviour.(*IgnoredTriggerBehaviour[TState, TTrigger]); !ok {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// HasDefaultTransition returns true if this state has a catch-all transition configured.
+func (sr *StateRepresentation[TState, TTrigger]) HasDefaultTransition() bool {
+	return sr.hasDefaultTransition
+}
+
+// DefaultDestination returns the destination of the catch-all transition.
+func (sr *StateRepresentation[TState, TTrigger]) DefaultDestination() TState {
+	return sr.defaultDestination
+}
+
+// SetDefaultTransition sets the catch-all transition for triggers without any configured behaviour.
+func (sr *StateRepresentation[TState, TTrigger]) SetDefaultTransition(dst TState) {
+	sr.hasDefaultTransition = true
+	sr.defaultDestination = dst
+	sr.markChanged()
+}
+
+// Tags returns the metadata attached to this state; the map must not be modified.
+func (sr *StateRepresentation[TState, TTrigger]) Tags() map[string]string {
+	return sr.tags
+}
+
+// SetTag attaches a metadata value to this state under key, replacing any previous value.
+func (sr *StateRepresentation[TState, TTrigger]) SetTag(key, value string) {
+	if sr.tags == nil {
+		sr.tags = make(map[string]string)
+	}
+	sr.tags[key] = value
+	sr.markChanged()
+}
+
+// IsFinal returns true if this state was marked final with MarkFinal.
+func (sr *StateRepresentation[TState, TTrigger]) IsFinal() bool {
+	return sr.isFinal
+}
+
+// SetFinal marks this state as final.
+func (sr *StateRepresentation[TState, TTrigger]) SetFinal() {
+	sr.isFinal = true
+	sr.markChanged()
+}
+
+// EntryFailureState returns the state entered when an entry action of this state fails, if any.
+func (sr *StateRepresentation[TState, TTrigger]) EntryFailureState() (TState, bool) {
+	return sr.entryFailureState, sr.hasEntryFailureState
+}
+
+// SetEntryFailureState sets the state entered when an entry action of this state fails.
+func (sr *StateRepresentation[TState, TTrigger]) SetEntryFailureState(dst TState) {
+	sr.hasEntryFailureState = true
+	sr.entryFailureState = dst
+	sr.markChanged()
 }
 
 // CanHandle returns true if this state can handle the specified trigger.
@@ -130,17 +315,47 @@ func (sr *StateRepresentation[TState, TTrigger]) CanHandle(ctx context.Context,
 }
 
 // TryFindHandler attempts to find a handler for the specified trigger.
+// Behaviours configured for the trigger in this state or any superstate take precedence;
+// only when none exist is the innermost default transition (see SetDefaultTransition) used.
 func (sr *StateRepresentation[TState, TTrigger]) TryFindHandler(
 	ctx context.Context,
 	trigger TTrigger,
 	args any,
+) *TriggerBehaviourResult[TState, TTrigger] {
+	result := sr.tryFindConfiguredHandler(ctx, trigger, args)
+	if result != nil {
+		return result
+	}
+
+	for rep := sr; rep != nil; rep = rep.superstate {
+		if rep.hasDefaultTransition {
+			return &TriggerBehaviourResult[TState, TTrigger]{
+				Handler: NewTransitioningTriggerBehaviour(trigger, rep.defaultDestination, EmptyTransitionGuard),
+				Owner:   rep,
+			}
+		}
+	}
+	return nil
+}
+
+// tryFindConfiguredHandler attempts to find a handler among the behaviours configured for the trigger
+// in this state and its superstates.
+func (sr *StateRepresentation[TState, TTrigger]) tryFindConfiguredHandler(
+	ctx context.Context,
+	trigger TTrigger,
+	args any,
 ) *TriggerBehaviourResult[TState, TTrigger] {
 	result := sr.TryFindLocalHandler(ctx, trigger, args)
 
+	// An unexpected guard error fails the fire rather than falling through to a superstate
+	if result != nil && result.UnexpectedError != nil {
+		return result
+	}
+
 	// If no local handler found, or local handler has unmet guards (Handler is nil),
 	// check superstate for a handler
 	if sr.superstate != nil && (result == nil || result.Handler == nil) {
-		superstateResult := sr.superstate.TryFindHandler(ctx, trigger, args)
+		superstateResult := sr.superstate.tryFindConfiguredHandler(ctx, trigger, args)
 		// If superstate has a valid handler, use it
 		if superstateResult != nil && superstateResult.Handler != nil {
 			return superstateResult
@@ -171,8 +386,8 @@ func (sr *StateRepresentation[TState, TTrigger]) TryFindLocalHandler(
 	for _, behaviour := range behaviours {
 		if err := behaviour.GuardConditionsMet(ctx, args); err == nil {
 			possibleBehaviours = append(possibleBehaviours, behaviour)
-		} else if IsGuardRejection(err) {
-			// Expected rejection - guard intentionally blocked
+		} else if IsGuardRejection(err) || IsRetry(err) {
+			// Expected rejection or retry request - guard intentionally blocked
 			rejections = append(rejections, err)
 		} else {
 			// Unexpected error - propagate immediately
@@ -194,6 +409,7 @@ func (sr *StateRepresentation[TState, TTrigger]) TryFindLocalHandler(
 	if len(possibleBehaviours) == 1 {
 		return &TriggerBehaviourResult[TState, TTrigger]{
 			Handler:              possibleBehaviours[0],
+			Owner:                sr,
 			UnmetGuardConditions: nil,
 		}
 	}
@@ -209,48 +425,63 @@ func (sr *StateRepresentation[TState, TTrigger]) TryFindLocalHandler(
 func (sr *StateRepresentation[TState, TTrigger]) AddTriggerBehaviour(behaviour TriggerBehaviour[TState, TTrigger]) {
 	trigger := behaviour.GetTrigger()
 	sr.triggerBehaviours[trigger] = append(sr.triggerBehaviours[trigger], behaviour)
+	sr.markChanged()
 }
 
 // AddEntryAction adds an entry action to this state.
 func (sr *StateRepresentation[TState, TTrigger]) AddEntryAction(action *EntryActionBehaviour[TState, TTrigger]) {
 	sr.entryActions = append(sr.entryActions, action)
+	sr.markChanged()
+}
+
+// PrependEntryAction adds an entry action to this state that runs before all previously added entry actions.
+func (sr *StateRepresentation[TState, TTrigger]) PrependEntryAction(action *EntryActionBehaviour[TState, TTrigger]) {
+	sr.entryActions = append([]*EntryActionBehaviour[TState, TTrigger]{action}, sr.entryActions...)
+	sr.markChanged()
 }
 
 // AddExitAction adds an exit action to this state.
 func (sr *StateRepresentation[TState, TTrigger]) AddExitAction(action *ExitActionBehaviour[TState, TTrigger]) {
 	sr.exitActions = append(sr.exitActions, action)
+	sr.markChanged()
 }
 
 // AddActivateAction adds an activate action to this state.
 func (sr *StateRepresentation[TState, TTrigger]) AddActivateAction(action *ActivateActionBehaviour[TState]) {
 	sr.activateActions = append(sr.activateActions, action)
+	sr.markChanged()
 }
 
 // AddDeactivateAction adds a deactivate action to this state.
 func (sr *StateRepresentation[TState, TTrigger]) AddDeactivateAction(action *DeactivateActionBehaviour[TState]) {
 	sr.deactivateActions = append(sr.deactivateActions, action)
+	sr.markChanged()
 }
 
 // Enter executes entry actions for this state.
+// Only states below the least common ancestor of the source and this state are entered,
+// outermost first; states shared by source and destination are never re-entered.
 func (sr *StateRepresentation[TState, TTrigger]) Enter(
 	ctx context.Context,
 	transition Transition[TState, TTrigger],
+) error {
+	return sr.enter(ctx, transition, nil)
+}
+
+// enter is Enter with an optional action run after the entry actions of each entered state.
+func (sr *StateRepresentation[TState, TTrigger]) enter(
+	ctx context.Context,
+	transition Transition[TState, TTrigger],
+	after TransitionAction[TState, TTrigger],
 ) error {
 	// Reentry - execute entry actions for this state only
-	if transition.Source == transition.Destination {
-		return sr.ExecuteEntryActions(ctx, transition)
+	if transition.Source == transition.Destination || transition.reentry {
+		return sr.executeEntryActions(ctx, transition, after)
 	}
 
-	// If source is not in this state's hierarchy, we need to enter
-	if !sr.Includes(transition.Source) {
-		// For initial transitions, don't enter superstate (we're already in it)
-		// For regular transitions, enter superstate first
-		if sr.superstate != nil && !transition.IsInitial() {
-			if err := sr.superstate.Enter(ctx, transition); err != nil {
-				return err
-			}
-		}
-		return sr.ExecuteEntryActions(ctx, transition)
+	// For initial transitions we are already inside the superstates, so only enter this state
+	if transition.IsInitial() {
+		return sr.executeEntryActions(ctx, transition, after)
 	}
 
 	// Note: When transitioning from a child state to its parent state,
@@ -258,28 +489,91 @@ func (sr *StateRepresentation[TState, TTrigger]) Enter(
 	// "left" the parent state. This matches .NET Stateless behavior.
 	// See: https://github.com/qmuntal/stateless/issues/98
 	// If you need entry actions to fire, use PermitReentry instead.
-
+	var buffer [8]*StateRepresentation[TState, TTrigger]
+	path := sr.statesBelowCommonAncestor(buffer[:0], transition.Source)
+	for i := len(path) - 1; i >= 0; i-- {
+		if err := path[i].executeEntryActions(ctx, transition, after); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 // Exit executes exit actions for this state.
+// Only states below the least common ancestor of this state and the destination are exited,
+// innermost first; states shared by source and destination are never exited.
 func (sr *StateRepresentation[TState, TTrigger]) Exit(
 	ctx context.Context,
 	transition Transition[TState, TTrigger],
+) error {
+	return sr.exit(ctx, transition, nil)
+}
+
+// exit is Exit with an optional action run after the exit actions of each exited state.
+func (sr *StateRepresentation[TState, TTrigger]) exit(
+	ctx context.Context,
+	transition Transition[TState, TTrigger],
+	after TransitionAction[TState, TTrigger],
 ) error {
 	if transition.Source == transition.Destination {
-		return sr.ExecuteExitActions(ctx, transition)
+		return sr.executeExitActions(ctx, transition, after)
 	}
 
-	if !sr.Includes(transition.Destination) {
-		if err := sr.ExecuteExitActions(ctx, transition); err != nil {
-			return err
+	var buffer [8]*StateRepresentation[TState, TTrigger]
+	path := sr.statesBelowCommonAncestor(buffer[:0], transition.Destination)
+	// The reentry of a superstate exits the superstate as well
+	if transition.reentry {
+		if last := len(path) - 1; last >= 0 && path[last].superstate != nil {
+			path = append(path, path[last].superstate)
 		}
-		if sr.superstate != nil {
-			return sr.superstate.Exit(ctx, transition)
+	}
+	for _, rep := range path {
+		if err := rep.executeExitActions(ctx, transition, after); err != nil {
+			return err
 		}
 	}
+	return nil
+}
+
+// statesBelowCommonAncestor appends to path this state followed by its superstates, innermost first,
+// stopping before the least common ancestor, i.e. the first one that also includes the given state.
+func (sr *StateRepresentation[TState, TTrigger]) statesBelowCommonAncestor(
+	path []*StateRepresentation[TState, TTrigger],
+	state TState,
+) []*StateRepresentation[TState, TTrigger] {
+	for rep := sr; rep != nil && !rep.Includes(state); rep = rep.superstate {
+		path = append(path, rep)
+	}
+	return path
+}
+
+// executeEntryActions executes the entry actions of this state, then after if it is not nil.
+func (sr *StateRepresentation[TState, TTrigger]) executeEntryActions(
+	ctx context.Context,
+	transition Transition[TState, TTrigger],
+	after TransitionAction[TState, TTrigger],
+) error {
+	if err := sr.ExecuteEntryActions(ctx, transition); err != nil {
+		return err
+	}
+	if after != nil {
+		return after(ctx, transition)
+	}
+	return nil
+}
 
+// executeExitActions executes the exit actions of this state, then after if it is not nil.
+func (sr *StateRepresentation[TState, TTrigger]) executeExitActions(
+	ctx context.Context,
+	transition Transition[TState, TTrigger],
+	after TransitionAction[TState, TTrigger],
+) error {
+	if err := sr.ExecuteExitActions(ctx, transition); err != nil {
+		return err
+	}
+	if after != nil {
+		return after(ctx, transition)
+	}
 	return nil
 }
 
@@ -301,7 +595,12 @@ func (sr *StateRepresentation[TState, TTrigger]) ExecuteExitActions(
 	ctx context.Context,
 	transition Transition[TState, TTrigger],
 ) error {
-	for _, action := range sr.exitActions {
+	actions := sr.exitActions
+	if sr.reverseExitOrder != nil && sr.reverseExitOrder.Load() {
+		actions = slices.Clone(actions)
+		slices.Reverse(actions)
+	}
+	for _, action := range actions {
 		if err := action.Execute(ctx, transition); err != nil {
 			return err
 		}
@@ -407,6 +706,37 @@ func (sr *StateRepresentation[TState, TTrigger]) GetLocalPermittedTriggers(ctx c
 	return result
 }
 
+// HasPermittedTrigger reports whether GetPermittedTriggers would return any trigger, stopping at the
+// first trigger found whose guards are met, in this state or its superstates.
+func (sr *StateRepresentation[TState, TTrigger]) HasPermittedTrigger(ctx context.Context, args any) bool {
+	for rep := sr; rep != nil; rep = rep.superstate {
+		for _, behaviours := range rep.triggerBehaviours {
+			for _, behaviour := range behaviours {
+				if behaviour.GuardConditionsMet(ctx, args) == nil {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// markChanged records that the configuration of this state has changed.
+// It panics while the owning state machine is processing a trigger.
+func (sr *StateRepresentation[TState, TTrigger]) markChanged() {
+	if sr.sealed != nil && *sr.sealed {
+		panic(fmt.Sprintf("stateless: state '%v' configured on a state machine created by Builder.Build", sr.state))
+	}
+	if sr.processing != nil && sr.processing.Load() > 0 {
+		panic(fmt.Sprintf(
+			"stateless: state '%v' configured while the state machine is processing a trigger; "+
+				"configure states before firing, not from actions or guards", sr.state))
+	}
+	if sr.configVersion != nil {
+		sr.configVersion.Add(1)
+	}
+}
+
 // String returns a string representation of this state.
 func (sr *StateRepresentation[TState, TTrigger]) String() string {
 	return fmt.Sprintf("%v", sr.state)
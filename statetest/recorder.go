@@ -0,0 +1,101 @@
+// Package statetest provides utilities for testing state machines.
+package statetest
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/atlekbai/stateless"
+)
+
+// EventPhase identifies which state machine event was recorded.
+type EventPhase int
+
+const (
+	// PhaseTransitioned indicates an OnTransitioned event.
+	PhaseTransitioned EventPhase = iota
+
+	// PhaseCompleted indicates an OnTransitionCompleted event.
+	PhaseCompleted
+)
+
+// String returns the name of the event phase.
+func (p EventPhase) String() string {
+	switch p {
+	case PhaseTransitioned:
+		return "Transitioned"
+	case PhaseCompleted:
+		return "Completed"
+	default:
+		return fmt.Sprintf("EventPhase(%d)", int(p))
+	}
+}
+
+// RecordedEvent is a single transition event captured by a Recorder.
+type RecordedEvent[TState, TTrigger comparable] struct {
+	// Phase is the event that was raised.
+	Phase EventPhase
+
+	// Source is the state transitioned from.
+	Source TState
+
+	// Destination is the state transitioned to.
+	Destination TState
+
+	// Trigger is the trigger that caused the transition.
+	Trigger TTrigger
+}
+
+// String returns a compact representation of the event, e.g. "Transitioned A -X-> B".
+func (e RecordedEvent[TState, TTrigger]) String() string {
+	return fmt.Sprintf("%v %v -%v-> %v", e.Phase, e.Source, e.Trigger, e.Destination)
+}
+
+// Recorder records the transition events raised by a state machine, in order.
+type Recorder[TState, TTrigger comparable] struct {
+	events []RecordedEvent[TState, TTrigger]
+	mutex  sync.Mutex
+}
+
+// NewRecorder creates a Recorder and registers it for the OnTransitioned and
+// OnTransitionCompleted events of the given state machine.
+func NewRecorder[TState, TTrigger comparable](
+	sm *stateless.StateMachine[TState, TTrigger],
+) *Recorder[TState, TTrigger] {
+	r := &Recorder[TState, TTrigger]{}
+	sm.OnTransitioned(func(t stateless.Transition[TState, TTrigger]) {
+		r.record(PhaseTransitioned, t)
+	})
+	sm.OnTransitionCompleted(func(t stateless.Transition[TState, TTrigger]) {
+		r.record(PhaseCompleted, t)
+	})
+	return r
+}
+
+// Events returns a copy of the events recorded so far, in the order they were raised.
+func (r *Recorder[TState, TTrigger]) Events() []RecordedEvent[TState, TTrigger] {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	events := make([]RecordedEvent[TState, TTrigger], len(r.events))
+	copy(events, r.events)
+	return events
+}
+
+// Reset discards all recorded events.
+func (r *Recorder[TState, TTrigger]) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = nil
+}
+
+// record appends an event for the given transition.
+func (r *Recorder[TState, TTrigger]) record(phase EventPhase, t stateless.Transition[TState, TTrigger]) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, RecordedEvent[TState, TTrigger]{
+		Phase:       phase,
+		Source:      t.Source,
+		Destination: t.Destination,
+		Trigger:     t.Trigger,
+	})
+}
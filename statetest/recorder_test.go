@@ -0,0 +1,53 @@
+package statetest_test
+
+import (
+	"testing"
+
+	"github.com/atlekbai/stateless"
+	"github.com/atlekbai/stateless/statetest"
+)
+
+func TestRecorder_RecordsEventsInOrder(t *testing.T) {
+	sm := stateless.NewStateMachine[string, string]("A")
+	sm.Configure("A").Permit("X", "B")
+	sm.Configure("B").InitialTransition("C")
+	sm.Configure("C").SubstateOf("B")
+
+	rec := statetest.NewRecorder(sm)
+
+	if err := sm.Fire("X", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []statetest.RecordedEvent[string, string]{
+		{Phase: statetest.PhaseTransitioned, Source: "A", Destination: "B", Trigger: "X"},
+		{Phase: statetest.PhaseTransitioned, Source: "B", Destination: "C", Trigger: "X"},
+		{Phase: statetest.PhaseCompleted, Source: "A", Destination: "C", Trigger: "X"},
+	}
+
+	events := rec.Events()
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %v", len(expected), len(events), events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("expected %v at index %d, got %v", expected[i], i, events[i])
+		}
+	}
+}
+
+func TestRecorder_Reset(t *testing.T) {
+	sm := stateless.NewStateMachine[string, string]("A")
+	sm.Configure("A").Permit("X", "B")
+
+	rec := statetest.NewRecorder(sm)
+
+	if err := sm.Fire("X", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rec.Reset()
+
+	if events := rec.Events(); len(events) != 0 {
+		t.Errorf("expected no events after reset, got %v", events)
+	}
+}
@@ -0,0 +1,109 @@
+package stateless
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// ignoreUnhandledKey marks the context of triggers that are dropped silently when nothing handles them,
+// such as those fired by PermitTick and Link.
+type ignoreUnhandledKey struct{}
+
+// withIgnoreUnhandled returns a context marking the fired trigger as dropped when unhandled.
+func withIgnoreUnhandled(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ignoreUnhandledKey{}, true)
+}
+
+// ignoresUnhandled reports whether ctx belongs to a trigger that is dropped when unhandled.
+func ignoresUnhandled(ctx context.Context) bool {
+	ignore, _ := ctx.Value(ignoreUnhandledKey{}).(bool)
+	return ignore
+}
+
+// tickRunner fires one PermitTick trigger repeatedly until stopped.
+type tickRunner struct {
+	mutex   sync.Mutex
+	stopped bool
+	timer   ClockTimer
+}
+
+// stop cancels the pending tick, if any, and prevents further ones.
+func (r *tickRunner) stop() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.stopped = true
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+}
+
+// scheduleTick arranges for the tick to fire after its interval and to reschedule itself afterwards.
+func (sm *StateMachine[TState, TTrigger]) scheduleTick(r *tickRunner, tick Tick[TTrigger]) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.stopped {
+		return
+	}
+	r.timer = sm.clock.AfterFunc(tick.Interval, func() {
+		r.mutex.Lock()
+		stopped := r.stopped
+		r.mutex.Unlock()
+		if stopped {
+			return
+		}
+		ctx := withIgnoreUnhandled(context.Background())
+		_ = sm.FireCtx(ctx, tick.Trigger, nil)
+		sm.scheduleTick(r, tick)
+	})
+}
+
+// updateTickers stops the tickers of states that are no longer active and, while the machine is
+// activated and not in replay mode, starts those of the active states that are not running yet.
+func (sm *StateMachine[TState, TTrigger]) updateTickers() {
+	if !sm.hasTicks.Load() {
+		return
+	}
+	active := sm.ActiveStates()
+	start := sm.isActive && !sm.replayMode
+
+	sm.tickMutex.Lock()
+	defer sm.tickMutex.Unlock()
+	for state, runners := range sm.tickers {
+		if !slices.Contains(active, state) {
+			for _, r := range runners {
+				r.stop()
+			}
+			delete(sm.tickers, state)
+		}
+	}
+	for _, state := range active {
+		if _, running := sm.tickers[state]; running || !start {
+			continue
+		}
+		rep, ok := sm.lookupRepresentation(state)
+		if !ok || len(rep.Ticks()) == 0 {
+			continue
+		}
+		if sm.tickers == nil {
+			sm.tickers = make(map[TState][]*tickRunner)
+		}
+		for _, tick := range rep.Ticks() {
+			r := &tickRunner{}
+			sm.tickers[state] = append(sm.tickers[state], r)
+			sm.scheduleTick(r, tick)
+		}
+	}
+}
+
+// stopTickers stops all running tickers.
+func (sm *StateMachine[TState, TTrigger]) stopTickers() {
+	sm.tickMutex.Lock()
+	defer sm.tickMutex.Unlock()
+	for _, runners := range sm.tickers {
+		for _, r := range runners {
+			r.stop()
+		}
+	}
+	sm.tickers = nil
+}
@@ -0,0 +1,284 @@
+package stateless_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/atlekbai/stateless"
+)
+
+// fakeClock is a manually advanced Clock. Callbacks run synchronously from Advance.
+type fakeClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock   *fakeClock
+	at      time.Time
+	f       func()
+	stopped bool
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) stateless.ClockTimer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	timer := &fakeTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+	wasPending := !t.stopped
+	t.stopped = true
+	return wasPending
+}
+
+// Advance moves the clock forward by d, running due callbacks in order.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	end := c.now.Add(d)
+	c.mutex.Unlock()
+	for {
+		c.mutex.Lock()
+		var next *fakeTimer
+		for _, timer := range c.timers {
+			if !timer.stopped && !timer.at.After(end) && (next == nil || timer.at.Before(next.at)) {
+				next = timer
+			}
+		}
+		if next == nil {
+			c.now = end
+			c.mutex.Unlock()
+			return
+		}
+		next.stopped = true
+		c.now = next.at
+		c.mutex.Unlock()
+		next.f()
+	}
+}
+
+func TestPermitTick(t *testing.T) {
+	clock := &fakeClock{}
+	polls := 0
+	ready := false
+
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued)
+	sm.SetClock(clock)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		PermitTick(TriggerY, time.Second).
+		PermitIf(TriggerY, StateC, func(_ context.Context, _ any) error {
+			polls++
+			if !ready {
+				return stateless.Reject("not ready")
+			}
+			return nil
+		})
+	sm.Configure(StateC)
+	if err := sm.Activate(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clock.Advance(5 * time.Second)
+	if polls != 0 {
+		t.Fatalf("expected no ticks before entering StateB, got %d", polls)
+	}
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clock.Advance(3 * time.Second)
+	if polls != 3 {
+		t.Errorf("expected 3 ticks, got %d", polls)
+	}
+	if sm.State() != StateB {
+		t.Fatalf("expected StateB, got %v", sm.State())
+	}
+
+	ready = true
+	clock.Advance(time.Second)
+	if sm.State() != StateC {
+		t.Fatalf("expected StateC, got %v", sm.State())
+	}
+
+	clock.Advance(5 * time.Second)
+	if polls != 4 {
+		t.Errorf("expected ticks to stop after leaving StateB, got %d polls", polls)
+	}
+}
+
+func TestPermitTick_UnhandledTickIgnored(t *testing.T) {
+	clock := &fakeClock{}
+	unhandled := 0
+
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued)
+	sm.SetClock(clock)
+	sm.OnUnhandledTrigger(func(_ State, _ Trigger, _ []error) {
+		unhandled++
+	})
+	sm.Configure(StateA).PermitTick(TriggerY, time.Second)
+
+	if err := sm.Activate(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clock.Advance(3 * time.Second)
+	if unhandled != 0 {
+		t.Errorf("expected unhandled ticks to be ignored, got %d OnUnhandledTrigger calls", unhandled)
+	}
+
+	if err := sm.Deactivate(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := sm.Fire(TriggerY, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if unhandled != 1 {
+		t.Errorf("expected a fired trigger to still reach OnUnhandledTrigger, got %d calls", unhandled)
+	}
+}
+
+func TestPermitTick_SubstateKeepsTicking(t *testing.T) {
+	clock := &fakeClock{}
+	ticks := 0
+
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued)
+	sm.SetClock(clock)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		PermitTick(TriggerY, time.Second).
+		InternalTransition(TriggerY, func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			ticks++
+			return nil
+		}).
+		Permit(TriggerX, StateC)
+	sm.Configure(StateC).SubstateOf(StateB)
+	if err := sm.Activate(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clock.Advance(time.Second)
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clock.Advance(2 * time.Second)
+
+	if ticks != 3 {
+		t.Errorf("expected 3 ticks across StateB and its substate, got %d", ticks)
+	}
+}
+
+func TestPermitTick_NotActivated(t *testing.T) {
+	clock := &fakeClock{}
+	ticks := 0
+
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued)
+	sm.SetClock(clock)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		PermitTick(TriggerY, time.Second).
+		InternalTransition(TriggerY, func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			ticks++
+			return nil
+		})
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clock.Advance(3 * time.Second)
+	if ticks != 0 {
+		t.Errorf("expected no ticks before Activate, got %d", ticks)
+	}
+
+	if err := sm.Activate(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clock.Advance(2 * time.Second)
+	if ticks != 2 {
+		t.Errorf("expected 2 ticks after Activate, got %d", ticks)
+	}
+}
+
+func TestPermitTick_ReplayMode(t *testing.T) {
+	clock := &fakeClock{}
+	ticks := 0
+
+	sm := stateless.NewStateMachineWithMode[State, Trigger](StateA, stateless.FiringQueued)
+	sm.SetClock(clock)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		PermitTick(TriggerY, time.Second).
+		InternalTransition(TriggerY, func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			ticks++
+			return nil
+		})
+	if err := sm.Activate(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sm.SetReplayMode(true)
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clock.Advance(3 * time.Second)
+	if ticks != 0 {
+		t.Errorf("expected no ticks in replay mode, got %d", ticks)
+	}
+
+	sm.SetReplayMode(false)
+	clock.Advance(2 * time.Second)
+	if ticks != 2 {
+		t.Errorf("expected ticks to start when replay mode is turned off, got %d", ticks)
+	}
+}
+
+func TestOnTransitionTimed(t *testing.T) {
+	clock := &fakeClock{}
+	var timed []time.Duration
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.SetClock(clock)
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		OnEntry(func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			clock.Advance(300 * time.Millisecond)
+			return nil
+		}).
+		InternalTransition(TriggerY, func(_ context.Context, _ stateless.Transition[State, Trigger]) error {
+			clock.Advance(time.Second)
+			return nil
+		})
+	sm.OnTransitionTimed(func(tr stateless.Transition[State, Trigger], d time.Duration) {
+		if tr.Source != StateA || tr.Destination != StateB {
+			t.Errorf("unexpected transition %v -> %v", tr.Source, tr.Destination)
+		}
+		timed = append(timed, d)
+	})
+
+	if err := sm.Fire(TriggerX, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := sm.Fire(TriggerY, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(timed, []time.Duration{300 * time.Millisecond}) {
+		t.Errorf("expected only the external transition to be timed at 300ms, got %v", timed)
+	}
+}
@@ -1,6 +1,9 @@
 package stateless
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 // TransitionAction is a function that is executed during a state transition.
 // It receives a context and the transition information, and returns an error if the action fails.
@@ -9,6 +12,42 @@ type TransitionAction[TState, TTrigger comparable] func(
 	t Transition[TState, TTrigger],
 ) error
 
+// TransitionKind describes how a trigger was handled.
+type TransitionKind int
+
+const (
+	// TransitionExternal indicates a regular transition (including reentry) that ran exit and entry actions.
+	// This is the default kind.
+	TransitionExternal TransitionKind = iota
+
+	// TransitionInternal indicates an internal transition: the state did not change
+	// and only the internal action was executed.
+	TransitionInternal
+
+	// TransitionIgnored indicates that the trigger was ignored and nothing happened.
+	TransitionIgnored
+
+	// TransitionForced indicates a transition made by GoTo rather than by firing a trigger.
+	// Exit and entry actions ran, and the Trigger is the zero value.
+	TransitionForced
+)
+
+// String returns the name of the transition kind.
+func (k TransitionKind) String() string {
+	switch k {
+	case TransitionExternal:
+		return "External"
+	case TransitionInternal:
+		return "Internal"
+	case TransitionIgnored:
+		return "Ignored"
+	case TransitionForced:
+		return "Forced"
+	default:
+		return fmt.Sprintf("TransitionKind(%d)", int(k))
+	}
+}
+
 // Transition describes a state transition.
 type Transition[TState, TTrigger comparable] struct {
 	// Source is the state transitioned from.
@@ -25,8 +64,25 @@ type Transition[TState, TTrigger comparable] struct {
 	//   if args, ok := t.Args.(MyArgs); ok { ... }
 	Args any
 
+	// Kind describes how the trigger was handled (external, internal, ignored or forced).
+	Kind TransitionKind
+
 	// isInitial indicates if this is an initial transition (entering the state machine).
 	isInitial bool
+
+	// completed is set on the transition reported once a trigger was handled, whose destination is
+	// the final state; viaInitial is only recorded on those.
+	completed bool
+
+	// reentry indicates that the trigger reentered its source state, or on a transition to a
+	// superstate of the source, that the superstate is reentered; see PermitDynamicReentry.
+	reentry bool
+
+	// viaInitial indicates that initial transitions were applied after entering the destination.
+	viaInitial bool
+
+	// result receives the value set with SetResult when the trigger is fired with FireForValue.
+	result *transitionResult
 }
 
 // NewTransition creates a new transition.
@@ -58,12 +114,34 @@ func NewInitialTransition[TState, TTrigger comparable](
 	}
 }
 
-// IsReentry returns true if the transition is a re-entry, i.e., the identity transition.
+// IsReentry returns true if the transition is a re-entry, i.e., the identity transition, or the
+// reentry of a superstate of the source made with PermitDynamicReentry. Internal transitions and
+// ignored triggers are not re-entries. For a completed transition, such as one returned by
+// FireResult, it reports whether the trigger reentered a state, even if initial transitions or
+// triggers fired from actions then moved the machine elsewhere.
 func (t Transition[TState, TTrigger]) IsReentry() bool {
-	return any(t.Source) == any(t.Destination)
+	if t.completed || t.reentry {
+		return t.Kind == TransitionExternal && t.reentry
+	}
+	return t.Kind == TransitionExternal && any(t.Source) == any(t.Destination)
 }
 
 // IsInitial returns true if this is an initial transition.
 func (t Transition[TState, TTrigger]) IsInitial() bool {
 	return t.isInitial
 }
+
+// HasInitialTransition returns true if initial transitions moved the machine from the state the
+// trigger led to into one of its substates, which is then the Destination. It is only set on
+// completed transitions, such as those returned by FireResult or raised with OnTransitionCompleted.
+func (t Transition[TState, TTrigger]) HasInitialTransition() bool {
+	return t.viaInitial
+}
+
+// SetResult sets the value returned by FireForValue for the trigger being fired. It does nothing
+// when the trigger was fired otherwise.
+func (t Transition[TState, TTrigger]) SetResult(value any) {
+	if t.result != nil {
+		t.result.set(value)
+	}
+}
@@ -0,0 +1,41 @@
+package stateless
+
+import "fmt"
+
+// AliasTriggers makes firing any of the aliases equivalent to firing canonical: the trigger is
+// replaced by canonical before args are validated and handlers are resolved, so transitions,
+// actions and events report canonical. CanFire and HandlingState resolve aliases the same way.
+// Graphs and GetInfo show only the canonical trigger, since the configuration uses it.
+//
+// Aliases must be set up before firing. It panics if an alias equals canonical, if canonical is
+// itself an alias, or if an alias is already the canonical trigger of other aliases.
+func (sm *StateMachine[TState, TTrigger]) AliasTriggers(canonical TTrigger, aliases ...TTrigger) {
+	sm.panicIfSealed("AliasTriggers")
+	if _, ok := sm.triggerAliases[canonical]; ok {
+		panic(fmt.Sprintf("stateless: trigger '%v' is an alias and cannot be a canonical trigger", canonical))
+	}
+	for _, alias := range aliases {
+		if alias == canonical {
+			panic(fmt.Sprintf("stateless: trigger '%v' cannot be an alias of itself", alias))
+		}
+		for _, target := range sm.triggerAliases {
+			if target == alias {
+				panic(fmt.Sprintf("stateless: trigger '%v' has aliases and cannot be an alias", alias))
+			}
+		}
+	}
+	if sm.triggerAliases == nil {
+		sm.triggerAliases = make(map[TTrigger]TTrigger)
+	}
+	for _, alias := range aliases {
+		sm.triggerAliases[alias] = canonical
+	}
+}
+
+// canonicalTrigger returns the trigger an alias stands for, or the trigger itself.
+func (sm *StateMachine[TState, TTrigger]) canonicalTrigger(trigger TTrigger) TTrigger {
+	if canonical, ok := sm.triggerAliases[trigger]; ok {
+		return canonical
+	}
+	return trigger
+}
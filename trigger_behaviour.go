@@ -100,6 +100,12 @@ type DynamicTriggerBehaviour[TState, TTrigger comparable] struct {
 
 	destination    StateSelector[TState]
 	TransitionInfo DynamicTransitionInfo
+
+	// back makes the state machine take the previous state as destination; see PermitBack.
+	back bool
+
+	// reentry makes a destination that includes the source state be reentered; see PermitDynamicReentry.
+	reentry bool
 }
 
 // NewDynamicTriggerBehaviour creates a new dynamic trigger behaviour.
@@ -130,6 +136,9 @@ type InternalTriggerBehaviour[TState, TTrigger comparable] struct {
 	triggerBehaviourBase[TState, TTrigger]
 
 	internalAction TransitionAction[TState, TTrigger]
+
+	// ActionDescription describes the internal action.
+	ActionDescription InvocationInfo
 }
 
 // NewInternalTriggerBehaviour creates a new internal trigger behaviour.
@@ -143,7 +152,8 @@ func NewInternalTriggerBehaviour[TState, TTrigger comparable](
 			trigger: tr,
 			guard:   tg,
 		},
-		internalAction: act,
+		internalAction:    act,
+		ActionDescription: CreateInvocationInfo(act, ""),
 	}
 }
 
@@ -158,12 +168,74 @@ func (s *InternalTriggerBehaviour[TState, TTrigger]) Execute(
 	return nil
 }
 
+// InternalOrTransitionTriggerBehaviour represents an internal transition that becomes a regular
+// transition when its selector picks a destination.
+type InternalOrTransitionTriggerBehaviour[TState, TTrigger comparable] struct {
+	triggerBehaviourBase[TState, TTrigger]
+
+	selector       func(ctx context.Context, args any) (TState, bool, error)
+	internalAction TransitionAction[TState, TTrigger]
+
+	// ActionDescription describes the internal action.
+	ActionDescription InvocationInfo
+
+	// TransitionInfo describes the transition taken when the selector picks a destination.
+	TransitionInfo DynamicTransitionInfo
+}
+
+// NewInternalOrTransitionTriggerBehaviour creates a new internal-or-transition trigger behaviour.
+func NewInternalOrTransitionTriggerBehaviour[TState, TTrigger comparable](
+	tr TTrigger,
+	selector func(ctx context.Context, args any) (TState, bool, error),
+	act TransitionAction[TState, TTrigger],
+	tg TransitionGuard,
+) *InternalOrTransitionTriggerBehaviour[TState, TTrigger] {
+	return &InternalOrTransitionTriggerBehaviour[TState, TTrigger]{
+		triggerBehaviourBase: triggerBehaviourBase[TState, TTrigger]{
+			trigger: tr,
+			guard:   tg,
+		},
+		selector:          selector,
+		internalAction:    act,
+		ActionDescription: CreateInvocationInfo(act, ""),
+		TransitionInfo: DynamicTransitionInfo{
+			transitionInfoBase: transitionInfoBase{
+				Trigger: NewTriggerInfo(tr),
+			},
+			DestinationStateSelectorDescription: CreateInvocationInfo(selector, ""),
+		},
+	}
+}
+
+// SelectDestination returns the state to transition to, or false if the internal action should run instead.
+func (b *InternalOrTransitionTriggerBehaviour[TState, TTrigger]) SelectDestination(
+	ctx context.Context,
+	args any,
+) (TState, bool, error) {
+	return b.selector(ctx, args)
+}
+
+// Execute executes the internal action.
+func (b *InternalOrTransitionTriggerBehaviour[TState, TTrigger]) Execute(
+	ctx context.Context,
+	t Transition[TState, TTrigger],
+) error {
+	if b.internalAction != nil {
+		return b.internalAction(ctx, t)
+	}
+	return nil
+}
+
 // TriggerBehaviourResult represents the result of finding a trigger behaviour.
 type TriggerBehaviourResult[TState, TTrigger comparable] struct {
 	// Handler is the trigger behaviour that was found.
 	Handler TriggerBehaviour[TState, TTrigger]
 
-	// UnmetGuardConditions contains expected guard rejections (GuardRejection errors).
+	// Owner is the state representation that configures Handler, which is a superstate of the
+	// state the trigger was fired in when the behaviour is inherited. Nil when Handler is nil.
+	Owner *StateRepresentation[TState, TTrigger]
+
+	// UnmetGuardConditions contains expected guard rejections (GuardRejection and Retry errors).
 	UnmetGuardConditions []error
 
 	// UnexpectedError contains an unexpected error that occurred during guard evaluation.
@@ -0,0 +1,66 @@
+package stateless
+
+import "context"
+
+// TriggerDetails describes a trigger that can be fired from the current state.
+type TriggerDetails[TState, TTrigger comparable] struct {
+	// Trigger is the permitted trigger.
+	Trigger TTrigger
+
+	// DestinationState is the state the machine would move to, valid when DestinationKnown is set.
+	// For internal transitions and ignored triggers it is the current state.
+	DestinationState TState
+
+	// DestinationKnown is false for dynamic transitions, whose destination is only chosen when
+	// the trigger is fired.
+	DestinationKnown bool
+
+	// SatisfiedGuards are the descriptions of the guard conditions of the behaviour that would
+	// handle the trigger, all of which are met.
+	SatisfiedGuards []string
+}
+
+// GetDetailedPermittedTriggers returns, for each trigger that would be handled if fired from the
+// current state with the given args, the destination and the guards that allow it, ordered by
+// trigger. Unlike GetPermittedTriggers, a trigger whose met behaviours are ambiguous is left out,
+// since firing it would fail. Guards are evaluated but no selectors or actions run.
+func (sm *StateMachine[TState, TTrigger]) GetDetailedPermittedTriggers(
+	ctx context.Context,
+	args any,
+) []TriggerDetails[TState, TTrigger] {
+	representation := sm.currentRepresentation()
+
+	seen := make(map[TTrigger]struct{})
+	var triggers []TTrigger
+	for rep := representation; rep != nil; rep = rep.Superstate() {
+		for trigger := range rep.TriggerBehaviours() {
+			if _, ok := seen[trigger]; !ok {
+				seen[trigger] = struct{}{}
+				triggers = append(triggers, trigger)
+			}
+		}
+	}
+	sortValues(triggers)
+
+	var details []TriggerDetails[TState, TTrigger]
+	for _, trigger := range sm.withoutDisabledTriggers(triggers) {
+		result := representation.TryFindHandler(ctx, trigger, args)
+		if result == nil || result.Handler == nil {
+			continue
+		}
+		detail := TriggerDetails[TState, TTrigger]{
+			Trigger:         trigger,
+			SatisfiedGuards: guardDescriptions(convertGuardConditions(result.Handler.GetGuard().Conditions)),
+		}
+		switch b := result.Handler.(type) {
+		case *TransitioningTriggerBehaviour[TState, TTrigger]:
+			detail.DestinationState, detail.DestinationKnown = b.Destination, true
+		case *ReentryTriggerBehaviour[TState, TTrigger]:
+			detail.DestinationState, detail.DestinationKnown = b.Destination, true
+		case *InternalTriggerBehaviour[TState, TTrigger], *IgnoredTriggerBehaviour[TState, TTrigger]:
+			detail.DestinationState, detail.DestinationKnown = representation.UnderlyingState(), true
+		}
+		details = append(details, detail)
+	}
+	return details
+}
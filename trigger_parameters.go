@@ -0,0 +1,263 @@
+package stateless
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// TriggerWithParameters1 associates a trigger with the type of its single argument,
+// so that the argument can be checked at compile time when the trigger is fired.
+type TriggerWithParameters1[TTrigger comparable, TArg0 any] struct {
+	trigger TTrigger
+}
+
+// NewTriggerWithParameters1 creates a trigger that takes a single typed argument.
+// The argument type comes first so that the trigger type can be inferred:
+//
+//	assign := stateless.NewTriggerWithParameters1[string](TriggerAssign)
+func NewTriggerWithParameters1[TArg0 any, TTrigger comparable](
+	trigger TTrigger,
+) *TriggerWithParameters1[TTrigger, TArg0] {
+	return &TriggerWithParameters1[TTrigger, TArg0]{trigger: trigger}
+}
+
+// Trigger returns the underlying trigger.
+func (t *TriggerWithParameters1[TTrigger, TArg0]) Trigger() TTrigger {
+	return t.trigger
+}
+
+// FireWith1 fires a parameterized trigger with a typed argument.
+// The argument is passed to actions as Transition.Args and can be retrieved with t.Args.(TArg0).
+func FireWith1[TState, TTrigger comparable, TArg0 any](
+	sm *StateMachine[TState, TTrigger],
+	trigger *TriggerWithParameters1[TTrigger, TArg0],
+	arg0 TArg0,
+) error {
+	return FireCtxWith1(context.Background(), sm, trigger, arg0)
+}
+
+// FireCtxWith1 fires a parameterized trigger with a context and a typed argument.
+func FireCtxWith1[TState, TTrigger comparable, TArg0 any](
+	ctx context.Context,
+	sm *StateMachine[TState, TTrigger],
+	trigger *TriggerWithParameters1[TTrigger, TArg0],
+	arg0 TArg0,
+) error {
+	return sm.FireCtx(ctx, trigger.Trigger(), arg0)
+}
+
+// TriggerWithParameters3 associates a trigger with the types of its three arguments, so that they
+// can be checked at compile time when the trigger is fired and when guards, selectors and entry
+// actions receive them.
+type TriggerWithParameters3[TTrigger comparable, TArg0, TArg1, TArg2 any] struct {
+	trigger TTrigger
+}
+
+// Args3 carries the arguments of a TriggerWithParameters3. It is passed as Transition.Args.
+type Args3[TArg0, TArg1, TArg2 any] struct {
+	Arg0 TArg0
+	Arg1 TArg1
+	Arg2 TArg2
+}
+
+// NewTriggerWithParameters3 creates a trigger that takes three typed arguments.
+// The argument types come first so that the trigger type can be inferred:
+//
+//	move := stateless.NewTriggerWithParameters3[int, int, string](TriggerMove)
+func NewTriggerWithParameters3[TArg0, TArg1, TArg2 any, TTrigger comparable](
+	trigger TTrigger,
+) *TriggerWithParameters3[TTrigger, TArg0, TArg1, TArg2] {
+	return &TriggerWithParameters3[TTrigger, TArg0, TArg1, TArg2]{trigger: trigger}
+}
+
+// Trigger returns the underlying trigger.
+func (t *TriggerWithParameters3[TTrigger, TArg0, TArg1, TArg2]) Trigger() TTrigger {
+	return t.trigger
+}
+
+// unpack converts the args a trigger was fired with back to the typed arguments, and returns an
+// ArgumentTypeError if they were not fired with FireWith3 or an Args3 of the same types.
+func (t *TriggerWithParameters3[TTrigger, TArg0, TArg1, TArg2]) unpack(
+	args any,
+) (Args3[TArg0, TArg1, TArg2], error) {
+	typed, ok := args.(Args3[TArg0, TArg1, TArg2])
+	if !ok {
+		err := &ArgumentTypeError{Trigger: t.trigger, Expected: reflect.TypeFor[Args3[TArg0, TArg1, TArg2]]()}
+		if args != nil {
+			err.Actual = reflect.TypeOf(args)
+		}
+		return typed, err
+	}
+	return typed, nil
+}
+
+// FireWith3 fires a parameterized trigger with three typed arguments.
+// The arguments are passed to actions as an Args3 in Transition.Args.
+func FireWith3[TState, TTrigger comparable, TArg0, TArg1, TArg2 any](
+	sm *StateMachine[TState, TTrigger],
+	trigger *TriggerWithParameters3[TTrigger, TArg0, TArg1, TArg2],
+	arg0 TArg0,
+	arg1 TArg1,
+	arg2 TArg2,
+) error {
+	return FireCtxWith3(context.Background(), sm, trigger, arg0, arg1, arg2)
+}
+
+// FireCtxWith3 fires a parameterized trigger with a context and three typed arguments.
+func FireCtxWith3[TState, TTrigger comparable, TArg0, TArg1, TArg2 any](
+	ctx context.Context,
+	sm *StateMachine[TState, TTrigger],
+	trigger *TriggerWithParameters3[TTrigger, TArg0, TArg1, TArg2],
+	arg0 TArg0,
+	arg1 TArg1,
+	arg2 TArg2,
+) error {
+	return sm.FireCtx(ctx, trigger.Trigger(), Args3[TArg0, TArg1, TArg2]{Arg0: arg0, Arg1: arg1, Arg2: arg2})
+}
+
+// PermitIf3 configures the state to transition to the specified destination state when the
+// parameterized trigger is fired, if the guard, which receives the typed arguments, is met.
+// It behaves as StateNode.PermitIf otherwise; args of the wrong type fail the guard with an
+// ArgumentTypeError.
+func PermitIf3[TState, TTrigger comparable, TArg0, TArg1, TArg2 any](
+	sn *StateNode[TState, TTrigger],
+	trigger *TriggerWithParameters3[TTrigger, TArg0, TArg1, TArg2],
+	dst TState,
+	guard func(ctx context.Context, arg0 TArg0, arg1 TArg1, arg2 TArg2) error,
+	description ...string,
+) *StateNode[TState, TTrigger] {
+	typedGuard := func(ctx context.Context, args any) error {
+		typed, err := trigger.unpack(args)
+		if err != nil {
+			return err
+		}
+		return guard(ctx, typed.Arg0, typed.Arg1, typed.Arg2)
+	}
+	return sn.permit(trigger.Trigger(), dst, TransitionGuard{
+		Conditions: []GuardCondition{
+			NewGuardCondition(typedGuard, CreateInvocationInfo(guard, optionalDescription(description))),
+		},
+	})
+}
+
+// PermitDynamic3 configures the state to transition to a destination chosen by a selector that
+// receives the typed arguments of the parameterized trigger. It behaves as StateNode.PermitDynamic
+// otherwise; args of the wrong type abort the fire with an ArgumentTypeError.
+func PermitDynamic3[TState, TTrigger comparable, TArg0, TArg1, TArg2 any](
+	sn *StateNode[TState, TTrigger],
+	trigger *TriggerWithParameters3[TTrigger, TArg0, TArg1, TArg2],
+	selector func(ctx context.Context, arg0 TArg0, arg1 TArg1, arg2 TArg2) (TState, error),
+	possibleDestinations ...DynamicStateInfo,
+) *StateNode[TState, TTrigger] {
+	typedSelector := func(ctx context.Context, args any) (TState, error) {
+		typed, err := trigger.unpack(args)
+		if err != nil {
+			var zero TState
+			return zero, err
+		}
+		return selector(ctx, typed.Arg0, typed.Arg1, typed.Arg2)
+	}
+	info := DynamicTransitionInfo{
+		transitionInfoBase: transitionInfoBase{
+			Trigger: NewTriggerInfo(trigger.Trigger()),
+		},
+		DestinationStateSelectorDescription: CreateInvocationInfo(selector, ""),
+		PossibleDestinationStates:           possibleDestinations,
+	}
+	sn.representation.AddTriggerBehaviour(
+		NewDynamicTriggerBehaviour(trigger.Trigger(), typedSelector, EmptyTransitionGuard, info),
+	)
+	return sn
+}
+
+// OnEntryFrom3 configures an action to be executed when the state is entered through the
+// parameterized trigger. The action receives the typed arguments along with the transition;
+// entering the state through other triggers does not run it.
+func OnEntryFrom3[TState, TTrigger comparable, TArg0, TArg1, TArg2 any](
+	sn *StateNode[TState, TTrigger],
+	trigger *TriggerWithParameters3[TTrigger, TArg0, TArg1, TArg2],
+	act func(ctx context.Context, arg0 TArg0, arg1 TArg1, arg2 TArg2, t Transition[TState, TTrigger]) error,
+) *StateNode[TState, TTrigger] {
+	sn.representation.AddEntryAction(
+		NewEntryActionBehaviour(func(ctx context.Context, t Transition[TState, TTrigger]) error {
+			if t.Trigger != trigger.Trigger() {
+				return nil
+			}
+			typed, err := trigger.unpack(t.Args)
+			if err != nil {
+				return err
+			}
+			return act(ctx, typed.Arg0, typed.Arg1, typed.Arg2, t)
+		}, CreateInvocationInfo(act, "")),
+	)
+	return sn
+}
+
+// SetTriggerParameters registers the type of the arguments expected by a trigger.
+// When the trigger is fired, its args must be non-nil and assignable to argType,
+// otherwise Fire returns an ArgumentTypeError without evaluating guards or running actions.
+// Use reflect.TypeFor to obtain the type:
+//
+//	sm.SetTriggerParameters(TriggerAssign, reflect.TypeFor[AssignArgs]())
+//
+// Passing a nil argType removes the registration. Triggers without a registration accept any args.
+func (sm *StateMachine[TState, TTrigger]) SetTriggerParameters(trigger TTrigger, argType reflect.Type) {
+	sm.panicIfSealed("SetTriggerParameters")
+	if argType == nil {
+		delete(sm.triggerParameters, trigger)
+		return
+	}
+	if sm.triggerParameters == nil {
+		sm.triggerParameters = make(map[TTrigger]reflect.Type)
+	}
+	sm.triggerParameters[trigger] = argType
+}
+
+// validateTriggerArgs checks the args of a fired trigger against its registered parameter type.
+func (sm *StateMachine[TState, TTrigger]) validateTriggerArgs(trigger TTrigger, args any) error {
+	expected, ok := sm.triggerParameters[trigger]
+	if !ok {
+		return nil
+	}
+	if args == nil {
+		return &ArgumentTypeError{Trigger: trigger, Expected: expected}
+	}
+	if actual := reflect.TypeOf(args); !actual.AssignableTo(expected) {
+		return &ArgumentTypeError{Trigger: trigger, Expected: expected, Actual: actual}
+	}
+	return nil
+}
+
+// SetArgDecoder registers a function that converts the args of a trigger before it is handled,
+// so that guards, selectors and actions receive the decoded value as Transition.Args. This is useful
+// for triggers whose args arrive in a raw form, such as a JSON payload. A decode error aborts the fire
+// with a ParameterConversionError wrapping it. The decoded args are what SetTriggerParameters validates.
+// Passing a nil decoder removes the registration.
+func (sm *StateMachine[TState, TTrigger]) SetArgDecoder(trigger TTrigger, decode func(any) (any, error)) {
+	sm.panicIfSealed("SetArgDecoder")
+	if decode == nil {
+		delete(sm.argDecoders, trigger)
+		return
+	}
+	if sm.argDecoders == nil {
+		sm.argDecoders = make(map[TTrigger]func(any) (any, error))
+	}
+	sm.argDecoders[trigger] = decode
+}
+
+// decodeTriggerArgs converts the args of a fired trigger with its registered decoder, if any.
+func (sm *StateMachine[TState, TTrigger]) decodeTriggerArgs(trigger TTrigger, args any) (any, error) {
+	decode, ok := sm.argDecoders[trigger]
+	if !ok {
+		return args, nil
+	}
+	decoded, err := decode(args)
+	if err != nil {
+		return nil, &ParameterConversionError{
+			Message: fmt.Sprintf("decoding args of trigger '%v': %v", trigger, err),
+			Err:     err,
+		}
+	}
+	return decoded, nil
+}
@@ -0,0 +1,212 @@
+package stateless_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/atlekbai/stateless"
+)
+
+func TestFireWith1_PassesTypedArgument(t *testing.T) {
+	var received string
+
+	assign := stateless.NewTriggerWithParameters1[string](TriggerX)
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).Permit(assign.Trigger(), StateB)
+	sm.Configure(StateB).
+		OnEntry(func(ctx context.Context, tr stateless.Transition[State, Trigger]) error {
+			if arg, ok := tr.Args.(string); ok {
+				received = arg
+			}
+			return nil
+		})
+
+	if err := stateless.FireWith1(sm, assign, "alice"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sm.State() != StateB {
+		t.Errorf("expected StateB, got %v", sm.State())
+	}
+	if received != "alice" {
+		t.Errorf("expected argument 'alice', got %q", received)
+	}
+}
+
+func TestFireCtxWith1_UsesContext(t *testing.T) {
+	type ctxKey struct{}
+
+	var received any
+
+	trigger := stateless.NewTriggerWithParameters1[int](TriggerX)
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.Configure(StateA).
+		PermitIf(trigger.Trigger(), StateB, func(ctx context.Context, args any) error {
+			received = ctx.Value(ctxKey{})
+			if n, ok := args.(int); !ok || n <= 0 {
+				return stateless.Reject("expected positive int")
+			}
+			return nil
+		})
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	if err := stateless.FireCtxWith1(ctx, sm, trigger, 42); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if received != "value" {
+		t.Errorf("expected context value to reach guard, got %v", received)
+	}
+	if sm.State() != StateB {
+		t.Errorf("expected StateB, got %v", sm.State())
+	}
+}
+
+func TestSetTriggerParameters_ValidatesArgs(t *testing.T) {
+	type assignArgs struct{ Name string }
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.SetTriggerParameters(TriggerX, reflect.TypeFor[assignArgs]())
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+
+	var typeErr *stateless.ArgumentTypeError
+	if err := sm.Fire(TriggerX, "alice"); !errors.As(err, &typeErr) {
+		t.Fatalf("expected ArgumentTypeError, got %v", err)
+	}
+	if typeErr.Actual != reflect.TypeFor[string]() {
+		t.Errorf("expected actual type string, got %v", typeErr.Actual)
+	}
+
+	if err := sm.Fire(TriggerX, nil); !errors.As(err, &typeErr) {
+		t.Fatalf("expected ArgumentTypeError for nil args, got %v", err)
+	}
+	if typeErr.Actual != nil {
+		t.Errorf("expected no actual type, got %v", typeErr.Actual)
+	}
+
+	if sm.State() != StateA {
+		t.Fatalf("expected StateA after rejected args, got %v", sm.State())
+	}
+
+	if err := sm.Fire(TriggerX, assignArgs{Name: "alice"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.State() != StateB {
+		t.Errorf("expected StateB, got %v", sm.State())
+	}
+}
+
+func TestSetTriggerParameters_InterfaceType(t *testing.T) {
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.SetTriggerParameters(TriggerX, reflect.TypeFor[error]())
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+
+	if err := sm.Fire(TriggerX, errors.New("cause")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSetArgDecoder(t *testing.T) {
+	type assignArgs struct{ Name string }
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	sm.SetArgDecoder(TriggerX, func(raw any) (any, error) {
+		data, ok := raw.([]byte)
+		if !ok {
+			return nil, errors.New("expected JSON bytes")
+		}
+		var args assignArgs
+		err := json.Unmarshal(data, &args)
+		return args, err
+	})
+	sm.SetTriggerParameters(TriggerX, reflect.TypeFor[assignArgs]())
+
+	var received assignArgs
+	sm.Configure(StateA).Permit(TriggerX, StateB)
+	sm.Configure(StateB).
+		OnEntry(func(_ context.Context, tr stateless.Transition[State, Trigger]) error {
+			received = tr.Args.(assignArgs)
+			return nil
+		})
+
+	var convErr *stateless.ParameterConversionError
+	if err := sm.Fire(TriggerX, "not bytes"); !errors.As(err, &convErr) {
+		t.Fatalf("expected ParameterConversionError, got %v", err)
+	}
+	if sm.State() != StateA {
+		t.Fatalf("expected StateA after decode failure, got %v", sm.State())
+	}
+
+	if err := sm.Fire(TriggerX, []byte(`{"Name":"alice"}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received.Name != "alice" {
+		t.Errorf("expected decoded name 'alice', got %q", received.Name)
+	}
+}
+
+func TestTriggerWithParameters3(t *testing.T) {
+	move := stateless.NewTriggerWithParameters3[int, int, string](TriggerX)
+
+	type moved struct {
+		x, y  int
+		label string
+	}
+	var entries []moved
+
+	sm := stateless.NewStateMachine[State, Trigger](StateA)
+	stateless.PermitIf3(sm.Configure(StateA), move, StateB, func(_ context.Context, x, y int, _ string) error {
+		if x < 0 || y < 0 {
+			return stateless.Reject("negative coordinates")
+		}
+		return nil
+	}, "on the board")
+	stateless.PermitDynamic3(sm.Configure(StateB), move,
+		func(_ context.Context, x, _ int, _ string) (State, error) {
+			if x > 10 {
+				return StateC, nil
+			}
+			return StateA, nil
+		})
+	stateless.OnEntryFrom3(sm.Configure(StateB), move,
+		func(_ context.Context, x, y int, label string, _ stateless.Transition[State, Trigger]) error {
+			entries = append(entries, moved{x, y, label})
+			return nil
+		})
+	sm.Configure(StateC)
+
+	if err := stateless.FireWith3(sm, move, -1, 2, "off"); err == nil {
+		t.Fatal("expected the guard to reject negative coordinates")
+	}
+	if err := stateless.FireWith3(sm, move, 1, 2, "start"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := stateless.FireWith3(sm, move, 11, 0, "far"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sm.State() != StateC {
+		t.Errorf("expected StateC, got %v", sm.State())
+	}
+	if len(entries) != 1 || entries[0] != (moved{1, 2, "start"}) {
+		t.Errorf("expected one entry with the typed args, got %v", entries)
+	}
+	guards := sm.GetInfo().States[0].FixedTransitions[0].GuardConditions
+	if len(guards) != 1 || guards[0].Description() != "on the board" {
+		t.Errorf("expected the guard description, got %v", guards)
+	}
+
+	untyped := stateless.NewStateMachine[State, Trigger](StateA)
+	stateless.PermitIf3(untyped.Configure(StateA), move, StateB, func(context.Context, int, int, string) error {
+		return nil
+	})
+	var typeErr *stateless.ArgumentTypeError
+	if err := untyped.Fire(TriggerX, "not typed"); !errors.As(err, &typeErr) {
+		t.Errorf("expected ArgumentTypeError, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package stateless
+
+import (
+	"context"
+	"slices"
+)
+
+// QueuedEvent is a fired trigger waiting in the queue of a FiringQueued state machine.
+type QueuedEvent[TTrigger comparable] struct {
+	Event[TTrigger]
+
+	// Context is the context the trigger was fired with.
+	Context context.Context
+}
+
+// TriggerQueue stores the triggers of a FiringQueued state machine until they are processed.
+// Events must be popped in the order they were pushed. The state machine serializes all calls,
+// so implementations do not need to be safe for concurrent use.
+//
+// A custom implementation can back the queue with durable storage so that pending triggers
+// survive a restart; the Context of a restored event is up to the implementation.
+type TriggerQueue[TTrigger comparable] interface {
+	// Push appends an event to the back of the queue.
+	Push(event QueuedEvent[TTrigger])
+	// Pop removes and returns the event at the front of the queue, or false if the queue is empty.
+	Pop() (QueuedEvent[TTrigger], bool)
+	// Len returns the number of queued events.
+	Len() int
+	// Events returns the queued events, front first, without removing them. Snapshot uses it to
+	// read the queue, so it must not change the queue.
+	Events() []QueuedEvent[TTrigger]
+}
+
+// sliceTriggerQueue is the default in-memory TriggerQueue.
+type sliceTriggerQueue[TTrigger comparable] struct {
+	events []QueuedEvent[TTrigger]
+}
+
+// Push appends an event to the back of the queue.
+func (q *sliceTriggerQueue[TTrigger]) Push(event QueuedEvent[TTrigger]) {
+	q.events = append(q.events, event)
+}
+
+// Pop removes and returns the event at the front of the queue.
+func (q *sliceTriggerQueue[TTrigger]) Pop() (QueuedEvent[TTrigger], bool) {
+	if len(q.events) == 0 {
+		return QueuedEvent[TTrigger]{}, false
+	}
+	event := q.events[0]
+	q.events = q.events[1:]
+	return event, true
+}
+
+// Len returns the number of queued events.
+func (q *sliceTriggerQueue[TTrigger]) Len() int {
+	return len(q.events)
+}
+
+// Events returns a copy of the queued events, front first.
+func (q *sliceTriggerQueue[TTrigger]) Events() []QueuedEvent[TTrigger] {
+	return slices.Clone(q.events)
+}